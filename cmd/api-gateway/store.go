@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"mine-detection-system/internal/infrastructure/memory"
+	"mine-detection-system/internal/infrastructure/repositories"
+	"mine-detection-system/internal/ports"
+)
+
+// repositorySet об'єднує всі репозиторії, які використовує шлюз
+type repositorySet struct {
+	devices         ports.DeviceRepository
+	missions        ports.MissionRepository
+	scans           ports.ScanRepository
+	sensorData      ports.SensorDataRepository
+	detectedObjects ports.DetectedObjectRepository
+	reports         ports.VerificationReportRepository
+	profiles        ports.DetectionProfileRepository
+	sightings       ports.SightingRepository
+}
+
+// newPostgresRepositories створює репозиторії поверх PostgreSQL
+func newPostgresRepositories(db *sql.DB) repositorySet {
+	return repositorySet{
+		devices:         repositories.NewPostgresDeviceRepository(db),
+		missions:        repositories.NewPostgresMissionRepository(db),
+		scans:           repositories.NewPostgresScanRepository(db),
+		sensorData:      repositories.NewPostgresSensorDataRepository(db),
+		detectedObjects: repositories.NewPostgresDetectedObjectRepository(db),
+		reports:         repositories.NewPostgresVerificationReportRepository(db),
+		profiles:        repositories.NewPostgresDetectionProfileRepository(db),
+		sightings:       repositories.NewPostgresSightingRepository(db),
+	}
+}
+
+// newMemoryRepositories створює репозиторії в пам'яті (для CI та польових ноутбуків без БД)
+func newMemoryRepositories() repositorySet {
+	return repositorySet{
+		devices:         memory.NewDeviceRepository(),
+		missions:        memory.NewMissionRepository(),
+		scans:           memory.NewScanRepository(),
+		sensorData:      memory.NewSensorDataRepository(),
+		detectedObjects: memory.NewDetectedObjectRepository(),
+		reports:         memory.NewVerificationReportRepository(),
+		profiles:        memory.NewDetectionProfileRepository(),
+		sightings:       memory.NewSightingRepository(),
+	}
+}
+
+// openStore відкриває сховище заданого типу; повертає функцію для його закриття.
+// Для PostgreSQL за потреби одразу застосовує незастосовані міграції.
+func openStore(ctx context.Context, store, dbURL string, migrate bool) (repositorySet, func(), error) {
+	switch store {
+	case "postgres":
+		db, err := sql.Open("postgres", dbURL)
+		if err != nil {
+			return repositorySet{}, nil, err
+		}
+
+		if migrate {
+			if err := applyMigrations(ctx, db); err != nil {
+				db.Close()
+				return repositorySet{}, nil, fmt.Errorf("migrations: %w", err)
+			}
+		}
+
+		return newPostgresRepositories(db), func() { db.Close() }, nil
+
+	case "memory":
+		return newMemoryRepositories(), func() {}, nil
+
+	default:
+		return repositorySet{}, nil, fmt.Errorf("unknown store %q (expected postgres or memory)", store)
+	}
+}
@@ -0,0 +1,379 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"mine-detection-system/pkg/fusion"
+	"mine-detection-system/pkg/protocol"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCommandTimeout час очікування підтвердження команди за замовчуванням
+	DefaultCommandTimeout = 10 * time.Second
+	// MaxCommandTimeout найбільший допустимий час очікування підтвердження
+	MaxCommandTimeout = 5 * time.Minute
+	// commandRetention час зберігання завершених команд для запитів статусу
+	commandRetention = time.Hour
+)
+
+var (
+	// ErrInvalidCommand повертається, якщо команда або її параметри некоректні
+	ErrInvalidCommand = errors.New("invalid device command")
+	// ErrCommandNotFound повертається, якщо команда не знайдена
+	ErrCommandNotFound = errors.New("device command not found")
+)
+
+// CommandService надсилає команди підключеним пристроям і відстежує їх підтвердження.
+// Команди зберігаються в пам'яті: після перезапуску сервера пристрої мають
+// перепідключитися, тож незавершені команди втрачають сенс.
+type CommandService struct {
+	deviceRepo ports.DeviceRepository
+	eventBus   ports.EventBus
+
+	mu        sync.Mutex
+	transport ports.DeviceTransport
+	commands  map[uuid.UUID]*trackedCommand
+}
+
+// trackedCommand команда разом із засобами очікування її підтвердження
+type trackedCommand struct {
+	command domain.DeviceCommand
+	// settled закривається, коли пристрій відповів на команду або вона завершилась
+	settled chan struct{}
+	timer   *time.Timer
+}
+
+// NewCommandService створює новий екземпляр CommandService
+func NewCommandService(deviceRepo ports.DeviceRepository, eventBus ports.EventBus) *CommandService {
+	return &CommandService{
+		deviceRepo: deviceRepo,
+		eventBus:   eventBus,
+		commands:   make(map[uuid.UUID]*trackedCommand),
+	}
+}
+
+// SetTransport задає канал доставки команд.
+// Обробник WebSocket пристроїв сам залежить від сервісів, тому транспорт
+// підключається після його створення.
+func (s *CommandService) SetTransport(transport ports.DeviceTransport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transport = transport
+}
+
+// IsOnline повідомляє, чи має пристрій активне з'єднання для отримання команд
+func (s *CommandService) IsOnline(deviceID uuid.UUID) bool {
+	s.mu.Lock()
+	transport := s.transport
+	s.mu.Unlock()
+
+	return transport != nil && transport.IsConnected(deviceID)
+}
+
+// SendCommand створює команду і надсилає її пристрою.
+// Якщо пристрій не підключений, команда зберігається зі статусом undelivered.
+// timeout обмежує очікування підтвердження; нуль означає DefaultCommandTimeout.
+func (s *CommandService) SendCommand(
+	ctx context.Context,
+	deviceID uuid.UUID,
+	commandType domain.CommandType,
+	params map[string]interface{},
+	timeout time.Duration,
+) (*domain.DeviceCommand, error) {
+	if timeout == 0 {
+		timeout = DefaultCommandTimeout
+	}
+	if timeout < 0 || timeout > MaxCommandTimeout {
+		return nil, fmt.Errorf("%w: timeout must be between 0 and %s", ErrInvalidCommand, MaxCommandTimeout)
+	}
+	if err := validateCommandParams(commandType, params); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.deviceRepo.FindByID(ctx, deviceID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tracked := &trackedCommand{
+		command: domain.DeviceCommand{
+			ID:        uuid.New(),
+			DeviceID:  deviceID,
+			Type:      commandType,
+			Params:    params,
+			Status:    domain.CommandStatusPending,
+			CreatedAt: now,
+			ExpiresAt: now.Add(timeout),
+		},
+		settled: make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	s.pruneLocked(now)
+	s.commands[tracked.command.ID] = tracked
+	transport := s.transport
+	s.mu.Unlock()
+
+	// Відправка виконується поза блокуванням, щоб повільне з'єднання не затримувало інші команди
+	var sendErr error
+	if transport == nil {
+		sendErr = errors.New("device transport is not configured")
+	} else {
+		sendErr = transport.Send(deviceID, protocol.Command{
+			CommandID: tracked.command.ID,
+			Command:   string(commandType),
+			Params:    params,
+			ExpiresAt: tracked.command.ExpiresAt.Unix(),
+		})
+	}
+
+	s.mu.Lock()
+	if sendErr != nil {
+		tracked.command.Status = domain.CommandStatusUndelivered
+		tracked.command.Error = sendErr.Error()
+		close(tracked.settled)
+	} else {
+		sentAt := time.Now()
+		tracked.command.Status = domain.CommandStatusSent
+		tracked.command.SentAt = &sentAt
+		commandID := tracked.command.ID
+		tracked.timer = time.AfterFunc(timeout, func() {
+			s.expire(commandID)
+		})
+	}
+	command := tracked.command
+	s.mu.Unlock()
+
+	s.publish(command)
+
+	return &command, nil
+}
+
+// HandleAck обробляє відповідь пристрою на команду.
+// status може бути accepted (команду прийнято), completed (виконано) або rejected (відхилено).
+func (s *CommandService) HandleAck(deviceID, commandID uuid.UUID, status, message string) error {
+	s.mu.Lock()
+
+	tracked, ok := s.commands[commandID]
+	if !ok || tracked.command.DeviceID != deviceID {
+		s.mu.Unlock()
+		return ErrCommandNotFound
+	}
+	if commandFinished(tracked.command.Status) {
+		s.mu.Unlock()
+		return fmt.Errorf("command %s is already %s", commandID, tracked.command.Status)
+	}
+
+	now := time.Now()
+	switch status {
+	case protocol.AckAccepted:
+		if tracked.command.Status == domain.CommandStatusAcknowledged {
+			s.mu.Unlock()
+			return nil
+		}
+		tracked.command.Status = domain.CommandStatusAcknowledged
+		tracked.command.AckedAt = &now
+	case protocol.AckCompleted:
+		if tracked.command.AckedAt == nil {
+			tracked.command.AckedAt = &now
+		}
+		tracked.command.Status = domain.CommandStatusCompleted
+		tracked.command.CompletedAt = &now
+	case protocol.AckRejected:
+		if tracked.command.AckedAt == nil {
+			tracked.command.AckedAt = &now
+		}
+		tracked.command.Status = domain.CommandStatusRejected
+		tracked.command.Error = message
+	default:
+		s.mu.Unlock()
+		return fmt.Errorf("unknown acknowledgement status %q", status)
+	}
+
+	// Підтвердження отримано вчасно, тож таймер очікування більше не потрібен
+	if tracked.timer != nil {
+		tracked.timer.Stop()
+	}
+	s.settleLocked(tracked)
+	command := tracked.command
+	s.mu.Unlock()
+
+	s.publish(command)
+
+	return nil
+}
+
+// WaitForAck очікує відповіді пристрою на команду, завершення команди або скасування ctx
+func (s *CommandService) WaitForAck(ctx context.Context, commandID uuid.UUID) (*domain.DeviceCommand, error) {
+	s.mu.Lock()
+	tracked, ok := s.commands[commandID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrCommandNotFound
+	}
+
+	select {
+	case <-tracked.settled:
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	command := tracked.command
+	s.mu.Unlock()
+
+	return &command, nil
+}
+
+// GetCommand повертає команду пристрою за ID
+func (s *CommandService) GetCommand(deviceID, commandID uuid.UUID) (*domain.DeviceCommand, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tracked, ok := s.commands[commandID]
+	if !ok || tracked.command.DeviceID != deviceID {
+		return nil, ErrCommandNotFound
+	}
+
+	command := tracked.command
+	return &command, nil
+}
+
+// ListCommands повертає команди пристрою, починаючи з найновіших
+func (s *CommandService) ListCommands(deviceID uuid.UUID) []*domain.DeviceCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	commands := make([]*domain.DeviceCommand, 0)
+	for _, tracked := range s.commands {
+		if tracked.command.DeviceID == deviceID {
+			command := tracked.command
+			commands = append(commands, &command)
+		}
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].CreatedAt.After(commands[j].CreatedAt)
+	})
+
+	return commands
+}
+
+// expire позначає команду простроченою, якщо пристрій так і не відповів
+func (s *CommandService) expire(commandID uuid.UUID) {
+	s.mu.Lock()
+	tracked, ok := s.commands[commandID]
+	if !ok || tracked.command.Status != domain.CommandStatusSent {
+		s.mu.Unlock()
+		return
+	}
+
+	tracked.command.Status = domain.CommandStatusTimedOut
+	tracked.command.Error = "no acknowledgement from device"
+	s.settleLocked(tracked)
+	command := tracked.command
+	s.mu.Unlock()
+
+	s.publish(command)
+}
+
+// settleLocked сповіщає тих, хто очікує відповіді на команду; викликається під s.mu
+func (s *CommandService) settleLocked(tracked *trackedCommand) {
+	select {
+	case <-tracked.settled:
+	default:
+		close(tracked.settled)
+	}
+}
+
+// pruneLocked видаляє давно завершені команди; викликається під s.mu
+func (s *CommandService) pruneLocked(now time.Time) {
+	for id, tracked := range s.commands {
+		if commandFinished(tracked.command.Status) && now.Sub(tracked.command.CreatedAt) > commandRetention {
+			delete(s.commands, id)
+		}
+	}
+}
+
+// publish повідомляє операторів про зміну стану команди
+func (s *CommandService) publish(command domain.DeviceCommand) {
+	s.eventBus.Publish(ports.Event{
+		Type:     ports.EventDeviceCommand,
+		DeviceID: command.DeviceID,
+		Payload:  command,
+	})
+}
+
+// commandFinished повідомляє, чи команда досягла кінцевого стану
+func commandFinished(status domain.CommandStatus) bool {
+	switch status {
+	case domain.CommandStatusUndelivered, domain.CommandStatusCompleted,
+		domain.CommandStatusRejected, domain.CommandStatusTimedOut:
+		return true
+	}
+	return false
+}
+
+// validateCommandParams перевіряє параметри команди відповідно до її типу
+func validateCommandParams(commandType domain.CommandType, params map[string]interface{}) error {
+	switch commandType {
+	case domain.CommandStartScan:
+		return requireUUIDParam(params, "mission_id")
+
+	case domain.CommandStopScan:
+		return requireUUIDParam(params, "scan_id")
+
+	case domain.CommandApplyConfig:
+		if params["config"] == nil {
+			return fmt.Errorf("%w: config is required", ErrInvalidCommand)
+		}
+
+	case domain.CommandRecalibrate:
+		// Без параметра sensor пристрій калібрує всі сенсори
+		if _, ok := params["sensor"]; ok {
+			return requireSensorParam(params)
+		}
+
+	case domain.CommandSetSampleRate:
+		if err := requireSensorParam(params); err != nil {
+			return err
+		}
+		rate, ok := params["rate_hz"].(float64)
+		if !ok || rate <= 0 {
+			return fmt.Errorf("%w: rate_hz must be a positive number", ErrInvalidCommand)
+		}
+
+	case domain.CommandReturnToBase:
+
+	default:
+		return fmt.Errorf("%w: unknown command type %q", ErrInvalidCommand, commandType)
+	}
+
+	return nil
+}
+
+// requireUUIDParam перевіряє, що параметр містить коректний UUID
+func requireUUIDParam(params map[string]interface{}, key string) error {
+	value, _ := params[key].(string)
+	if _, err := uuid.Parse(value); err != nil {
+		return fmt.Errorf("%w: %s must be a valid UUID", ErrInvalidCommand, key)
+	}
+	return nil
+}
+
+// requireSensorParam перевіряє, що параметр sensor називає підтримуваний тип сенсора
+func requireSensorParam(params map[string]interface{}) error {
+	sensor, _ := params["sensor"].(string)
+	if !fusion.IsSensorType(sensor) {
+		return fmt.Errorf("%w: sensor must be one of %s", ErrInvalidCommand, strings.Join(fusion.SensorTypes(), ", "))
+	}
+	return nil
+}
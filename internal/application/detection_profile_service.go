@@ -0,0 +1,165 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"mine-detection-system/pkg/fusion"
+	"time"
+)
+
+// DefaultProfileName назва профілю, який діє для місії без власного профілю
+const DefaultProfileName = "default"
+
+// ErrInvalidProfile повертається, якщо профіль детекції не пройшов валідацію
+var ErrInvalidProfile = errors.New("invalid detection profile")
+
+// DetectionProfileService керує профілями детекції місій
+type DetectionProfileService struct {
+	profileRepo ports.DetectionProfileRepository
+	missionRepo ports.MissionRepository
+	classifier  *fusion.Classifier
+}
+
+// NewDetectionProfileService створює новий екземпляр DetectionProfileService.
+// classifier використовується для профілів без власної моделі; nil означає вбудовану модель.
+func NewDetectionProfileService(
+	profileRepo ports.DetectionProfileRepository,
+	missionRepo ports.MissionRepository,
+	classifier *fusion.Classifier,
+) *DetectionProfileService {
+	if classifier == nil {
+		classifier = fusion.DefaultClassifier()
+	}
+
+	return &DetectionProfileService{
+		profileRepo: profileRepo,
+		missionRepo: missionRepo,
+		classifier:  classifier,
+	}
+}
+
+// Presets повертає доступні набори налаштувань детектора
+func (s *DetectionProfileService) Presets() map[string]fusion.DetectorConfig {
+	return fusion.DetectorPresets()
+}
+
+// GetMissionProfile повертає профіль місії або профіль за замовчуванням, якщо власного немає
+func (s *DetectionProfileService) GetMissionProfile(ctx context.Context, missionID uuid.UUID) (*domain.DetectionProfile, error) {
+	if _, err := s.missionRepo.FindByID(ctx, missionID); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.profileRepo.FindByMissionID(ctx, missionID)
+	if errors.Is(err, ports.ErrDetectionProfileNotFound) {
+		// Відсутність профілю не є помилкою: місія працює з налаштуваннями за замовчуванням
+		return &domain.DetectionProfile{
+			MissionID: missionID,
+			Name:      DefaultProfileName,
+			Config:    fusion.DefaultDetectorConfig(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// SetMissionProfile призначає місії профіль детекції.
+// Налаштування беруться з набору з назвою name (або з налаштувань за замовчуванням,
+// якщо такого набору немає), а overrides перекриває окремі поля конфігурації.
+func (s *DetectionProfileService) SetMissionProfile(ctx context.Context, missionID uuid.UUID, name string, overrides json.RawMessage) (*domain.DetectionProfile, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidProfile)
+	}
+
+	mission, err := s.missionRepo.FindByID(ctx, missionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isMissionClosed(mission.Status) {
+		return nil, fmt.Errorf("%w: status is %s", ErrMissionClosed, mission.Status)
+	}
+
+	config, ok := fusion.DetectorPresets()[name]
+	if !ok {
+		if len(overrides) == 0 {
+			return nil, fmt.Errorf("%w: unknown preset %q and no config given", ErrInvalidProfile, name)
+		}
+		config = fusion.DefaultDetectorConfig()
+	}
+
+	if len(overrides) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(overrides))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+		}
+	}
+
+	// Побудова детектора перевіряє конфігурацію разом з моделлю класифікатора
+	if _, err := fusion.NewDetectorWithConfig(config, s.classifier); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProfile, err)
+	}
+
+	now := time.Now()
+	profile := &domain.DetectionProfile{
+		MissionID: missionID,
+		Name:      name,
+		Config:    config,
+		UpdatedAt: &now,
+	}
+
+	if err := s.profileRepo.Save(ctx, profile); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// ResetMissionProfile видаляє власний профіль місії, повертаючи налаштування за замовчуванням
+func (s *DetectionProfileService) ResetMissionProfile(ctx context.Context, missionID uuid.UUID) error {
+	return s.profileRepo.Delete(ctx, missionID)
+}
+
+// DetectorForMission створює детектор з профілем місії
+func (s *DetectionProfileService) DetectorForMission(ctx context.Context, missionID uuid.UUID) (*fusion.Detector, error) {
+	profile, err := s.GetMissionProfile(ctx, missionID)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := profileConfig(profile)
+	if err != nil {
+		return nil, fmt.Errorf("detection profile %s: %w", profile.Name, err)
+	}
+
+	return fusion.NewDetectorWithConfig(config, s.classifier)
+}
+
+// profileConfig приводить збережену конфігурацію профілю до fusion.DetectorConfig.
+// Конфігурація зі сховища представлена JSON-об'єктом і конвертується через повторну серіалізацію.
+func profileConfig(profile *domain.DetectionProfile) (fusion.DetectorConfig, error) {
+	if config, ok := profile.Config.(fusion.DetectorConfig); ok {
+		return config, nil
+	}
+
+	raw, err := json.Marshal(profile.Config)
+	if err != nil {
+		return fusion.DetectorConfig{}, err
+	}
+
+	config := fusion.DefaultDetectorConfig()
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return fusion.DetectorConfig{}, err
+	}
+
+	return config, nil
+}
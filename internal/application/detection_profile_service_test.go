@@ -0,0 +1,71 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/infrastructure/memory"
+	"mine-detection-system/internal/ports"
+	"mine-detection-system/pkg/fusion"
+	"testing"
+	"time"
+)
+
+// failingProfileRepository сховище профілів, недоступне під час читання
+type failingProfileRepository struct {
+	*memory.DetectionProfileRepository
+	err error
+}
+
+func (r *failingProfileRepository) FindByMissionID(ctx context.Context, missionID uuid.UUID) (*domain.DetectionProfile, error) {
+	return nil, r.err
+}
+
+func newProfileTestService(t *testing.T, profiles ports.DetectionProfileRepository) (*DetectionProfileService, uuid.UUID) {
+	t.Helper()
+
+	missions := memory.NewMissionRepository()
+	mission := &domain.Mission{ID: uuid.New(), Name: "test", Status: domain.MissionStatusActive, StartDate: time.Now()}
+	if err := missions.Save(context.Background(), mission); err != nil {
+		t.Fatalf("save mission: %v", err)
+	}
+
+	return NewDetectionProfileService(profiles, missions, fusion.DefaultClassifier()), mission.ID
+}
+
+func TestGetMissionProfileFallsBackToDefault(t *testing.T) {
+	service, missionID := newProfileTestService(t, memory.NewDetectionProfileRepository())
+
+	profile, err := service.GetMissionProfile(context.Background(), missionID)
+	if err != nil {
+		t.Fatalf("GetMissionProfile: %v", err)
+	}
+	if profile.Name != DefaultProfileName || profile.MissionID != missionID {
+		t.Errorf("profile = %s for %s, want %s for %s", profile.Name, profile.MissionID, DefaultProfileName, missionID)
+	}
+}
+
+func TestGetMissionProfileReturnsRepositoryErrors(t *testing.T) {
+	outage := errors.New("connection refused")
+	service, missionID := newProfileTestService(t, &failingProfileRepository{
+		DetectionProfileRepository: memory.NewDetectionProfileRepository(),
+		err:                        outage,
+	})
+
+	// Збій сховища не має непомітно підміняти профіль місії налаштуваннями за замовчуванням
+	if _, err := service.GetMissionProfile(context.Background(), missionID); !errors.Is(err, outage) {
+		t.Errorf("GetMissionProfile: got error %v, want %v", err, outage)
+	}
+	if _, err := service.DetectorForMission(context.Background(), missionID); !errors.Is(err, outage) {
+		t.Errorf("DetectorForMission: got error %v, want %v", err, outage)
+	}
+}
+
+func TestResetMissionProfileWithoutProfile(t *testing.T) {
+	service, missionID := newProfileTestService(t, memory.NewDetectionProfileRepository())
+
+	if err := service.ResetMissionProfile(context.Background(), missionID); !errors.Is(err, ports.ErrDetectionProfileNotFound) {
+		t.Errorf("got error %v, want %v", err, ports.ErrDetectionProfileNotFound)
+	}
+}
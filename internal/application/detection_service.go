@@ -0,0 +1,68 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+)
+
+// MaxSearchRadius обмежує радіус пошуку виявлених об'єктів (у метрах)
+const MaxSearchRadius = 5000.0
+
+// DetectionService відповідає за доступ до виявлених об'єктів
+type DetectionService struct {
+	detectedObjectRepo ports.DetectedObjectRepository
+	scanRepo           ports.ScanRepository
+	sightingRepo       ports.SightingRepository
+}
+
+// NewDetectionService створює новий екземпляр DetectionService
+func NewDetectionService(
+	detectedObjectRepo ports.DetectedObjectRepository,
+	scanRepo ports.ScanRepository,
+	sightingRepo ports.SightingRepository,
+) *DetectionService {
+	return &DetectionService{
+		detectedObjectRepo: detectedObjectRepo,
+		scanRepo:           scanRepo,
+		sightingRepo:       sightingRepo,
+	}
+}
+
+// GetDetectionByID отримує виявлений об'єкт за ID
+func (s *DetectionService) GetDetectionByID(ctx context.Context, id uuid.UUID) (*domain.DetectedObject, error) {
+	return s.detectedObjectRepo.FindByID(ctx, id)
+}
+
+// ListScanDetections отримує всі об'єкти, виявлені під час сканування
+func (s *DetectionService) ListScanDetections(ctx context.Context, scanID uuid.UUID) ([]*domain.DetectedObject, error) {
+	if _, err := s.scanRepo.FindByID(ctx, scanID); err != nil {
+		return nil, err
+	}
+
+	return s.detectedObjectRepo.FindByScanID(ctx, scanID)
+}
+
+// ListSightings повертає історію спостережень виявленого об'єкта
+func (s *DetectionService) ListSightings(ctx context.Context, objectID uuid.UUID) ([]*domain.Sighting, error) {
+	if _, err := s.detectedObjectRepo.FindByID(ctx, objectID); err != nil {
+		return nil, err
+	}
+
+	return s.sightingRepo.FindByDetectedObjectID(ctx, objectID)
+}
+
+// FindNearby шукає виявлені об'єкти в радіусі radius метрів від точки
+func (s *DetectionService) FindNearby(ctx context.Context, lat, lon, radius float64) ([]*domain.DetectedObject, error) {
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return nil, errors.New("coordinates out of range")
+	}
+
+	if radius <= 0 || radius > MaxSearchRadius {
+		return nil, errors.New("radius must be positive and not exceed 5000 m")
+	}
+
+	return s.detectedObjectRepo.FindByCoordinates(ctx, lat, lon, radius)
+}
@@ -11,13 +11,17 @@ import (
 
 // DeviceService відповідає за бізнес-логіку роботи з пристроями
 type DeviceService struct {
-	deviceRepo ports.DeviceRepository
+	deviceRepo     ports.DeviceRepository
+	eventBus       ports.EventBus
+	commandService *CommandService
 }
 
 // NewDeviceService створює новий екземпляр DeviceService
-func NewDeviceService(deviceRepo ports.DeviceRepository) *DeviceService {
+func NewDeviceService(deviceRepo ports.DeviceRepository, eventBus ports.EventBus, commandService *CommandService) *DeviceService {
 	return &DeviceService{
-		deviceRepo: deviceRepo,
+		deviceRepo:     deviceRepo,
+		eventBus:       eventBus,
+		commandService: commandService,
 	}
 }
 
@@ -60,10 +64,24 @@ func (s *DeviceService) UpdateDeviceStatus(ctx context.Context, deviceID uuid.UU
 		return err
 	}
 
+	previous := device.Status
 	device.Status = status
 	device.LastConnectionAt = time.Now()
 
-	return s.deviceRepo.Update(ctx, device)
+	if err := s.deviceRepo.Update(ctx, device); err != nil {
+		return err
+	}
+
+	// Heartbeat лише підтверджує поточний статус, тож подія надсилається тільки при зміні
+	if previous != status {
+		s.eventBus.Publish(ports.Event{
+			Type:     ports.EventDeviceStatus,
+			DeviceID: device.ID,
+			Payload:  *device,
+		})
+	}
+
+	return nil
 }
 
 // GetDeviceByID отримує пристрій за ID
@@ -76,15 +94,44 @@ func (s *DeviceService) ListDevices(ctx context.Context, filters map[string]inte
 	return s.deviceRepo.FindAll(ctx, filters)
 }
 
-// UpdateDeviceConfiguration оновлює конфігурацію пристрою
-func (s *DeviceService) UpdateDeviceConfiguration(ctx context.Context, deviceID uuid.UUID, config interface{}) error {
+// UpdateDeviceConfiguration оновлює конфігурацію пристрою.
+// Підключений пристрій одразу отримує команду apply_config; її стан повертається,
+// а для пристрою поза мережею повертається nil і конфігурація застосується пізніше.
+func (s *DeviceService) UpdateDeviceConfiguration(ctx context.Context, deviceID uuid.UUID, config interface{}) (*domain.DeviceCommand, error) {
 	device, err := s.deviceRepo.FindByID(ctx, deviceID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	device.Configuration = config
 	device.LastConnectionAt = time.Now()
 
-	return s.deviceRepo.Update(ctx, device)
+	if err := s.deviceRepo.Update(ctx, device); err != nil {
+		return nil, err
+	}
+
+	if config == nil || !s.commandService.IsOnline(deviceID) {
+		return nil, nil
+	}
+
+	return s.commandService.SendCommand(ctx, deviceID, domain.CommandApplyConfig, map[string]interface{}{
+		"config": config,
+	}, 0)
+}
+
+// SyncConfiguration надсилає пристрою збережену конфігурацію після підключення,
+// щоб зміни, зроблені поки пристрій був поза мережею, не загубилися
+func (s *DeviceService) SyncConfiguration(ctx context.Context, deviceID uuid.UUID) (*domain.DeviceCommand, error) {
+	device, err := s.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.Configuration == nil {
+		return nil, nil
+	}
+
+	return s.commandService.SendCommand(ctx, deviceID, domain.CommandApplyConfig, map[string]interface{}{
+		"config": device.Configuration,
+	}, 0)
 }
@@ -0,0 +1,188 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"mine-detection-system/pkg/geo"
+	"time"
+)
+
+// ErrInvalidMission повертається, якщо дані місії не пройшли валідацію
+var ErrInvalidMission = errors.New("invalid mission")
+
+// ErrInvalidMissionTransition повертається при спробі недопустимої зміни статусу місії
+var ErrInvalidMissionTransition = errors.New("invalid mission status transition")
+
+// ErrMissionClosed повертається при спробі редагувати завершену або перервану місію
+var ErrMissionClosed = errors.New("mission is closed")
+
+// missionTransitions описує допустимі переходи життєвого циклу місії.
+// Завершені та перервані місії є кінцевими станами.
+var missionTransitions = map[domain.MissionStatus][]domain.MissionStatus{
+	domain.MissionStatusPlanned: {domain.MissionStatusActive, domain.MissionStatusAborted},
+	domain.MissionStatusActive:  {domain.MissionStatusCompleted, domain.MissionStatusAborted},
+}
+
+// MissionUpdate містить поля місії, які можна змінити; nil означає "без змін"
+type MissionUpdate struct {
+	Name        *string
+	Description *string
+	Boundaries  domain.GeoJSON
+	StartDate   *time.Time
+	Priority    *int
+}
+
+// MissionService відповідає за бізнес-логіку роботи з місіями
+type MissionService struct {
+	missionRepo ports.MissionRepository
+}
+
+// NewMissionService створює новий екземпляр MissionService
+func NewMissionService(missionRepo ports.MissionRepository) *MissionService {
+	return &MissionService{
+		missionRepo: missionRepo,
+	}
+}
+
+// CreateMission створює нову місію у статусі planned
+func (s *MissionService) CreateMission(
+	ctx context.Context,
+	name, description string,
+	boundaries domain.GeoJSON,
+	startDate time.Time,
+	priority int,
+) (*domain.Mission, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidMission)
+	}
+
+	if err := geo.ValidatePolygon(boundaries); err != nil {
+		return nil, fmt.Errorf("%w: boundaries: %v", ErrInvalidMission, err)
+	}
+
+	if startDate.IsZero() {
+		startDate = time.Now()
+	}
+
+	mission := &domain.Mission{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: description,
+		Boundaries:  boundaries,
+		Status:      domain.MissionStatusPlanned,
+		StartDate:   startDate,
+		Priority:    priority,
+	}
+
+	if err := s.missionRepo.Save(ctx, mission); err != nil {
+		return nil, err
+	}
+
+	return mission, nil
+}
+
+// UpdateMission змінює атрибути місії, яка ще не завершена
+func (s *MissionService) UpdateMission(ctx context.Context, missionID uuid.UUID, update MissionUpdate) (*domain.Mission, error) {
+	mission, err := s.missionRepo.FindByID(ctx, missionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isMissionClosed(mission.Status) {
+		return nil, fmt.Errorf("%w: status is %s", ErrMissionClosed, mission.Status)
+	}
+
+	if update.Name != nil {
+		if *update.Name == "" {
+			return nil, fmt.Errorf("%w: name is required", ErrInvalidMission)
+		}
+		mission.Name = *update.Name
+	}
+
+	if update.Description != nil {
+		mission.Description = *update.Description
+	}
+
+	if update.Boundaries != nil {
+		if err := geo.ValidatePolygon(update.Boundaries); err != nil {
+			return nil, fmt.Errorf("%w: boundaries: %v", ErrInvalidMission, err)
+		}
+		mission.Boundaries = update.Boundaries
+	}
+
+	if update.StartDate != nil {
+		// Фактичний час початку активної місії вже зафіксовано переходом у active
+		if mission.Status != domain.MissionStatusPlanned {
+			return nil, fmt.Errorf("%w: start date can only be changed for planned missions", ErrInvalidMission)
+		}
+		mission.StartDate = *update.StartDate
+	}
+
+	if update.Priority != nil {
+		mission.Priority = *update.Priority
+	}
+
+	if err := s.missionRepo.Update(ctx, mission); err != nil {
+		return nil, err
+	}
+
+	return mission, nil
+}
+
+// ChangeMissionStatus переводить місію в новий статус, якщо такий перехід допустимий,
+// і фіксує час переходу: StartDate при активації, EndDate при завершенні чи перериванні
+func (s *MissionService) ChangeMissionStatus(ctx context.Context, missionID uuid.UUID, status domain.MissionStatus) (*domain.Mission, error) {
+	mission, err := s.missionRepo.FindByID(ctx, missionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canTransitionMission(mission.Status, status) {
+		return nil, fmt.Errorf("%w from %s to %s", ErrInvalidMissionTransition, mission.Status, status)
+	}
+
+	now := time.Now()
+	switch status {
+	case domain.MissionStatusActive:
+		mission.StartDate = now
+	case domain.MissionStatusCompleted, domain.MissionStatusAborted:
+		mission.EndDate = &now
+	}
+	mission.Status = status
+
+	if err := s.missionRepo.Update(ctx, mission); err != nil {
+		return nil, err
+	}
+
+	return mission, nil
+}
+
+// GetMissionByID отримує місію за ID
+func (s *MissionService) GetMissionByID(ctx context.Context, missionID uuid.UUID) (*domain.Mission, error) {
+	return s.missionRepo.FindByID(ctx, missionID)
+}
+
+// ListMissions отримує список місій з можливістю фільтрації
+func (s *MissionService) ListMissions(ctx context.Context, filters map[string]interface{}) ([]*domain.Mission, error) {
+	return s.missionRepo.FindAll(ctx, filters)
+}
+
+// canTransitionMission перевіряє, чи дозволений перехід між статусами місії
+func canTransitionMission(from, to domain.MissionStatus) bool {
+	for _, allowed := range missionTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isMissionClosed повертає true для кінцевих статусів місії
+func isMissionClosed(status domain.MissionStatus) bool {
+	return status == domain.MissionStatusCompleted || status == domain.MissionStatusAborted
+}
@@ -0,0 +1,231 @@
+package application
+
+import (
+	"mine-detection-system/internal/domain"
+	"sort"
+	"sync"
+	"time"
+)
+
+// sequenceWindow кількість останніх порядкових номерів, для яких запам'ятовуються
+// пропущені пакети. Пропуски, старші за вікно, враховуються лише в статистиці.
+const sequenceWindow = 4096
+
+// sequenceIdleTimeout час без пакетів, після якого трекер сканування вивантажується
+// з пам'яті. Стан трекера відновлюється з квитанцій пакетів, коли пакети надійдуть знову.
+const sequenceIdleTimeout = 15 * time.Minute
+
+// PacketResumePoint описує, з якого місця пристрій має продовжити передачу
+// пакетів сканування після перепідключення
+type PacketResumePoint struct {
+	// LastSequence найбільший отриманий номер; nil, якщо пакети ще не надходили
+	LastSequence *uint32
+	// Missing пропущені номери в межах вікна, які ще будуть прийняті
+	Missing []uint32
+}
+
+// sequenceTracker відстежує порядкові номери збережених пакетів одного сканування.
+// Чи є пакет дублікатом, визначають квитанції пакетів у сховищі, а трекер
+// веде поточну епоху нумерації, точку відновлення та статистику доставки.
+type sequenceTracker struct {
+	mu       sync.Mutex
+	epoch    int
+	started  bool
+	first    uint32
+	highest  uint32
+	lastSeen time.Time
+	// missing номери пропущених пакетів у межах вікна, які ще можуть надійти
+	missing map[uint32]bool
+	stats   domain.PacketStats
+}
+
+// newSequenceTracker створює трекер без жодного отриманого пакета
+func newSequenceTracker() *sequenceTracker {
+	return &sequenceTracker{missing: make(map[uint32]bool), lastSeen: time.Now()}
+}
+
+// restoreSequenceTracker відновлює трекер з квитанцій пакетів, упорядкованих за
+// епохою і номером. Лічильники, які не можна вивести з квитанцій (дублікати,
+// пакети не за порядком), беруться зі збереженої статистики сканування.
+func restoreSequenceTracker(packets []*domain.SensorPacket, saved *domain.PacketStats) *sequenceTracker {
+	t := newSequenceTracker()
+	for _, packet := range packets {
+		for t.epoch < packet.Epoch {
+			t.resetLocked()
+		}
+		t.observeLocked(packet.Sequence)
+	}
+
+	if saved != nil {
+		t.stats.Duplicates = saved.Duplicates
+		t.stats.Reordered = saved.Reordered
+		if saved.Gaps > t.stats.Gaps {
+			t.stats.Gaps = saved.Gaps
+		}
+	}
+
+	return t
+}
+
+// currentEpoch повертає епоху нумерації, в якій зберігаються нові пакети
+func (t *sequenceTracker) currentEpoch() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.epoch
+}
+
+// observe враховує збережений пакет епохи epoch. Запізнілі пакети заповнюють
+// раніше виявлений розрив.
+func (t *sequenceTracker) observe(epoch int, sequence uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastSeen = time.Now()
+	if epoch != t.epoch {
+		// Пакет попередньої епохи, що надійшов після скидання нумерації
+		t.stats.Received++
+		return
+	}
+	t.observeLocked(sequence)
+}
+
+// observeLocked враховує номер збереженого пакета поточної епохи; викликається під t.mu
+func (t *sequenceTracker) observeLocked(sequence uint32) {
+	switch {
+	case !t.started:
+		t.started = true
+		t.first = sequence
+		t.highest = sequence
+
+	case sequence > t.highest:
+		t.addGap(t.highest+1, sequence)
+		t.highest = sequence
+		t.forgetBefore(sequence)
+
+	case t.missing[sequence]:
+		delete(t.missing, sequence)
+		t.stats.Missing--
+		t.stats.Reordered++
+
+	case sequence < t.first:
+		// Надійшов пакет, відправлений раніше за перший отриманий
+		t.addGap(sequence+1, t.first)
+		t.first = sequence
+		t.stats.Reordered++
+
+	default:
+		// Пропущений пакет, який уже випав з вікна, але все ж надійшов
+		if t.stats.Missing > 0 {
+			t.stats.Missing--
+		}
+		t.stats.Reordered++
+	}
+
+	t.stats.Received++
+}
+
+// addGap враховує пропущені номери з проміжку [from, to); викликається під t.mu
+func (t *sequenceTracker) addGap(from, to uint32) {
+	if to <= from {
+		return
+	}
+
+	t.stats.Gaps++
+	t.stats.Missing += int64(to - from)
+
+	// Запам'ятовуються лише номери в межах вікна, решта одразу вважається втраченою
+	if to-from > sequenceWindow {
+		from = to - sequenceWindow
+	}
+	for n := from; n < to; n++ {
+		t.missing[n] = true
+	}
+}
+
+// duplicate враховує повторну передачу вже збереженого пакета
+func (t *sequenceTracker) duplicate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastSeen = time.Now()
+	t.stats.Duplicates++
+}
+
+// reset починає нову епоху нумерації: пристрій почав відлік номерів заново.
+// Пакети, пропущені в попередній епосі, залишаються втраченими.
+func (t *sequenceTracker) reset() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastSeen = time.Now()
+	t.resetLocked()
+	return t.epoch
+}
+
+// resetLocked починає нову епоху нумерації; викликається під t.mu
+func (t *sequenceTracker) resetLocked() {
+	t.epoch++
+	t.stats.Resets++
+	t.started = false
+	t.missing = make(map[uint32]bool)
+}
+
+// idleSince повідомляє, чи не надходили пакети після моменту since
+func (t *sequenceTracker) idleSince(since time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.lastSeen.Before(since)
+}
+
+// forgetBefore прибирає з вікна пропущені номери, які вже не встигнуть надійти
+func (t *sequenceTracker) forgetBefore(highest uint32) {
+	if highest <= sequenceWindow || len(t.missing) == 0 {
+		return
+	}
+
+	for n := range t.missing {
+		if n < highest-sequenceWindow {
+			delete(t.missing, n)
+		}
+	}
+}
+
+// resumePoint повертає найбільший отриманий номер і пропущені номери в межах вікна
+func (t *sequenceTracker) resumePoint() *PacketResumePoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	point := &PacketResumePoint{}
+	if !t.started {
+		return point
+	}
+
+	last := t.highest
+	point.LastSequence = &last
+	point.Missing = make([]uint32, 0, len(t.missing))
+	for n := range t.missing {
+		point.Missing = append(point.Missing, n)
+	}
+	sort.Slice(point.Missing, func(i, j int) bool {
+		return point.Missing[i] < point.Missing[j]
+	})
+
+	return point
+}
+
+// snapshot повертає поточну статистику доставки
+func (t *sequenceTracker) snapshot() *domain.PacketStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := t.stats
+	stats.FirstSequence = t.first
+	stats.LastSequence = t.highest
+	if expected := stats.Received + stats.Missing; expected > 0 {
+		stats.Completeness = float64(stats.Received) / float64(expected)
+	}
+
+	return &stats
+}
@@ -0,0 +1,212 @@
+package application
+
+import (
+	"mine-detection-system/internal/domain"
+	"reflect"
+	"testing"
+)
+
+// sequenceStep подія, яку отримує трекер: збережений пакет, дублікат або скидання нумерації
+type sequenceStep struct {
+	sequence  uint32
+	duplicate bool
+	reset     bool
+	// stale пакет попередньої епохи, збережений уже після скидання нумерації
+	stale bool
+}
+
+func received(sequence uint32) sequenceStep { return sequenceStep{sequence: sequence} }
+func duplicated(sequence uint32) sequenceStep {
+	return sequenceStep{sequence: sequence, duplicate: true}
+}
+func stale(sequence uint32) sequenceStep { return sequenceStep{sequence: sequence, stale: true} }
+func restarted() sequenceStep            { return sequenceStep{reset: true} }
+
+func (s sequenceStep) apply(t *sequenceTracker) {
+	switch {
+	case s.reset:
+		t.reset()
+	case s.duplicate:
+		t.duplicate()
+	case s.stale:
+		t.observe(t.currentEpoch()-1, s.sequence)
+	default:
+		t.observe(t.currentEpoch(), s.sequence)
+	}
+}
+
+func TestSequenceTrackerObserve(t *testing.T) {
+	tests := []struct {
+		name        string
+		steps       []sequenceStep
+		want        domain.PacketStats
+		wantLast    *uint32
+		wantMissing []uint32
+	}{
+		{
+			name:        "no packets",
+			wantMissing: nil,
+		},
+		{
+			name:        "in order",
+			steps:       []sequenceStep{received(1), received(2), received(3)},
+			want:        domain.PacketStats{Received: 3, FirstSequence: 1, LastSequence: 3, Completeness: 1},
+			wantLast:    uint32Ptr(3),
+			wantMissing: []uint32{},
+		},
+		{
+			name:        "gap",
+			steps:       []sequenceStep{received(1), received(2), received(5)},
+			want:        domain.PacketStats{Received: 3, Gaps: 1, Missing: 2, FirstSequence: 1, LastSequence: 5, Completeness: 0.6},
+			wantLast:    uint32Ptr(5),
+			wantMissing: []uint32{3, 4},
+		},
+		{
+			name:        "reordered packets fill the gap",
+			steps:       []sequenceStep{received(1), received(4), received(3), received(2)},
+			want:        domain.PacketStats{Received: 4, Reordered: 2, Gaps: 1, FirstSequence: 1, LastSequence: 4, Completeness: 1},
+			wantLast:    uint32Ptr(4),
+			wantMissing: []uint32{},
+		},
+		{
+			name:        "packet sent before the first received",
+			steps:       []sequenceStep{received(5), received(3)},
+			want:        domain.PacketStats{Received: 2, Reordered: 1, Gaps: 1, Missing: 1, FirstSequence: 3, LastSequence: 5, Completeness: 2.0 / 3},
+			wantLast:    uint32Ptr(5),
+			wantMissing: []uint32{4},
+		},
+		{
+			name:        "duplicate",
+			steps:       []sequenceStep{received(1), received(2), duplicated(2), duplicated(1)},
+			want:        domain.PacketStats{Received: 2, Duplicates: 2, FirstSequence: 1, LastSequence: 2, Completeness: 1},
+			wantLast:    uint32Ptr(2),
+			wantMissing: []uint32{},
+		},
+		{
+			name:        "reset starts a new epoch",
+			steps:       []sequenceStep{received(10), received(11), restarted(), received(1), received(2)},
+			want:        domain.PacketStats{Received: 4, Resets: 1, FirstSequence: 1, LastSequence: 2, Completeness: 1},
+			wantLast:    uint32Ptr(2),
+			wantMissing: []uint32{},
+		},
+		{
+			name:        "packets missing before a reset stay lost",
+			steps:       []sequenceStep{received(1), received(4), restarted(), received(1)},
+			want:        domain.PacketStats{Received: 3, Resets: 1, Gaps: 1, Missing: 2, FirstSequence: 1, LastSequence: 1, Completeness: 3.0 / 5},
+			wantLast:    uint32Ptr(1),
+			wantMissing: []uint32{},
+		},
+		{
+			name:        "packet of the previous epoch after a reset",
+			steps:       []sequenceStep{received(1), restarted(), stale(2), received(1)},
+			want:        domain.PacketStats{Received: 3, Resets: 1, FirstSequence: 1, LastSequence: 1, Completeness: 1},
+			wantLast:    uint32Ptr(1),
+			wantMissing: []uint32{},
+		},
+		{
+			name:        "reset without new packets",
+			steps:       []sequenceStep{received(7), restarted()},
+			want:        domain.PacketStats{Received: 1, Resets: 1, FirstSequence: 7, LastSequence: 7, Completeness: 1},
+			wantMissing: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newSequenceTracker()
+			for _, step := range tt.steps {
+				step.apply(tracker)
+			}
+
+			if stats := tracker.snapshot(); !reflect.DeepEqual(*stats, tt.want) {
+				t.Errorf("stats = %+v, want %+v", *stats, tt.want)
+			}
+
+			point := tracker.resumePoint()
+			if !reflect.DeepEqual(point.LastSequence, tt.wantLast) {
+				t.Errorf("last sequence = %v, want %v", deref(point.LastSequence), deref(tt.wantLast))
+			}
+			if !reflect.DeepEqual(point.Missing, tt.wantMissing) {
+				t.Errorf("missing = %v, want %v", point.Missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestSequenceTrackerWindow(t *testing.T) {
+	tracker := newSequenceTracker()
+	tracker.observe(0, 1)
+	tracker.observe(0, sequenceWindow+10)
+
+	// Пропуск довший за вікно: запам'ятовуються лише останні sequenceWindow номерів
+	point := tracker.resumePoint()
+	if len(point.Missing) != sequenceWindow {
+		t.Fatalf("remembered %d missing packets, want %d", len(point.Missing), sequenceWindow)
+	}
+	if first := point.Missing[0]; first != 10 {
+		t.Errorf("first remembered missing packet = %d, want 10", first)
+	}
+
+	// Пакет, що випав з вікна, все ж приймається і зменшує кількість втрачених
+	tracker.observe(0, 2)
+	stats := tracker.snapshot()
+	if stats.Missing != sequenceWindow+7 || stats.Reordered != 1 || stats.Received != 3 {
+		t.Errorf("stats = %+v, want missing %d, reordered 1, received 3", *stats, sequenceWindow+7)
+	}
+	if got := len(tracker.resumePoint().Missing); got != sequenceWindow {
+		t.Errorf("remembered %d missing packets after late packet, want %d", got, sequenceWindow)
+	}
+}
+
+func TestRestoreSequenceTracker(t *testing.T) {
+	packets := []*domain.SensorPacket{
+		{Epoch: 0, Sequence: 1},
+		{Epoch: 0, Sequence: 2},
+		{Epoch: 0, Sequence: 5},
+		{Epoch: 1, Sequence: 1},
+		{Epoch: 1, Sequence: 3},
+	}
+	saved := &domain.PacketStats{Duplicates: 4, Reordered: 2, Gaps: 1}
+
+	tracker := restoreSequenceTracker(packets, saved)
+
+	if epoch := tracker.currentEpoch(); epoch != 1 {
+		t.Errorf("epoch = %d, want 1", epoch)
+	}
+
+	want := domain.PacketStats{
+		Received:      5,
+		Duplicates:    4,
+		Reordered:     2,
+		Gaps:          2,
+		Missing:       3,
+		Resets:        1,
+		FirstSequence: 1,
+		LastSequence:  3,
+		Completeness:  5.0 / 8,
+	}
+	if stats := tracker.snapshot(); !reflect.DeepEqual(*stats, want) {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+
+	point := tracker.resumePoint()
+	if deref(point.LastSequence) != 3 || !reflect.DeepEqual(point.Missing, []uint32{2}) {
+		t.Errorf("resume point = (%d, %v), want (3, [2])", deref(point.LastSequence), point.Missing)
+	}
+
+	// Пакет, якого бракувало, приймається в поточній епосі після відновлення
+	tracker.observe(1, 2)
+	if point := tracker.resumePoint(); len(point.Missing) != 0 {
+		t.Errorf("missing after late packet = %v, want none", point.Missing)
+	}
+}
+
+func uint32Ptr(v uint32) *uint32 { return &v }
+
+// deref повертає значення вказівника або нуль для nil
+func deref(v *uint32) uint32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
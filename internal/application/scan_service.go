@@ -0,0 +1,388 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"sync"
+	"time"
+)
+
+// ErrScanNotActive повертається для даних або запитів до сканування, яке вже завершено
+var ErrScanNotActive = errors.New("scan is not in progress")
+
+// ScanService відповідає за життєвий цикл сеансів сканування
+type ScanService struct {
+	scanRepo       ports.ScanRepository
+	deviceRepo     ports.DeviceRepository
+	missionRepo    ports.MissionRepository
+	sensorDataRepo ports.SensorDataRepository
+	fusionService  *SensorFusionService
+
+	// sequences трекери порядкових номерів пакетів активних сканувань
+	sequences   map[uuid.UUID]*sequenceTracker
+	sequencesMu sync.Mutex
+
+	// receiving впорядковує прийом пакетів і завершення сканувань: пакет, перевірений
+	// як пакет активного сканування, зберігається до того, як EndScan запустить злиття
+	receiving sync.RWMutex
+}
+
+// NewScanService створює новий екземпляр ScanService
+func NewScanService(
+	scanRepo ports.ScanRepository,
+	deviceRepo ports.DeviceRepository,
+	missionRepo ports.MissionRepository,
+	sensorDataRepo ports.SensorDataRepository,
+	fusionService *SensorFusionService,
+) *ScanService {
+	return &ScanService{
+		scanRepo:       scanRepo,
+		deviceRepo:     deviceRepo,
+		missionRepo:    missionRepo,
+		sensorDataRepo: sensorDataRepo,
+		fusionService:  fusionService,
+		sequences:      make(map[uuid.UUID]*sequenceTracker),
+	}
+}
+
+// StartScan відкриває нове сканування для активного пристрою в межах активної місії
+func (s *ScanService) StartScan(ctx context.Context, deviceID, missionID uuid.UUID, scanType string, metadata interface{}) (*domain.Scan, error) {
+	if scanType == "" {
+		return nil, errors.New("scan type is required")
+	}
+
+	device, err := s.deviceRepo.FindByID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device.Status != domain.DeviceStatusActive {
+		return nil, fmt.Errorf("device is %s, expected %s", device.Status, domain.DeviceStatusActive)
+	}
+
+	mission, err := s.missionRepo.FindByID(ctx, missionID)
+	if err != nil {
+		return nil, err
+	}
+	if mission.Status != domain.MissionStatusActive {
+		return nil, fmt.Errorf("mission is %s, expected %s", mission.Status, domain.MissionStatusActive)
+	}
+
+	// Пристрій не може вести два сканування одночасно
+	scans, err := s.scanRepo.FindByDeviceID(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, scan := range scans {
+		if scan.Status == domain.ScanStatusInProgress {
+			return nil, fmt.Errorf("device already has scan %s in progress", scan.ID)
+		}
+	}
+
+	scan := &domain.Scan{
+		ID:        uuid.New(),
+		MissionID: missionID,
+		DeviceID:  deviceID,
+		StartTime: time.Now(),
+		ScanType:  scanType,
+		Status:    domain.ScanStatusInProgress,
+		Metadata:  metadata,
+	}
+
+	if err := s.scanRepo.Save(ctx, scan); err != nil {
+		return nil, err
+	}
+
+	return scan, nil
+}
+
+// EndScan закриває сканування пристрою зі статусом completed або failed.
+// Для успішно завершеного сканування одразу запускається злиття даних і виявлення об'єктів.
+func (s *ScanService) EndScan(ctx context.Context, deviceID, scanID uuid.UUID, status domain.ScanStatus) (*domain.Scan, []*domain.DetectedObject, error) {
+	if status != domain.ScanStatusCompleted && status != domain.ScanStatusFailed {
+		return nil, nil, fmt.Errorf("invalid final scan status %q", status)
+	}
+
+	scan, err := s.closeScan(ctx, deviceID, scanID, status)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Потокові дані більше не надходять; остаточний результат дає пакетне злиття
+	s.fusionService.CloseStream(scanID)
+
+	if status != domain.ScanStatusCompleted {
+		return scan, nil, nil
+	}
+
+	detections, err := s.fusionService.FuseAndDetect(ctx, scanID, "")
+	if err != nil {
+		return scan, nil, fmt.Errorf("fusion failed for scan %s: %w", scanID, err)
+	}
+
+	return scan, detections, nil
+}
+
+// closeScan переводить сканування в остаточний статус разом зі статистикою пакетів.
+// Пакети, прийом яких уже почався, зберігаються до зміни статусу, а наступні відхиляються.
+func (s *ScanService) closeScan(ctx context.Context, deviceID, scanID uuid.UUID, status domain.ScanStatus) (*domain.Scan, error) {
+	s.receiving.Lock()
+	defer s.receiving.Unlock()
+
+	scan, err := s.scanRepo.FindByID(ctx, scanID)
+	if err != nil {
+		return nil, err
+	}
+	if scan.DeviceID != deviceID {
+		return nil, errors.New("scan belongs to another device")
+	}
+	if scan.Status != domain.ScanStatusInProgress {
+		return nil, fmt.Errorf("%w: scan is already %s", ErrScanNotActive, scan.Status)
+	}
+
+	// Статистика пакетів береться з трекера, відновленого з квитанцій, якщо його немає в пам'яті
+	tracker, err := s.sequenceTracker(ctx, scan)
+	if err != nil {
+		return nil, err
+	}
+	if stats := tracker.snapshot(); stats.Received > 0 || stats.Duplicates > 0 {
+		scan.PacketStats = stats
+	}
+
+	now := time.Now()
+	scan.EndTime = &now
+	scan.Status = status
+
+	if err := s.scanRepo.Update(ctx, scan); err != nil {
+		return nil, err
+	}
+
+	s.sequencesMu.Lock()
+	delete(s.sequences, scanID)
+	s.sequencesMu.Unlock()
+
+	return scan, nil
+}
+
+// GetScanByID отримує сканування за ID
+func (s *ScanService) GetScanByID(ctx context.Context, scanID uuid.UUID) (*domain.Scan, error) {
+	scan, err := s.scanRepo.FindByID(ctx, scanID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.attachLiveStats(scan)
+	return scan, nil
+}
+
+// ListMissionScans отримує всі сканування місії
+func (s *ScanService) ListMissionScans(ctx context.Context, missionID uuid.UUID) ([]*domain.Scan, error) {
+	if _, err := s.missionRepo.FindByID(ctx, missionID); err != nil {
+		return nil, err
+	}
+
+	scans, err := s.scanRepo.FindByMissionID(ctx, missionID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scan := range scans {
+		s.attachLiveStats(scan)
+	}
+	return scans, nil
+}
+
+// ReceivePacket зберігає виміри пакета з порядковим номером рівно один раз.
+// Повторна передача вже збереженого пакета повертає ports.ErrDuplicatePacket.
+// Той самий номер з іншим вмістом означає, що пристрій почав нумерацію заново,
+// не повідомивши про це: пакет зберігається в новій епосі нумерації.
+// Помилка, що обгортає ErrStreamingFusion, означає, що виміри збережено.
+// Нові пакети завершеного сканування не зберігаються: його дані вже об'єднано,
+// тож повертається ErrScanNotActive. Повторна передача пакета, збереженого до
+// завершення, як і раніше повертає ports.ErrDuplicatePacket.
+func (s *ScanService) ReceivePacket(ctx context.Context, deviceID, scanID uuid.UUID, sequence uint32, digest string, readings []SensorReading) error {
+	s.receiving.RLock()
+	defer s.receiving.RUnlock()
+
+	scan, err := s.scanRepo.FindByID(ctx, scanID)
+	if err != nil {
+		return err
+	}
+	if scan.DeviceID != deviceID {
+		return errors.New("scan belongs to another device")
+	}
+
+	tracker, err := s.sequenceTracker(ctx, scan)
+	if err != nil {
+		return err
+	}
+
+	if scan.Status != domain.ScanStatusInProgress {
+		// Підтвердження останніх пакетів могло загубитися, поки пристрій завершував сканування
+		stored, findErr := s.sensorDataRepo.FindPacket(ctx, scanID, tracker.currentEpoch(), sequence)
+		if findErr == nil && stored.Digest == digest {
+			return ports.ErrDuplicatePacket
+		}
+		return fmt.Errorf("%w: scan is already %s", ErrScanNotActive, scan.Status)
+	}
+
+	packet := &domain.SensorPacket{
+		ScanID:     scanID,
+		Epoch:      tracker.currentEpoch(),
+		Sequence:   sequence,
+		Digest:     digest,
+		ReceivedAt: time.Now(),
+	}
+
+	err = s.fusionService.ProcessSensorPacket(ctx, packet, readings)
+	if errors.Is(err, ports.ErrDuplicatePacket) {
+		stored, findErr := s.sensorDataRepo.FindPacket(ctx, scanID, packet.Epoch, sequence)
+		if findErr != nil {
+			return findErr
+		}
+		if stored.Digest == digest {
+			tracker.duplicate()
+			return err
+		}
+
+		packet.Epoch = tracker.reset()
+		err = s.fusionService.ProcessSensorPacket(ctx, packet, readings)
+	}
+	if err != nil && !errors.Is(err, ErrStreamingFusion) {
+		return err
+	}
+
+	tracker.observe(packet.Epoch, sequence)
+	return err
+}
+
+// PacketResumePoint повертає, які пакети активного сканування сервер уже зберіг.
+// Якщо reset задано, пристрій почав нумерацію пакетів заново (наприклад, після
+// перезавантаження): сервер починає нову епоху, і раніше використані номери
+// більше не вважаються дублікатами.
+func (s *ScanService) PacketResumePoint(ctx context.Context, deviceID, scanID uuid.UUID, reset bool) (*PacketResumePoint, error) {
+	scan, err := s.scanRepo.FindByID(ctx, scanID)
+	if err != nil {
+		return nil, err
+	}
+	if scan.DeviceID != deviceID {
+		return nil, errors.New("scan belongs to another device")
+	}
+	if scan.Status != domain.ScanStatusInProgress {
+		return nil, fmt.Errorf("%w: scan is already %s", ErrScanNotActive, scan.Status)
+	}
+
+	tracker, err := s.sequenceTracker(ctx, scan)
+	if err != nil {
+		return nil, err
+	}
+
+	if reset {
+		tracker.reset()
+	}
+	return tracker.resumePoint(), nil
+}
+
+// sequenceTracker повертає трекер пакетів сканування. Трекер, якого немає в пам'яті
+// (після перезапуску сервера або вивантаження), відновлюється з квитанцій пакетів.
+// Трекери завершених сканувань не кешуються.
+func (s *ScanService) sequenceTracker(ctx context.Context, scan *domain.Scan) (*sequenceTracker, error) {
+	s.sequencesMu.Lock()
+	tracker, ok := s.sequences[scan.ID]
+	s.sequencesMu.Unlock()
+	if ok {
+		return tracker, nil
+	}
+
+	packets, err := s.sensorDataRepo.FindPackets(ctx, scan.ID)
+	if err != nil {
+		return nil, err
+	}
+	tracker = restoreSequenceTracker(packets, scan.PacketStats)
+
+	if scan.Status != domain.ScanStatusInProgress {
+		return tracker, nil
+	}
+
+	s.sequencesMu.Lock()
+	// Паралельний виклик міг уже створити трекер
+	if existing, ok := s.sequences[scan.ID]; ok {
+		s.sequencesMu.Unlock()
+		return existing, nil
+	}
+	s.sequences[scan.ID] = tracker
+	idle := s.evictIdleLocked(time.Now().Add(-sequenceIdleTimeout))
+	s.sequencesMu.Unlock()
+
+	s.saveIdleStats(ctx, idle)
+	return tracker, nil
+}
+
+// evictIdleLocked вивантажує трекери сканувань, пакети яких давно не надходили,
+// і повертає їх для збереження статистики; викликається під s.sequencesMu
+func (s *ScanService) evictIdleLocked(since time.Time) map[uuid.UUID]*sequenceTracker {
+	idle := make(map[uuid.UUID]*sequenceTracker)
+	for scanID, tracker := range s.sequences {
+		if tracker.idleSince(since) {
+			idle[scanID] = tracker
+			delete(s.sequences, scanID)
+		}
+	}
+	return idle
+}
+
+// saveIdleStats зберігає статистику вивантажених трекерів разом зі скануваннями,
+// щоб відновлений пізніше трекер не втратив лічильники дублікатів і перестановок
+func (s *ScanService) saveIdleStats(ctx context.Context, idle map[uuid.UUID]*sequenceTracker) {
+	for scanID, tracker := range idle {
+		scan, err := s.scanRepo.FindByID(ctx, scanID)
+		if err != nil {
+			log.Printf("Error saving packet stats for idle scan %s: %v", scanID, err)
+			continue
+		}
+		if scan.Status != domain.ScanStatusInProgress {
+			continue
+		}
+
+		scan.PacketStats = tracker.snapshot()
+		if err := s.scanRepo.Update(ctx, scan); err != nil {
+			log.Printf("Error saving packet stats for idle scan %s: %v", scanID, err)
+		}
+	}
+}
+
+// packetStats повертає поточну статистику пакетів сканування або nil, якщо номери не надходили
+func (s *ScanService) packetStats(scanID uuid.UUID) *domain.PacketStats {
+	s.sequencesMu.Lock()
+	tracker, ok := s.sequences[scanID]
+	s.sequencesMu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return tracker.snapshot()
+}
+
+// attachLiveStats додає до активного сканування статистику пакетів, яка ще не збережена
+func (s *ScanService) attachLiveStats(scan *domain.Scan) {
+	if scan.Status != domain.ScanStatusInProgress {
+		return
+	}
+
+	if stats := s.packetStats(scan.ID); stats != nil {
+		scan.PacketStats = stats
+	}
+}
+
+// GetSensorData отримує сторінку даних сенсорів сканування
+func (s *ScanService) GetSensorData(ctx context.Context, scanID uuid.UUID, limit, offset int) ([]*domain.SensorData, error) {
+	if _, err := s.scanRepo.FindByID(ctx, scanID); err != nil {
+		return nil, err
+	}
+
+	return s.sensorDataRepo.FindByScanID(ctx, scanID, limit, offset)
+}
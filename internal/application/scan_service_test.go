@@ -0,0 +1,273 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/infrastructure/events"
+	"mine-detection-system/internal/infrastructure/memory"
+	"mine-detection-system/internal/ports"
+	"mine-detection-system/pkg/fusion"
+	"mine-detection-system/pkg/protocol"
+	"testing"
+	"time"
+)
+
+// packetTestEnv сервіси сканування поверх сховищ у пам'яті з одним активним скануванням
+type packetTestEnv struct {
+	scans      *memory.ScanRepository
+	devices    *memory.DeviceRepository
+	missions   *memory.MissionRepository
+	sensorData *memory.SensorDataRepository
+	fusion     *SensorFusionService
+	service    *ScanService
+	scan       *domain.Scan
+}
+
+func newPacketTestEnv(t *testing.T) *packetTestEnv {
+	t.Helper()
+	ctx := context.Background()
+
+	env := &packetTestEnv{
+		scans:      memory.NewScanRepository(),
+		devices:    memory.NewDeviceRepository(),
+		missions:   memory.NewMissionRepository(),
+		sensorData: memory.NewSensorDataRepository(),
+	}
+	profiles := NewDetectionProfileService(memory.NewDetectionProfileRepository(), env.missions, fusion.DefaultClassifier())
+	env.fusion = NewSensorFusionService(env.sensorData, memory.NewDetectedObjectRepository(), env.scans,
+		memory.NewSightingRepository(), profiles, events.NewBus())
+	env.service = env.restart()
+
+	device := &domain.Device{ID: uuid.New(), DeviceType: "multi", SerialNumber: "SN-1", Status: domain.DeviceStatusActive}
+	if err := env.devices.Save(ctx, device); err != nil {
+		t.Fatalf("save device: %v", err)
+	}
+	mission := &domain.Mission{ID: uuid.New(), Name: "test", Status: domain.MissionStatusActive, StartDate: time.Now()}
+	if err := env.missions.Save(ctx, mission); err != nil {
+		t.Fatalf("save mission: %v", err)
+	}
+
+	scan, err := env.service.StartScan(ctx, device.ID, mission.ID, "lane", nil)
+	if err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	env.scan = scan
+
+	return env
+}
+
+// restart створює новий ScanService над тими самими сховищами, як після перезапуску сервера
+func (env *packetTestEnv) restart() *ScanService {
+	return NewScanService(env.scans, env.devices, env.missions, env.sensorData, env.fusion)
+}
+
+// receive передає пакет з одним виміром магнітометра; помилка потокового злиття
+// не заважає збереженню, тож не вважається помилкою
+func (env *packetTestEnv) receive(t *testing.T, sequence uint32, digest string) error {
+	t.Helper()
+
+	payload, err := protocol.EncodeMagnetic(&protocol.MagneticVector{
+		SampleRate: 10,
+		Readings:   []protocol.MagneticReading{{X: 48000, Y: 100, Z: -200}},
+	})
+	if err != nil {
+		t.Fatalf("EncodeMagnetic: %v", err)
+	}
+	readings := []SensorReading{{
+		SensorType: fusion.SensorMagnetic,
+		Data:       payload,
+		Metadata: map[string]interface{}{
+			"latitude":  50.0 + float64(sequence)*1e-6,
+			"longitude": 30.0,
+			"altitude":  0.0,
+			"quality":   map[string]interface{}{},
+		},
+	}}
+
+	err = env.service.ReceivePacket(context.Background(), env.scan.DeviceID, env.scan.ID, sequence, digest, readings)
+	if errors.Is(err, ErrStreamingFusion) {
+		return nil
+	}
+	return err
+}
+
+// stored повертає кількість збережених вимірів сканування
+func (env *packetTestEnv) stored(t *testing.T) int {
+	t.Helper()
+
+	data, err := env.sensorData.FindByScanID(context.Background(), env.scan.ID, 1000, 0)
+	if err != nil {
+		t.Fatalf("FindByScanID: %v", err)
+	}
+	return len(data)
+}
+
+func (env *packetTestEnv) stats(t *testing.T) domain.PacketStats {
+	t.Helper()
+
+	scan, err := env.service.GetScanByID(context.Background(), env.scan.ID)
+	if err != nil {
+		t.Fatalf("GetScanByID: %v", err)
+	}
+	if scan.PacketStats == nil {
+		t.Fatal("scan has no packet stats")
+	}
+	return *scan.PacketStats
+}
+
+func TestReceivePacketDeduplicates(t *testing.T) {
+	env := newPacketTestEnv(t)
+
+	for _, sequence := range []uint32{1, 2, 3} {
+		if err := env.receive(t, sequence, "digest-"+string(rune('0'+sequence))); err != nil {
+			t.Fatalf("packet %d: %v", sequence, err)
+		}
+	}
+
+	// Повторна передача з тим самим вмістом не зберігає виміри вдруге
+	if err := env.receive(t, 2, "digest-2"); !errors.Is(err, ports.ErrDuplicatePacket) {
+		t.Fatalf("retransmitted packet: got error %v, want %v", err, ports.ErrDuplicatePacket)
+	}
+	if n := env.stored(t); n != 3 {
+		t.Errorf("stored %d samples, want 3", n)
+	}
+
+	stats := env.stats(t)
+	if stats.Received != 3 || stats.Duplicates != 1 || stats.Resets != 0 {
+		t.Errorf("stats = %+v, want 3 received, 1 duplicate, no resets", stats)
+	}
+}
+
+func TestReceivePacketDetectsImplicitReset(t *testing.T) {
+	env := newPacketTestEnv(t)
+
+	for _, sequence := range []uint32{1, 2} {
+		if err := env.receive(t, sequence, "before"); err != nil {
+			t.Fatalf("packet %d: %v", sequence, err)
+		}
+	}
+
+	// Той самий номер з іншим вмістом: пристрій почав нумерацію заново, не повідомивши
+	if err := env.receive(t, 1, "after"); err != nil {
+		t.Fatalf("packet after reset: %v", err)
+	}
+	if err := env.receive(t, 1, "after"); !errors.Is(err, ports.ErrDuplicatePacket) {
+		t.Errorf("retransmitted packet after reset: got error %v, want %v", err, ports.ErrDuplicatePacket)
+	}
+	if n := env.stored(t); n != 3 {
+		t.Errorf("stored %d samples, want 3", n)
+	}
+
+	stats := env.stats(t)
+	if stats.Received != 3 || stats.Resets != 1 || stats.Duplicates != 1 {
+		t.Errorf("stats = %+v, want 3 received, 1 reset, 1 duplicate", stats)
+	}
+}
+
+func TestPacketResumePointExplicitReset(t *testing.T) {
+	env := newPacketTestEnv(t)
+	ctx := context.Background()
+
+	for _, sequence := range []uint32{1, 3} {
+		if err := env.receive(t, sequence, "same"); err != nil {
+			t.Fatalf("packet %d: %v", sequence, err)
+		}
+	}
+
+	point, err := env.service.PacketResumePoint(ctx, env.scan.DeviceID, env.scan.ID, false)
+	if err != nil {
+		t.Fatalf("PacketResumePoint: %v", err)
+	}
+	if point.LastSequence == nil || *point.LastSequence != 3 || len(point.Missing) != 1 || point.Missing[0] != 2 {
+		t.Fatalf("resume point = (%v, %v), want (3, [2])", deref(point.LastSequence), point.Missing)
+	}
+
+	point, err = env.service.PacketResumePoint(ctx, env.scan.DeviceID, env.scan.ID, true)
+	if err != nil {
+		t.Fatalf("PacketResumePoint with reset: %v", err)
+	}
+	if point.LastSequence != nil || len(point.Missing) != 0 {
+		t.Fatalf("resume point after reset = (%d, %v), want nothing received", deref(point.LastSequence), point.Missing)
+	}
+
+	// Після явного скидання той самий номер і вміст - новий пакет, а не дублікат
+	if err := env.receive(t, 1, "same"); err != nil {
+		t.Fatalf("packet after explicit reset: %v", err)
+	}
+	if n := env.stored(t); n != 3 {
+		t.Errorf("stored %d samples, want 3", n)
+	}
+}
+
+func TestReceivePacketAfterRestart(t *testing.T) {
+	env := newPacketTestEnv(t)
+	ctx := context.Background()
+
+	for _, sequence := range []uint32{1, 2, 4} {
+		if err := env.receive(t, sequence, "before"); err != nil {
+			t.Fatalf("packet %d: %v", sequence, err)
+		}
+	}
+
+	// Новий сервіс не має трекерів у пам'яті й відновлює їх з квитанцій пакетів
+	env.service = env.restart()
+
+	if err := env.receive(t, 4, "before"); !errors.Is(err, ports.ErrDuplicatePacket) {
+		t.Errorf("retransmitted packet after restart: got error %v, want %v", err, ports.ErrDuplicatePacket)
+	}
+
+	point, err := env.service.PacketResumePoint(ctx, env.scan.DeviceID, env.scan.ID, false)
+	if err != nil {
+		t.Fatalf("PacketResumePoint: %v", err)
+	}
+	if point.LastSequence == nil || *point.LastSequence != 4 || len(point.Missing) != 1 || point.Missing[0] != 3 {
+		t.Errorf("resume point = (%v, %v), want (4, [3])", deref(point.LastSequence), point.Missing)
+	}
+
+	if err := env.receive(t, 3, "before"); err != nil {
+		t.Fatalf("missing packet after restart: %v", err)
+	}
+
+	scan, _, err := env.service.EndScan(ctx, env.scan.DeviceID, env.scan.ID, domain.ScanStatusFailed)
+	if err != nil {
+		t.Fatalf("EndScan: %v", err)
+	}
+	if stats := scan.PacketStats; stats == nil || stats.Received != 4 || stats.Missing != 0 || stats.Duplicates != 1 {
+		t.Errorf("final stats = %+v, want 4 received, none missing, 1 duplicate", stats)
+	}
+}
+
+func TestReceivePacketRejectsEndedScan(t *testing.T) {
+	env := newPacketTestEnv(t)
+	ctx := context.Background()
+
+	for _, sequence := range []uint32{1, 2} {
+		if err := env.receive(t, sequence, "before"); err != nil {
+			t.Fatalf("packet %d: %v", sequence, err)
+		}
+	}
+	if _, _, err := env.service.EndScan(ctx, env.scan.DeviceID, env.scan.ID, domain.ScanStatusCompleted); err != nil {
+		t.Fatalf("EndScan: %v", err)
+	}
+
+	// Дані завершеного сканування вже об'єднано, тож новий пакет не зберігається
+	if err := env.receive(t, 3, "after"); !errors.Is(err, ErrScanNotActive) {
+		t.Errorf("packet after end: got error %v, want %v", err, ErrScanNotActive)
+	}
+	if err := env.fusion.ProcessSensorData(ctx, env.scan.ID, fusion.SensorMagnetic, nil, nil); !errors.Is(err, ErrScanNotActive) {
+		t.Errorf("unsequenced data after end: got error %v, want %v", err, ErrScanNotActive)
+	}
+	if n := env.stored(t); n != 2 {
+		t.Errorf("stored %d samples, want 2", n)
+	}
+
+	// Пакет, збережений до завершення, підтверджується як дублікат, якщо підтвердження загубилося
+	if err := env.receive(t, 2, "before"); !errors.Is(err, ports.ErrDuplicatePacket) {
+		t.Errorf("retransmitted packet after end: got error %v, want %v", err, ports.ErrDuplicatePacket)
+	}
+	if _, err := env.service.PacketResumePoint(ctx, env.scan.DeviceID, env.scan.ID, false); !errors.Is(err, ErrScanNotActive) {
+		t.Errorf("PacketResumePoint after end: got error %v, want %v", err, ErrScanNotActive)
+	}
+}
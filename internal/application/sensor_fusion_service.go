@@ -3,18 +3,68 @@ package application
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"math"
 	"mine-detection-system/internal/domain"
 	"mine-detection-system/internal/ports"
 	"mine-detection-system/pkg/fusion"
+	"mine-detection-system/pkg/geo"
+	"sync"
 	"time"
 )
 
+// ErrStreamingFusion повертається ProcessSensorData, якщо дані збережено,
+// але не вдалося оновити потоковий стан злиття сканування
+var ErrStreamingFusion = errors.New("streaming fusion failed")
+
 // SensorFusionService відповідає за обробку та злиття даних з різних сенсорів
 type SensorFusionService struct {
 	sensorDataRepo     ports.SensorDataRepository
 	detectedObjectRepo ports.DetectedObjectRepository
 	scanRepo           ports.ScanRepository
+	sightingRepo       ports.SightingRepository
+	profileService     *DetectionProfileService
+	eventBus           ports.EventBus
+
+	// Стан потокового злиття активних сканувань
+	streamsMu   sync.Mutex
+	streams     map[uuid.UUID]*scanStream
+	provisional []ProvisionalDetectionHandler
+}
+
+// ProvisionalDetection попередня детекція, отримана потоковим злиттям під час сканування.
+// Вона не зберігається: остаточні об'єкти створює FuseAndDetect після завершення сканування.
+type ProvisionalDetection struct {
+	ScanID       uuid.UUID `json:"scan_id"`
+	MissionID    uuid.UUID `json:"mission_id"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	ObjectType   string    `json:"object_type"`
+	Confidence   float64   `json:"confidence"`
+	Belief       float64   `json:"belief"`
+	Plausibility float64   `json:"plausibility"`
+	DangerLevel  int       `json:"danger_level"`
+	DetectedAt   time.Time `json:"detected_at"`
+}
+
+// ProvisionalDetectionHandler отримує попередні детекції потокового злиття
+type ProvisionalDetectionHandler func(detection ProvisionalDetection)
+
+// coverageInterval мінімальний інтервал між подіями покриття одного сканування
+const coverageInterval = time.Second
+
+// streamIdleTimeout час без вимірів, після якого потоковий стан сканування
+// звільняється, навіть якщо сканування не завершено явно
+const streamIdleTimeout = 15 * time.Minute
+
+// scanStream потоковий стан злиття одного сканування
+type scanStream struct {
+	mu             sync.Mutex
+	missionID      uuid.UUID
+	stream         *fusion.Stream
+	coveragePushed time.Time
+	lastSample     time.Time
 }
 
 // NewSensorFusionService створює новий екземпляр SensorFusionService
@@ -22,126 +72,543 @@ func NewSensorFusionService(
 	sensorDataRepo ports.SensorDataRepository,
 	detectedObjectRepo ports.DetectedObjectRepository,
 	scanRepo ports.ScanRepository,
+	sightingRepo ports.SightingRepository,
+	profileService *DetectionProfileService,
+	eventBus ports.EventBus,
 ) *SensorFusionService {
 	return &SensorFusionService{
 		sensorDataRepo:     sensorDataRepo,
 		detectedObjectRepo: detectedObjectRepo,
 		scanRepo:           scanRepo,
+		sightingRepo:       sightingRepo,
+		profileService:     profileService,
+		eventBus:           eventBus,
+		streams:            make(map[uuid.UUID]*scanStream),
 	}
 }
 
+// OnProvisionalDetection реєструє обробник попередніх детекцій.
+// Обробники викликаються синхронно з ProcessSensorData і не повинні блокуватися.
+func (s *SensorFusionService) OnProvisionalDetection(handler ProvisionalDetectionHandler) {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+
+	s.provisional = append(s.provisional, handler)
+}
+
+// CloseStream звільняє потоковий стан сканування після його завершення
+func (s *SensorFusionService) CloseStream(scanID uuid.UUID) {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+
+	delete(s.streams, scanID)
+}
+
+// SensorReading один вимір сенсора для пакетної обробки
+type SensorReading struct {
+	SensorType string
+	Data       []byte
+	Metadata   map[string]interface{}
+}
+
 // ProcessSensorData обробляє дані з сенсорів та зберігає оброблені дані
 func (s *SensorFusionService) ProcessSensorData(ctx context.Context, scanID uuid.UUID, sensorType string, data []byte, metadata map[string]interface{}) error {
-	// Перевірка, чи існує сканування
-	if _, err := s.scanRepo.FindByID(ctx, scanID); err != nil {
+	return s.ProcessSensorBatch(ctx, scanID, []SensorReading{{SensorType: sensorType, Data: data, Metadata: metadata}})
+}
+
+// ProcessSensorBatch обробляє кадр вимірів і зберігає їх одним пакетним записом.
+// Якщо хоча б один вимір некоректний, кадр не зберігається.
+func (s *SensorFusionService) ProcessSensorBatch(ctx context.Context, scanID uuid.UUID, readings []SensorReading) error {
+	return s.processReadings(ctx, scanID, nil, readings)
+}
+
+// ProcessSensorPacket обробляє кадр пакета з порядковим номером і зберігає виміри
+// разом з квитанцією пакета. Для вже збереженої квитанції повертає ports.ErrDuplicatePacket.
+func (s *SensorFusionService) ProcessSensorPacket(ctx context.Context, packet *domain.SensorPacket, readings []SensorReading) error {
+	return s.processReadings(ctx, packet.ScanID, packet, readings)
+}
+
+// processReadings декодує виміри кадру, зберігає їх і передає в потокове злиття.
+// Квитанція packet, якщо задана, зберігається в тому самому записі.
+func (s *SensorFusionService) processReadings(ctx context.Context, scanID uuid.UUID, packet *domain.SensorPacket, readings []SensorReading) error {
+	// Дані приймаються лише для активного сканування: завершене вже об'єднано
+	scan, err := s.scanRepo.FindByID(ctx, scanID)
+	if err != nil {
 		return err
 	}
+	if scan.Status != domain.ScanStatusInProgress {
+		return fmt.Errorf("%w: scan is already %s", ErrScanNotActive, scan.Status)
+	}
+
+	samples := make([]*domain.SensorData, len(readings))
+	for i, reading := range readings {
+		sample, err := s.newSensorData(scanID, reading)
+		if err != nil {
+			if len(readings) > 1 {
+				return fmt.Errorf("sample %d: %w", i, err)
+			}
+			return err
+		}
+		samples[i] = sample
+	}
+
+	// Збереження даних
+	if packet != nil {
+		err = s.sensorDataRepo.SavePacket(ctx, packet, samples)
+	} else {
+		err = s.sensorDataRepo.SaveBatch(ctx, samples)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Інкрементальне оновлення стану злиття активного сканування
+	var streamErr error
+	for _, sample := range samples {
+		if err := s.feedStream(ctx, scan, sample); err != nil && streamErr == nil {
+			streamErr = err
+		}
+	}
+	return streamErr
+}
+
+// newSensorData декодує вимір і перевіряє його метадані
+func (s *SensorFusionService) newSensorData(scanID uuid.UUID, reading SensorReading) (*domain.SensorData, error) {
+	metadata := reading.Metadata
 
 	// Перевірка наявності необхідних полів у метаданих
 	latitude, ok := metadata["latitude"].(float64)
 	if !ok {
-		return errors.New("метадані не містять коректного поля latitude")
+		return nil, errors.New("метадані не містять коректного поля latitude")
 	}
 
 	longitude, ok := metadata["longitude"].(float64)
 	if !ok {
-		return errors.New("метадані не містять коректного поля longitude")
+		return nil, errors.New("метадані не містять коректного поля longitude")
 	}
 
 	altitude, ok := metadata["altitude"].(float64)
 	if !ok {
-		return errors.New("метадані не містять коректного поля altitude")
+		return nil, errors.New("метадані не містять коректного поля altitude")
 	}
 
 	qualityIndicators, ok := metadata["quality"]
 	if !ok {
-		return errors.New("метадані не містять поля quality")
+		return nil, errors.New("метадані не містять поля quality")
 	}
 
 	// Обробка даних в залежності від типу сенсора
-	processedData, err := s.processSensorTypeData(sensorType, data)
+	processedData, err := s.processSensorTypeData(reading.SensorType, reading.Data)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Час виміру на пристрої точніший за час отримання пакета, якщо пристрій його надсилає
+	timestamp := time.Now()
+	if deviceTime, ok := metadata["timestamp"].(time.Time); ok {
+		timestamp = deviceTime
 	}
 
 	// Створення запису з даними сенсора
-	sensorData := &domain.SensorData{
+	return &domain.SensorData{
 		ID:                uuid.New(),
 		ScanID:            scanID,
-		SensorType:        sensorType,
-		Timestamp:         time.Now(),
+		SensorType:        reading.SensorType,
+		Timestamp:         timestamp,
 		Latitude:          latitude,
 		Longitude:         longitude,
 		Altitude:          altitude,
 		Data:              processedData,
 		QualityIndicators: qualityIndicators,
+	}, nil
+}
+
+// fusionSample перетворює збережений вимір на вхідні дані детектора
+func fusionSample(data *domain.SensorData) fusion.Sample {
+	return fusion.Sample{
+		SensorType: data.SensorType,
+		Timestamp:  data.Timestamp,
+		Latitude:   data.Latitude,
+		Longitude:  data.Longitude,
+		Altitude:   data.Altitude,
+		Data:       data.Data,
+		Quality:    data.QualityIndicators,
 	}
+}
 
-	// Збереження даних
-	return s.sensorDataRepo.SaveBatch(ctx, []*domain.SensorData{sensorData})
+// feedStream передає вимір у потоковий стан злиття сканування і сповіщає
+// обробники, якщо з'явилася нова попередня детекція
+func (s *SensorFusionService) feedStream(ctx context.Context, scan *domain.Scan, sample *domain.SensorData) error {
+	state, err := s.scanStream(ctx, scan)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrStreamingFusion, err)
+	}
+
+	state.mu.Lock()
+	detection, ok := state.stream.Add(fusionSample(sample))
+	var coverage *fusion.Coverage
+	now := time.Now()
+	state.lastSample = now
+	if now.Sub(state.coveragePushed) >= coverageInterval {
+		value := state.stream.Coverage()
+		coverage = &value
+		state.coveragePushed = now
+	}
+	state.mu.Unlock()
+
+	if coverage != nil {
+		s.eventBus.Publish(ports.Event{
+			Type:      ports.EventScanCoverage,
+			MissionID: state.missionID,
+			ScanID:    scan.ID,
+			DeviceID:  scan.DeviceID,
+			Payload:   *coverage,
+		})
+	}
+
+	if !ok {
+		return nil
+	}
+
+	provisional := ProvisionalDetection{
+		ScanID:       scan.ID,
+		MissionID:    state.missionID,
+		Latitude:     detection.Latitude,
+		Longitude:    detection.Longitude,
+		ObjectType:   detection.ObjectType,
+		Confidence:   detection.Confidence,
+		Belief:       detection.Belief,
+		Plausibility: detection.Plausibility,
+		DangerLevel:  detection.DangerLevel,
+		DetectedAt:   time.Now(),
+	}
+
+	s.streamsMu.Lock()
+	handlers := append([]ProvisionalDetectionHandler(nil), s.provisional...)
+	s.streamsMu.Unlock()
+
+	for _, handler := range handlers {
+		handler(provisional)
+	}
+
+	s.eventBus.Publish(ports.Event{
+		Type:      ports.EventDetectionProvisional,
+		MissionID: state.missionID,
+		ScanID:    scan.ID,
+		DeviceID:  scan.DeviceID,
+		Payload:   provisional,
+	})
+
+	return nil
+}
+
+// scanStream повертає потоковий стан сканування, створюючи його з профілем місії
+func (s *SensorFusionService) scanStream(ctx context.Context, scan *domain.Scan) (*scanStream, error) {
+	s.streamsMu.Lock()
+	state, ok := s.streams[scan.ID]
+	s.streamsMu.Unlock()
+	if ok {
+		return state, nil
+	}
+
+	detector, err := s.profileService.DetectorForMission(ctx, scan.MissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+
+	// Паралельний виклик міг уже створити стан
+	if state, ok := s.streams[scan.ID]; ok {
+		return state, nil
+	}
+
+	s.evictIdleStreamsLocked(time.Now().Add(-streamIdleTimeout))
+
+	state = &scanStream{missionID: scan.MissionID, stream: detector.NewStream(), lastSample: time.Now()}
+	s.streams[scan.ID] = state
+	return state, nil
+}
+
+// evictIdleStreamsLocked звільняє потоковий стан сканувань, виміри яких давно
+// не надходили (наприклад, пристрій зник, не завершивши сканування). Остаточні
+// детекції від цього не залежать: їх дає пакетне злиття збережених даних.
+// Викликається під s.streamsMu.
+func (s *SensorFusionService) evictIdleStreamsLocked(since time.Time) {
+	for scanID, state := range s.streams {
+		state.mu.Lock()
+		idle := state.lastSample.Before(since)
+		state.mu.Unlock()
+
+		if idle {
+			delete(s.streams, scanID)
+		}
+	}
 }
 
 // FuseAndDetect об'єднує дані з різних сенсорів та виявляє потенційні міни
 func (s *SensorFusionService) FuseAndDetect(ctx context.Context, scanID uuid.UUID, regionID string) ([]*domain.DetectedObject, error) {
 	// Отримання даних з різних сенсорів для даної області сканування
-	lidarData, err := s.sensorDataRepo.FindBySensorType(ctx, scanID, "lidar")
-	if err != nil {
-		return nil, err
+	var samples []*domain.SensorData
+	for _, sensorType := range fusion.SensorTypes() {
+		data, err := s.sensorDataRepo.FindBySensorType(ctx, scanID, sensorType)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, data...)
 	}
 
-	magneticData, err := s.sensorDataRepo.FindBySensorType(ctx, scanID, "magnetic")
+	// Сканування без даних сенсорів не містить виявлених об'єктів
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	// Детектор налаштовується профілем місії, до якої належить сканування
+	scan, err := s.scanRepo.FindByID(ctx, scanID)
 	if err != nil {
 		return nil, err
 	}
 
-	acousticData, err := s.sensorDataRepo.FindBySensorType(ctx, scanID, "acoustic")
+	detector, err := s.profileService.DetectorForMission(ctx, scan.MissionID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Використання алгоритму злиття даних для виявлення потенційних мін
-	detector := fusion.NewDetector()
-	detections, err := detector.FuseAndDetect(lidarData, magneticData, acousticData)
+	input := make([]fusion.Sample, len(samples))
+	for i, sample := range samples {
+		input[i] = fusionSample(sample)
+	}
+	detections, err := detector.FuseAndDetect(input)
 	if err != nil {
 		return nil, err
 	}
 
-	// Перетворення результатів детекції в доменні об'єкти
+	// Кожна детекція або оновлює відомий об'єкт місії поблизу, або створює новий
+	association := newDetectionAssociation(scan, detector.AssociationRadius())
 	var detectedObjects []*domain.DetectedObject
 	for _, detection := range detections {
-		detectedObject := &domain.DetectedObject{
+		detectedObject, err := s.recordDetection(ctx, association, scan, detection)
+		if err != nil {
+			return nil, err
+		}
+
+		detectedObjects = append(detectedObjects, detectedObject)
+	}
+
+	return detectedObjects, nil
+}
+
+// detectionAssociation зберігає стан об'єднання детекцій одного сканування
+type detectionAssociation struct {
+	scanID    uuid.UUID
+	missionID uuid.UUID
+	radius    float64
+	// scanMissions кешує місії сканувань об'єктів-кандидатів
+	scanMissions map[uuid.UUID]uuid.UUID
+	// touched об'єкти, вже оновлені або створені цим скануванням
+	touched map[uuid.UUID]bool
+}
+
+func newDetectionAssociation(scan *domain.Scan, radius float64) *detectionAssociation {
+	return &detectionAssociation{
+		scanID:       scan.ID,
+		missionID:    scan.MissionID,
+		radius:       radius,
+		scanMissions: make(map[uuid.UUID]uuid.UUID),
+		touched:      make(map[uuid.UUID]bool),
+	}
+}
+
+// recordDetection об'єднує детекцію з найближчим відомим об'єктом місії в радіусі
+// асоціації або зберігає її як новий об'єкт. В обох випадках додається спостереження.
+func (s *SensorFusionService) recordDetection(ctx context.Context, association *detectionAssociation, scan *domain.Scan, detection fusion.Detection) (*domain.DetectedObject, error) {
+	existing, err := s.findAssociatedObject(ctx, association, detection)
+	if err != nil {
+		return nil, err
+	}
+
+	// Повторне злиття того самого сканування не додає спостереження вдруге
+	if existing != nil && recordsScan(existing, scan.ID) {
+		association.touched[existing.ID] = true
+		return existing, nil
+	}
+
+	var detectedObject *domain.DetectedObject
+	eventType := ports.EventDetectionCreated
+	if existing != nil {
+		eventType = ports.EventDetectionUpdated
+		mergeDetection(existing, scan.ID, detection)
+		if err := s.detectedObjectRepo.Update(ctx, existing); err != nil {
+			return nil, err
+		}
+		detectedObject = existing
+	} else {
+		detectedObject = &domain.DetectedObject{
 			ID:                 uuid.New(),
-			ScanID:             scanID,
+			ScanID:             scan.ID,
+			ScanIDs:            []uuid.UUID{scan.ID},
 			Latitude:           detection.Latitude,
 			Longitude:          detection.Longitude,
 			Depth:              detection.Depth,
 			ObjectType:         detection.ObjectType,
 			Confidence:         detection.Confidence,
+			Belief:             detection.Belief,
+			Plausibility:       detection.Plausibility,
 			DangerLevel:        detection.DangerLevel,
 			VerificationStatus: domain.VerificationStatusUnverified,
+			Footprint:          footprintGeoJSON(detection.Footprint),
 		}
 
 		// Збереження виявленого об'єкта
 		if err := s.detectedObjectRepo.Save(ctx, detectedObject); err != nil {
 			return nil, err
 		}
+	}
+	association.touched[detectedObject.ID] = true
 
-		detectedObjects = append(detectedObjects, detectedObject)
+	sighting := &domain.Sighting{
+		ID:               uuid.New(),
+		DetectedObjectID: detectedObject.ID,
+		ScanID:           scan.ID,
+		Latitude:         detection.Latitude,
+		Longitude:        detection.Longitude,
+		ObjectType:       detection.ObjectType,
+		Confidence:       detection.Confidence,
+		Belief:           detection.Belief,
+		Plausibility:     detection.Plausibility,
+		SeenAt:           time.Now(),
+	}
+	if err := s.sightingRepo.Save(ctx, sighting); err != nil {
+		return nil, err
 	}
 
-	return detectedObjects, nil
+	s.eventBus.Publish(ports.Event{
+		Type:      eventType,
+		MissionID: scan.MissionID,
+		ScanID:    scan.ID,
+		DeviceID:  scan.DeviceID,
+		Payload:   *detectedObject,
+	})
+
+	return detectedObject, nil
 }
 
-// processSensorTypeData обробляє дані конкретного типу сенсора
+// findAssociatedObject шукає найближчий об'єкт тієї ж місії в радіусі асоціації.
+// Об'єкти, вже оновлені цим скануванням, не розглядаються, щоб два кластери
+// одного сканування не злилися в один об'єкт. Відхилені саперами об'єкти
+// не поглинають нові детекції: інакше небезпека поруч з ними залишилася б
+// прихованою від операторів. Виняток - об'єкт, що вже містить спостереження
+// цього сканування, тобто повторне злиття тих самих даних.
+func (s *SensorFusionService) findAssociatedObject(ctx context.Context, association *detectionAssociation, detection fusion.Detection) (*domain.DetectedObject, error) {
+	if association.radius <= 0 {
+		return nil, nil
+	}
+
+	candidates, err := s.detectedObjectRepo.FindByCoordinates(ctx, detection.Latitude, detection.Longitude, association.radius)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		nearest  *domain.DetectedObject
+		distance float64
+	)
+	for _, candidate := range candidates {
+		if association.touched[candidate.ID] {
+			continue
+		}
+		if candidate.VerificationStatus == domain.VerificationStatusDismissed && !recordsScan(candidate, association.scanID) {
+			continue
+		}
+
+		missionID, ok := association.scanMissions[candidate.ScanID]
+		if !ok {
+			scan, err := s.scanRepo.FindByID(ctx, candidate.ScanID)
+			if err != nil {
+				return nil, err
+			}
+			missionID = scan.MissionID
+			association.scanMissions[candidate.ScanID] = missionID
+		}
+		if missionID != association.missionID {
+			continue
+		}
+
+		d := geo.Distance(detection.Latitude, detection.Longitude, candidate.Latitude, candidate.Longitude)
+		if nearest == nil || d < distance {
+			nearest, distance = candidate, d
+		}
+	}
+
+	return nearest, nil
+}
+
+// mergeDetection оновлює відомий об'єкт новим спостереженням сканування scanID.
+// Впевненість комбінується як імовірність того, що хоча б одне спостереження
+// правильне, тож вона ніколи не знижується; тому кожне сканування зливається
+// з об'єктом не більше одного разу. Підтверджені саперами об'єкти
+// зберігають тип, глибину, положення та статус, встановлені на місцевості;
+// для решти атрибути оновлюються, якщо нове спостереження впевненіше.
+func mergeDetection(obj *domain.DetectedObject, scanID uuid.UUID, detection fusion.Detection) {
+	previousConfidence := obj.Confidence
+	obj.ScanIDs = append(obj.ScanIDs, scanID)
+
+	obj.Confidence = 1 - (1-obj.Confidence)*(1-detection.Confidence)
+	obj.Belief = math.Max(obj.Belief, detection.Belief)
+	obj.Plausibility = math.Max(math.Max(obj.Plausibility, detection.Plausibility), obj.Confidence)
+
+	if obj.VerificationStatus == domain.VerificationStatusConfirmed {
+		return
+	}
+
+	// Положення уточнюється середнім, зваженим впевненістю спостережень
+	weight := previousConfidence + detection.Confidence
+	if weight > 0 {
+		obj.Latitude = (obj.Latitude*previousConfidence + detection.Latitude*detection.Confidence) / weight
+		obj.Longitude = (obj.Longitude*previousConfidence + detection.Longitude*detection.Confidence) / weight
+	}
+
+	if detection.DangerLevel > obj.DangerLevel {
+		obj.DangerLevel = detection.DangerLevel
+	}
+
+	if detection.Confidence > previousConfidence {
+		obj.ObjectType = detection.ObjectType
+		obj.Depth = detection.Depth
+		if footprint := footprintGeoJSON(detection.Footprint); footprint != nil {
+			obj.Footprint = footprint
+		}
+	}
+}
+
+// recordsScan перевіряє, чи містить об'єкт спостереження сканування
+func recordsScan(obj *domain.DetectedObject, scanID uuid.UUID) bool {
+	if obj.ScanID == scanID {
+		return true
+	}
+	for _, id := range obj.ScanIDs {
+		if id == scanID {
+			return true
+		}
+	}
+	return false
+}
+
+// processSensorTypeData обробляє дані конкретного типу сенсора декодером з реєстру fusion
 func (s *SensorFusionService) processSensorTypeData(sensorType string, data []byte) (interface{}, error) {
-	switch sensorType {
-	case "lidar":
-		return fusion.ProcessLidarData(data)
-	case "magnetic":
-		return fusion.ProcessMagneticData(data)
-	case "acoustic":
-		return fusion.ProcessAcousticData(data)
-	default:
-		return nil, errors.New("unsupported sensor type")
+	return fusion.DecodeSensorData(sensorType, data)
+}
+
+// footprintGeoJSON перетворює контур детекції на GeoJSON Polygon
+func footprintGeoJSON(ring [][]float64) domain.GeoJSON {
+	if len(ring) == 0 {
+		return nil
+	}
+
+	return domain.GeoJSON{
+		"type":        "Polygon",
+		"coordinates": [][][]float64{ring},
 	}
 }
@@ -0,0 +1,67 @@
+package application
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/infrastructure/events"
+	"mine-detection-system/internal/infrastructure/memory"
+	"mine-detection-system/pkg/fusion"
+	"testing"
+	"time"
+)
+
+func TestRescanMergesIntoExistingObject(t *testing.T) {
+	ctx := context.Background()
+	scans := memory.NewScanRepository()
+	missions := memory.NewMissionRepository()
+	objects := memory.NewDetectedObjectRepository()
+	sightings := memory.NewSightingRepository()
+	profiles := NewDetectionProfileService(memory.NewDetectionProfileRepository(), missions, fusion.DefaultClassifier())
+	service := NewSensorFusionService(memory.NewSensorDataRepository(), objects, scans, sightings, profiles, events.NewBus())
+
+	missionID := uuid.New()
+	first := &domain.Scan{ID: uuid.New(), MissionID: missionID, DeviceID: uuid.New(), Status: domain.ScanStatusCompleted, StartTime: time.Now()}
+	second := &domain.Scan{ID: uuid.New(), MissionID: missionID, DeviceID: uuid.New(), Status: domain.ScanStatusCompleted, StartTime: time.Now()}
+	for _, scan := range []*domain.Scan{first, second} {
+		if err := scans.Save(ctx, scan); err != nil {
+			t.Fatalf("save scan: %v", err)
+		}
+	}
+
+	detection := fusion.Detection{Latitude: 50.4501, Longitude: 30.5234, ObjectType: "AT_MINE", Confidence: 0.6, Belief: 0.5, Plausibility: 0.7, DangerLevel: 4}
+	original, err := service.recordDetection(ctx, newDetectionAssociation(first, 2), first, detection)
+	if err != nil {
+		t.Fatalf("first scan: %v", err)
+	}
+
+	// Повторне сканування за метр від об'єкта зливається з ним, а не створює новий
+	detection.Latitude += 0.00001
+	merged, err := service.recordDetection(ctx, newDetectionAssociation(second, 2), second, detection)
+	if err != nil {
+		t.Fatalf("second scan: %v", err)
+	}
+	if merged.ID != original.ID || merged.ScanID != first.ID {
+		t.Fatalf("second scan produced object %s of scan %s, want merge into %s of scan %s", merged.ID, merged.ScanID, original.ID, first.ID)
+	}
+
+	detections := NewDetectionService(objects, scans, sightings)
+	for _, scan := range []*domain.Scan{first, second} {
+		listed, err := detections.ListScanDetections(ctx, scan.ID)
+		if err != nil {
+			t.Fatalf("ListScanDetections: %v", err)
+		}
+		if len(listed) != 1 || listed[0].ID != original.ID {
+			t.Errorf("scan %s lists %d objects, want merged object %s", scan.ID, len(listed), original.ID)
+		}
+	}
+
+	// Об'єкт, який спостерігали обидва сканування місії, рахується один раз
+	summary, err := NewVerificationService(objects, memory.NewVerificationReportRepository(), scans).GetMissionClearance(ctx, missionID)
+	if err != nil {
+		t.Fatalf("GetMissionClearance: %v", err)
+	}
+	if summary.Total != 1 || summary.Unverified != 1 || summary.Cleared {
+		t.Errorf("clearance = %+v, want one unverified object", summary)
+	}
+}
@@ -0,0 +1,223 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"sync"
+	"time"
+)
+
+// ErrInvalidReport повертається, якщо звіт про верифікацію не пройшов валідацію
+var ErrInvalidReport = errors.New("invalid verification report")
+
+// VerificationInput містить дані звіту саперної групи
+type VerificationInput struct {
+	Outcome          domain.VerificationStatus
+	ActualObjectType string
+	MeasuredDepth    *float64
+	TeamID           string
+	Note             string
+}
+
+// ClearanceSummary описує фактичний стан розмінування місії
+type ClearanceSummary struct {
+	MissionID  uuid.UUID `json:"mission_id"`
+	Total      int       `json:"total"`
+	Unverified int       `json:"unverified"`
+	Confirmed  int       `json:"confirmed"`
+	Dismissed  int       `json:"dismissed"`
+	// ScansCompleted кількість успішно завершених сканувань місії
+	ScansCompleted int `json:"scans_completed"`
+	// ScansInProgress кількість сканувань, які ще тривають і можуть виявити нові об'єкти
+	ScansInProgress int `json:"scans_in_progress"`
+	// Cleared означає, що територію проскановано до кінця, а кожен виявлений
+	// об'єкт перевірено на місцевості
+	Cleared bool `json:"cleared"`
+}
+
+// VerificationService відповідає за верифікацію виявлених об'єктів саперами
+type VerificationService struct {
+	detectedObjectRepo ports.DetectedObjectRepository
+	reportRepo         ports.VerificationReportRepository
+	scanRepo           ports.ScanRepository
+
+	// mu впорядковує подання звітів
+	mu sync.Mutex
+}
+
+// NewVerificationService створює новий екземпляр VerificationService
+func NewVerificationService(
+	detectedObjectRepo ports.DetectedObjectRepository,
+	reportRepo ports.VerificationReportRepository,
+	scanRepo ports.ScanRepository,
+) *VerificationService {
+	return &VerificationService{
+		detectedObjectRepo: detectedObjectRepo,
+		reportRepo:         reportRepo,
+		scanRepo:           scanRepo,
+	}
+}
+
+// SubmitReport зберігає звіт польової групи та переводить об'єкт у стан confirmed або dismissed.
+// Повторні звіти дозволені: останній визначає поточний стан, а вся історія зберігається.
+func (s *VerificationService) SubmitReport(ctx context.Context, objectID uuid.UUID, input VerificationInput) (*domain.VerificationReport, *domain.DetectedObject, error) {
+	if err := validateVerificationInput(input); err != nil {
+		return nil, nil, err
+	}
+
+	// Звіти обробляються по одному, щоб відкат невдалого звіту не затер стан, встановлений іншим
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj, err := s.detectedObjectRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	previous := verificationState{
+		status:     obj.VerificationStatus,
+		objectType: obj.ObjectType,
+		depth:      obj.Depth,
+	}
+
+	report := &domain.VerificationReport{
+		ID:               uuid.New(),
+		DetectedObjectID: objectID,
+		Outcome:          input.Outcome,
+		ActualObjectType: input.ActualObjectType,
+		MeasuredDepth:    input.MeasuredDepth,
+		TeamID:           input.TeamID,
+		Note:             input.Note,
+		ReportedAt:       time.Now(),
+	}
+
+	// Дані з місцевості мають пріоритет над оцінкою алгоритму злиття
+	obj.VerificationStatus = input.Outcome
+	if input.Outcome == domain.VerificationStatusConfirmed {
+		obj.ObjectType = input.ActualObjectType
+		if input.MeasuredDepth != nil {
+			obj.Depth = *input.MeasuredDepth
+		}
+	}
+
+	// Спершу оновлюється об'єкт: якщо звіт не вдасться зберегти, зміну можна відкотити,
+	// а звіт без відповідного стану об'єкта спотворив би історію перевірок
+	if err := s.detectedObjectRepo.Update(ctx, obj); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.reportRepo.Save(ctx, report); err != nil {
+		if restoreErr := s.restoreVerification(ctx, objectID, previous); restoreErr != nil {
+			return nil, nil, fmt.Errorf("save report: %w (restoring object state failed: %v)", err, restoreErr)
+		}
+		return nil, nil, err
+	}
+
+	return report, obj, nil
+}
+
+// verificationState поля об'єкта, які змінює звіт про верифікацію
+type verificationState struct {
+	status     domain.VerificationStatus
+	objectType string
+	depth      float64
+}
+
+// restoreVerification повертає об'єкту стан верифікації, який був до невдалого звіту.
+// Об'єкт перечитується, щоб не затерти інші поля, змінені тим часом злиттям даних.
+func (s *VerificationService) restoreVerification(ctx context.Context, objectID uuid.UUID, previous verificationState) error {
+	obj, err := s.detectedObjectRepo.FindByID(ctx, objectID)
+	if err != nil {
+		return err
+	}
+
+	obj.VerificationStatus = previous.status
+	obj.ObjectType = previous.objectType
+	obj.Depth = previous.depth
+
+	return s.detectedObjectRepo.Update(ctx, obj)
+}
+
+// ListReports повертає історію звітів для виявленого об'єкта
+func (s *VerificationService) ListReports(ctx context.Context, objectID uuid.UUID) ([]*domain.VerificationReport, error) {
+	if _, err := s.detectedObjectRepo.FindByID(ctx, objectID); err != nil {
+		return nil, err
+	}
+
+	return s.reportRepo.FindByDetectedObjectID(ctx, objectID)
+}
+
+// GetMissionClearance підраховує стан верифікації всіх об'єктів, виявлених у місії
+func (s *VerificationService) GetMissionClearance(ctx context.Context, missionID uuid.UUID) (*ClearanceSummary, error) {
+	scans, err := s.scanRepo.FindByMissionID(ctx, missionID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ClearanceSummary{MissionID: missionID}
+	// Об'єкт, підтверджений кількома скануваннями місії, рахується один раз
+	counted := make(map[uuid.UUID]bool)
+	for _, scan := range scans {
+		switch scan.Status {
+		case domain.ScanStatusCompleted:
+			summary.ScansCompleted++
+		case domain.ScanStatusInProgress:
+			summary.ScansInProgress++
+		}
+
+		objects, err := s.detectedObjectRepo.FindByScanID(ctx, scan.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objects {
+			if counted[obj.ID] {
+				continue
+			}
+			counted[obj.ID] = true
+
+			summary.Total++
+			switch obj.VerificationStatus {
+			case domain.VerificationStatusConfirmed:
+				summary.Confirmed++
+			case domain.VerificationStatusDismissed:
+				summary.Dismissed++
+			default:
+				summary.Unverified++
+			}
+		}
+	}
+
+	// Місія без жодного завершеного сканування ще нічого не перевірила, а активне
+	// сканування може додати нові об'єкти, тож жодну з них не можна вважати розмінованою
+	summary.Cleared = summary.ScansCompleted > 0 && summary.ScansInProgress == 0 && summary.Unverified == 0
+
+	return summary, nil
+}
+
+// validateVerificationInput перевіряє обов'язкові поля звіту
+func validateVerificationInput(input VerificationInput) error {
+	switch input.Outcome {
+	case domain.VerificationStatusConfirmed:
+		if input.ActualObjectType == "" {
+			return fmt.Errorf("%w: actual object type is required for confirmed objects", ErrInvalidReport)
+		}
+	case domain.VerificationStatusDismissed:
+	default:
+		return fmt.Errorf("%w: outcome must be %s or %s", ErrInvalidReport,
+			domain.VerificationStatusConfirmed, domain.VerificationStatusDismissed)
+	}
+
+	if input.TeamID == "" {
+		return fmt.Errorf("%w: team ID is required", ErrInvalidReport)
+	}
+
+	if input.MeasuredDepth != nil && *input.MeasuredDepth < 0 {
+		return fmt.Errorf("%w: measured depth must not be negative", ErrInvalidReport)
+	}
+
+	return nil
+}
@@ -67,6 +67,28 @@ type Scan struct {
 	ScanType  string      `json:"scan_type"`
 	Status    ScanStatus  `json:"status"`
 	Metadata  interface{} `json:"metadata"`
+	// PacketStats статистика доставки пакетів з порядковими номерами; nil, якщо пристрій їх не надсилав
+	PacketStats *PacketStats `json:"packet_stats,omitempty"`
+}
+
+// PacketStats описує повноту бінарних даних сканування за порядковими номерами пакетів
+type PacketStats struct {
+	// Received кількість унікальних прийнятих пакетів
+	Received int64 `json:"received"`
+	// Duplicates кількість повторних пакетів, які було відкинуто
+	Duplicates int64 `json:"duplicates"`
+	// Reordered кількість пакетів, що надійшли після пакетів з більшими номерами
+	Reordered int64 `json:"reordered"`
+	// Gaps кількість розривів послідовності
+	Gaps int64 `json:"gaps"`
+	// Missing кількість пакетів, які так і не надійшли
+	Missing int64 `json:"missing"`
+	// Resets кількість скидань лічильника пристрою (наприклад, після перезавантаження)
+	Resets        int64  `json:"resets"`
+	FirstSequence uint32 `json:"first_sequence"`
+	LastSequence  uint32 `json:"last_sequence"`
+	// Completeness частка отриманих пакетів серед очікуваних
+	Completeness float64 `json:"completeness"`
 }
 
 // SensorData представляє агреговані дані з сенсорів
@@ -82,18 +104,116 @@ type SensorData struct {
 	QualityIndicators interface{} `json:"quality_indicators"`
 }
 
+// SensorPacket квитанція пакета з порядковим номером, виміри якого збережено.
+// Квитанції зберігаються разом з вимірами, тож повторна передача пакета
+// розпізнається навіть після перезапуску сервера.
+type SensorPacket struct {
+	ScanID uuid.UUID `json:"scan_id"`
+	// Epoch номер нумерації пакетів сканування; збільшується, коли пристрій
+	// починає нумерацію заново, наприклад після перезавантаження
+	Epoch    int    `json:"epoch"`
+	Sequence uint32 `json:"sequence"`
+	// Digest контрольна сума вмісту пакета: той самий номер з іншим вмістом
+	// означає новий пакет, а не повторну передачу
+	Digest     string    `json:"digest"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
 // DetectedObject представляє потенційну міну
 type DetectedObject struct {
-	ID                 uuid.UUID          `json:"id"`
-	ScanID             uuid.UUID          `json:"scan_id"`
+	ID uuid.UUID `json:"id"`
+	// ScanID сканування, яке першим виявило об'єкт
+	ScanID uuid.UUID `json:"scan_id"`
+	// ScanIDs усі сканування, спостереження яких увійшли в об'єкт, у порядку надходження
+	ScanIDs            []uuid.UUID        `json:"scan_ids"`
 	Latitude           float64            `json:"latitude"`
 	Longitude          float64            `json:"longitude"`
 	Depth              float64            `json:"depth"`
 	ObjectType         string             `json:"object_type"`
 	Confidence         float64            `json:"confidence"`
+	Belief             float64            `json:"belief"`
+	Plausibility       float64            `json:"plausibility"`
 	DangerLevel        int                `json:"danger_level"`
 	VerificationStatus VerificationStatus `json:"verification_status"`
+	// Footprint контур об'єкта як GeoJSON Polygon
+	Footprint GeoJSON `json:"footprint,omitempty"`
 }
 
 // GeoJSON представляє геопросторові дані
 type GeoJSON map[string]interface{}
+
+// VerificationReport представляє звіт саперної групи про перевірку виявленого об'єкта на місцевості
+type VerificationReport struct {
+	ID               uuid.UUID          `json:"id"`
+	DetectedObjectID uuid.UUID          `json:"detected_object_id"`
+	Outcome          VerificationStatus `json:"outcome"`
+	ActualObjectType string             `json:"actual_object_type"`
+	MeasuredDepth    *float64           `json:"measured_depth"`
+	TeamID           string             `json:"team_id"`
+	Note             string             `json:"note"`
+	ReportedAt       time.Time          `json:"reported_at"`
+}
+
+// DetectionProfile представляє іменований набір налаштувань детектора для місії.
+// Config містить налаштування злиття даних у форматі fusion.DetectorConfig.
+type DetectionProfile struct {
+	MissionID uuid.UUID   `json:"mission_id"`
+	Name      string      `json:"name"`
+	Config    interface{} `json:"config"`
+	UpdatedAt *time.Time  `json:"updated_at"`
+}
+
+// Sighting представляє одне спостереження виявленого об'єкта під час сканування
+type Sighting struct {
+	ID               uuid.UUID `json:"id"`
+	DetectedObjectID uuid.UUID `json:"detected_object_id"`
+	ScanID           uuid.UUID `json:"scan_id"`
+	Latitude         float64   `json:"latitude"`
+	Longitude        float64   `json:"longitude"`
+	ObjectType       string    `json:"object_type"`
+	Confidence       float64   `json:"confidence"`
+	Belief           float64   `json:"belief"`
+	Plausibility     float64   `json:"plausibility"`
+	SeenAt           time.Time `json:"seen_at"`
+}
+
+// CommandType тип команди, яку сервер надсилає пристрою
+type CommandType string
+
+// CommandStatus стан доставки та виконання команди
+type CommandStatus string
+
+const (
+	// Типи команд пристроям
+	CommandStartScan     CommandType = "start_scan"
+	CommandStopScan      CommandType = "stop_scan"
+	CommandApplyConfig   CommandType = "apply_config"
+	CommandRecalibrate   CommandType = "recalibrate"
+	CommandSetSampleRate CommandType = "set_sample_rate"
+	CommandReturnToBase  CommandType = "return_to_base"
+
+	// Статуси команд
+	CommandStatusPending      CommandStatus = "pending"
+	CommandStatusSent         CommandStatus = "sent"
+	CommandStatusUndelivered  CommandStatus = "undelivered"
+	CommandStatusAcknowledged CommandStatus = "acknowledged"
+	CommandStatusCompleted    CommandStatus = "completed"
+	CommandStatusRejected     CommandStatus = "rejected"
+	CommandStatusTimedOut     CommandStatus = "timed_out"
+)
+
+// DeviceCommand представляє команду пристрою та стан її доставки
+type DeviceCommand struct {
+	ID          uuid.UUID     `json:"id"`
+	DeviceID    uuid.UUID     `json:"device_id"`
+	Type        CommandType   `json:"type"`
+	Params      interface{}   `json:"params,omitempty"`
+	Status      CommandStatus `json:"status"`
+	Error       string        `json:"error,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	SentAt      *time.Time    `json:"sent_at,omitempty"`
+	AckedAt     *time.Time    `json:"acked_at,omitempty"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+	// ExpiresAt момент, після якого непідтверджена команда вважається простроченою
+	ExpiresAt time.Time `json:"expires_at"`
+}
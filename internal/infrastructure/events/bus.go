@@ -0,0 +1,75 @@
+package events
+
+import (
+	"log"
+	"mine-detection-system/internal/ports"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Bus імплементує ports.EventBus у межах процесу.
+// Кожен підписник має власний буферизований канал; якщо підписник не встигає
+// читати, нові події для нього відкидаються, щоб повільна консоль оператора
+// не гальмувала обробку даних пристроїв.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	events  chan ports.Event
+	dropped atomic.Int64
+}
+
+// NewBus створює новий екземпляр Bus
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish надсилає подію всім підписникам
+func (b *Bus) Publish(event ports.Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			if dropped := sub.dropped.Add(1); dropped == 1 || dropped%100 == 0 {
+				log.Printf("Event bus subscriber is lagging, %d events dropped", dropped)
+			}
+		}
+	}
+}
+
+// Subscribe створює підписку з буфером на buffer подій
+func (b *Bus) Subscribe(buffer int) (<-chan ports.Event, func()) {
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	sub := &subscriber{events: make(chan ports.Event, buffer)}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, sub)
+			b.mu.Unlock()
+			close(sub.events)
+		})
+	}
+
+	return sub.events, cancel
+}
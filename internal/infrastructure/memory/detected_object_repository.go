@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"mine-detection-system/pkg/geo"
+	"sort"
+	"sync"
+)
+
+// DetectedObjectRepository імплементує ports.DetectedObjectRepository у пам'яті
+type DetectedObjectRepository struct {
+	mu      sync.RWMutex
+	objects map[uuid.UUID]domain.DetectedObject
+}
+
+// NewDetectedObjectRepository створює новий екземпляр DetectedObjectRepository
+func NewDetectedObjectRepository() *DetectedObjectRepository {
+	return &DetectedObjectRepository{
+		objects: make(map[uuid.UUID]domain.DetectedObject),
+	}
+}
+
+// Save зберігає новий виявлений об'єкт
+func (r *DetectedObjectRepository) Save(ctx context.Context, obj *domain.DetectedObject) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.objects[obj.ID]; exists {
+		return errors.New("detected object already exists")
+	}
+
+	r.objects[obj.ID] = cloneDetectedObject(obj)
+	return nil
+}
+
+// FindByID шукає виявлений об'єкт за ID
+func (r *DetectedObjectRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.DetectedObject, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	obj, ok := r.objects[id]
+	if !ok {
+		return nil, ports.ErrDetectedObjectNotFound
+	}
+
+	return &obj, nil
+}
+
+// FindByScanID повертає всі об'єкти, які спостерігало сканування, зокрема
+// об'єкти попередніх сканувань, з якими злилися його детекції, за спаданням впевненості
+func (r *DetectedObjectRepository) FindByScanID(ctx context.Context, scanID uuid.UUID) ([]*domain.DetectedObject, error) {
+	objects := r.filter(func(obj *domain.DetectedObject) bool {
+		if obj.ScanID == scanID {
+			return true
+		}
+		for _, id := range obj.ScanIDs {
+			if id == scanID {
+				return true
+			}
+		}
+		return false
+	})
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Confidence > objects[j].Confidence
+	})
+
+	return objects, nil
+}
+
+// FindByCoordinates повертає об'єкти в радіусі radius метрів від точки (lat, lon)
+func (r *DetectedObjectRepository) FindByCoordinates(ctx context.Context, lat, lon float64, radius float64) ([]*domain.DetectedObject, error) {
+	if radius < 0 {
+		return nil, errors.New("radius must not be negative")
+	}
+
+	return r.filter(func(obj *domain.DetectedObject) bool {
+		return geo.Distance(lat, lon, obj.Latitude, obj.Longitude) <= radius
+	}), nil
+}
+
+// Update оновлює інформацію про виявлений об'єкт
+func (r *DetectedObjectRepository) Update(ctx context.Context, obj *domain.DetectedObject) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.objects[obj.ID]; !ok {
+		return ports.ErrDetectedObjectNotFound
+	}
+
+	r.objects[obj.ID] = cloneDetectedObject(obj)
+	return nil
+}
+
+// filter повертає копії об'єктів, що задовольняють умову
+func (r *DetectedObjectRepository) filter(keep func(obj *domain.DetectedObject) bool) []*domain.DetectedObject {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var objects []*domain.DetectedObject
+	for _, obj := range r.objects {
+		obj := obj
+		if keep(&obj) {
+			objects = append(objects, &obj)
+		}
+	}
+
+	return objects
+}
+
+// cloneDetectedObject копіює об'єкт разом зі списком сканувань, щоб
+// подальші зміни викликача не потрапляли у сховище
+func cloneDetectedObject(obj *domain.DetectedObject) domain.DetectedObject {
+	clone := *obj
+	clone.ScanIDs = append([]uuid.UUID(nil), obj.ScanIDs...)
+	return clone
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"sync"
+)
+
+// DetectionProfileRepository імплементує ports.DetectionProfileRepository у пам'яті
+type DetectionProfileRepository struct {
+	mu       sync.RWMutex
+	profiles map[uuid.UUID]domain.DetectionProfile
+}
+
+// NewDetectionProfileRepository створює новий екземпляр DetectionProfileRepository
+func NewDetectionProfileRepository() *DetectionProfileRepository {
+	return &DetectionProfileRepository{
+		profiles: make(map[uuid.UUID]domain.DetectionProfile),
+	}
+}
+
+// Save зберігає профіль місії, замінюючи попередній
+func (r *DetectionProfileRepository) Save(ctx context.Context, profile *domain.DetectionProfile) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.profiles[profile.MissionID] = *profile
+	return nil
+}
+
+// FindByMissionID шукає профіль місії
+func (r *DetectionProfileRepository) FindByMissionID(ctx context.Context, missionID uuid.UUID) (*domain.DetectionProfile, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	profile, ok := r.profiles[missionID]
+	if !ok {
+		return nil, ports.ErrDetectionProfileNotFound
+	}
+
+	return &profile, nil
+}
+
+// Delete видаляє профіль місії
+func (r *DetectionProfileRepository) Delete(ctx context.Context, missionID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.profiles[missionID]; !ok {
+		return ports.ErrDetectionProfileNotFound
+	}
+
+	delete(r.profiles, missionID)
+	return nil
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"sort"
+	"sync"
+)
+
+// DeviceRepository імплементує ports.DeviceRepository у пам'яті
+type DeviceRepository struct {
+	mu      sync.RWMutex
+	devices map[uuid.UUID]domain.Device
+}
+
+// NewDeviceRepository створює новий екземпляр DeviceRepository
+func NewDeviceRepository() *DeviceRepository {
+	return &DeviceRepository{
+		devices: make(map[uuid.UUID]domain.Device),
+	}
+}
+
+// Save зберігає новий пристрій
+func (r *DeviceRepository) Save(ctx context.Context, device *domain.Device) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.devices[device.ID]; exists {
+		return errors.New("device already exists")
+	}
+
+	r.devices[device.ID] = *device
+	return nil
+}
+
+// FindByID шукає пристрій за ID
+func (r *DeviceRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Device, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	device, ok := r.devices[id]
+	if !ok {
+		return nil, errors.New("device not found")
+	}
+
+	return &device, nil
+}
+
+// FindAll шукає пристрої за фільтрами serial_number, device_type та status
+func (r *DeviceRepository) FindAll(ctx context.Context, filters map[string]interface{}) ([]*domain.Device, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var devices []*domain.Device
+	for _, device := range r.devices {
+		if !matches(filters, "serial_number", device.SerialNumber) ||
+			!matches(filters, "device_type", device.DeviceType) ||
+			!matches(filters, "status", device.Status) {
+			continue
+		}
+
+		device := device
+		devices = append(devices, &device)
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].CreatedAt.Before(devices[j].CreatedAt)
+	})
+
+	return devices, nil
+}
+
+// Update оновлює інформацію про пристрій
+func (r *DeviceRepository) Update(ctx context.Context, device *domain.Device) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.devices[device.ID]
+	if !ok {
+		return errors.New("device not found")
+	}
+
+	// Як і в PostgreSQL, дата створення не оновлюється
+	updated := *device
+	updated.CreatedAt = existing.CreatedAt
+	r.devices[device.ID] = updated
+
+	return nil
+}
+
+// Delete видаляє пристрій
+func (r *DeviceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.devices[id]; !ok {
+		return errors.New("device not found")
+	}
+
+	delete(r.devices, id)
+	return nil
+}
@@ -0,0 +1,15 @@
+package memory
+
+import "fmt"
+
+// matches перевіряє, чи відповідає значення поля фільтру.
+// Порівняння виконується за текстовим представленням, як це робить PostgreSQL
+// для параметрів запиту, тому "active" і domain.DeviceStatusActive рівнозначні.
+func matches(filters map[string]interface{}, key string, value interface{}) bool {
+	expected, ok := filters[key]
+	if !ok {
+		return true
+	}
+
+	return fmt.Sprint(expected) == fmt.Sprint(value)
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"sort"
+	"sync"
+)
+
+// MissionRepository імплементує ports.MissionRepository у пам'яті
+type MissionRepository struct {
+	mu       sync.RWMutex
+	missions map[uuid.UUID]domain.Mission
+}
+
+// NewMissionRepository створює новий екземпляр MissionRepository
+func NewMissionRepository() *MissionRepository {
+	return &MissionRepository{
+		missions: make(map[uuid.UUID]domain.Mission),
+	}
+}
+
+// Save зберігає нову місію
+func (r *MissionRepository) Save(ctx context.Context, mission *domain.Mission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.missions[mission.ID]; exists {
+		return errors.New("mission already exists")
+	}
+
+	r.missions[mission.ID] = *mission
+	return nil
+}
+
+// FindByID шукає місію за ID
+func (r *MissionRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Mission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	mission, ok := r.missions[id]
+	if !ok {
+		return nil, ports.ErrMissionNotFound
+	}
+
+	return &mission, nil
+}
+
+// FindAll шукає місії за фільтрами status, priority та name
+func (r *MissionRepository) FindAll(ctx context.Context, filters map[string]interface{}) ([]*domain.Mission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var missions []*domain.Mission
+	for _, mission := range r.missions {
+		if !matches(filters, "status", mission.Status) ||
+			!matches(filters, "priority", mission.Priority) ||
+			!matches(filters, "name", mission.Name) {
+			continue
+		}
+
+		mission := mission
+		missions = append(missions, &mission)
+	}
+
+	// Той самий порядок, що й ORDER BY priority DESC, start_date
+	sort.Slice(missions, func(i, j int) bool {
+		if missions[i].Priority != missions[j].Priority {
+			return missions[i].Priority > missions[j].Priority
+		}
+		return missions[i].StartDate.Before(missions[j].StartDate)
+	})
+
+	return missions, nil
+}
+
+// Update оновлює інформацію про місію
+func (r *MissionRepository) Update(ctx context.Context, mission *domain.Mission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.missions[mission.ID]; !ok {
+		return ports.ErrMissionNotFound
+	}
+
+	r.missions[mission.ID] = *mission
+	return nil
+}
+
+// Delete видаляє місію
+func (r *MissionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.missions[id]; !ok {
+		return ports.ErrMissionNotFound
+	}
+
+	delete(r.missions, id)
+	return nil
+}
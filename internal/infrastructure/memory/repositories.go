@@ -0,0 +1,15 @@
+package memory
+
+import "mine-detection-system/internal/ports"
+
+// Перевірка відповідності інтерфейсам портів на етапі компіляції
+var (
+	_ ports.DeviceRepository             = (*DeviceRepository)(nil)
+	_ ports.MissionRepository            = (*MissionRepository)(nil)
+	_ ports.ScanRepository               = (*ScanRepository)(nil)
+	_ ports.SensorDataRepository         = (*SensorDataRepository)(nil)
+	_ ports.DetectedObjectRepository     = (*DetectedObjectRepository)(nil)
+	_ ports.VerificationReportRepository = (*VerificationReportRepository)(nil)
+	_ ports.DetectionProfileRepository   = (*DetectionProfileRepository)(nil)
+	_ ports.SightingRepository           = (*SightingRepository)(nil)
+)
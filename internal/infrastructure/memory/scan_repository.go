@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"sort"
+	"sync"
+)
+
+// ScanRepository імплементує ports.ScanRepository у пам'яті
+type ScanRepository struct {
+	mu    sync.RWMutex
+	scans map[uuid.UUID]domain.Scan
+}
+
+// NewScanRepository створює новий екземпляр ScanRepository
+func NewScanRepository() *ScanRepository {
+	return &ScanRepository{
+		scans: make(map[uuid.UUID]domain.Scan),
+	}
+}
+
+// Save зберігає нове сканування
+func (r *ScanRepository) Save(ctx context.Context, scan *domain.Scan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.scans[scan.ID]; exists {
+		return errors.New("scan already exists")
+	}
+
+	r.scans[scan.ID] = *scan
+	return nil
+}
+
+// FindByID шукає сканування за ID
+func (r *ScanRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Scan, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scan, ok := r.scans[id]
+	if !ok {
+		return nil, ports.ErrScanNotFound
+	}
+
+	return &scan, nil
+}
+
+// FindByMissionID шукає всі сканування місії
+func (r *ScanRepository) FindByMissionID(ctx context.Context, missionID uuid.UUID) ([]*domain.Scan, error) {
+	return r.filter(func(scan *domain.Scan) bool {
+		return scan.MissionID == missionID
+	}), nil
+}
+
+// FindByDeviceID шукає всі сканування, виконані пристроєм
+func (r *ScanRepository) FindByDeviceID(ctx context.Context, deviceID uuid.UUID) ([]*domain.Scan, error) {
+	return r.filter(func(scan *domain.Scan) bool {
+		return scan.DeviceID == deviceID
+	}), nil
+}
+
+// Update оновлює інформацію про сканування
+func (r *ScanRepository) Update(ctx context.Context, scan *domain.Scan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.scans[scan.ID]; !ok {
+		return ports.ErrScanNotFound
+	}
+
+	r.scans[scan.ID] = *scan
+	return nil
+}
+
+// filter повертає копії сканувань, що задовольняють умову, впорядковані за часом початку
+func (r *ScanRepository) filter(keep func(scan *domain.Scan) bool) []*domain.Scan {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var scans []*domain.Scan
+	for _, scan := range r.scans {
+		scan := scan
+		if keep(&scan) {
+			scans = append(scans, &scan)
+		}
+	}
+
+	sort.Slice(scans, func(i, j int) bool {
+		return scans[i].StartTime.Before(scans[j].StartTime)
+	})
+
+	return scans
+}
@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SensorDataRepository імплементує ports.SensorDataRepository у пам'яті
+type SensorDataRepository struct {
+	mu      sync.RWMutex
+	ids     map[uuid.UUID]struct{}
+	byScan  map[uuid.UUID][]domain.SensorData
+	packets map[packetKey]domain.SensorPacket
+}
+
+// packetKey ключ квитанції пакета
+type packetKey struct {
+	scanID   uuid.UUID
+	epoch    int
+	sequence uint32
+}
+
+// NewSensorDataRepository створює новий екземпляр SensorDataRepository
+func NewSensorDataRepository() *SensorDataRepository {
+	return &SensorDataRepository{
+		ids:     make(map[uuid.UUID]struct{}),
+		byScan:  make(map[uuid.UUID][]domain.SensorData),
+		packets: make(map[packetKey]domain.SensorPacket),
+	}
+}
+
+// SaveBatch зберігає пакет даних сенсорів атомарно: або всі записи, або жодного
+func (r *SensorDataRepository) SaveBatch(ctx context.Context, data []*domain.SensorData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.saveLocked(data)
+}
+
+// SavePacket зберігає квитанцію пакета разом з його вимірами
+func (r *SensorDataRepository) SavePacket(ctx context.Context, packet *domain.SensorPacket, data []*domain.SensorData) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := packetKey{scanID: packet.ScanID, epoch: packet.Epoch, sequence: packet.Sequence}
+	if _, exists := r.packets[key]; exists {
+		return ports.ErrDuplicatePacket
+	}
+
+	if err := r.saveLocked(data); err != nil {
+		return err
+	}
+
+	r.packets[key] = *packet
+	return nil
+}
+
+// FindPacket шукає квитанцію пакета за скануванням, епохою і номером
+func (r *SensorDataRepository) FindPacket(ctx context.Context, scanID uuid.UUID, epoch int, sequence uint32) (*domain.SensorPacket, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	packet, ok := r.packets[packetKey{scanID: scanID, epoch: epoch, sequence: sequence}]
+	if !ok {
+		return nil, errors.New("sensor packet not found")
+	}
+
+	return &packet, nil
+}
+
+// FindPackets повертає квитанції пакетів сканування за зростанням епохи і номера
+func (r *SensorDataRepository) FindPackets(ctx context.Context, scanID uuid.UUID) ([]*domain.SensorPacket, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.SensorPacket
+	for key, packet := range r.packets {
+		if key.scanID == scanID {
+			packet := packet
+			result = append(result, &packet)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Epoch != result[j].Epoch {
+			return result[i].Epoch < result[j].Epoch
+		}
+		return result[i].Sequence < result[j].Sequence
+	})
+
+	return result, nil
+}
+
+// saveLocked зберігає виміри, якщо жоден з них ще не збережений; викликається під r.mu
+func (r *SensorDataRepository) saveLocked(data []*domain.SensorData) error {
+	seen := make(map[uuid.UUID]struct{}, len(data))
+	for _, sample := range data {
+		if _, exists := r.ids[sample.ID]; exists {
+			return errors.New("sensor data already exists")
+		}
+		if _, dup := seen[sample.ID]; dup {
+			return errors.New("sensor data already exists")
+		}
+		seen[sample.ID] = struct{}{}
+	}
+
+	for _, sample := range data {
+		r.ids[sample.ID] = struct{}{}
+		r.byScan[sample.ScanID] = append(r.byScan[sample.ScanID], *sample)
+	}
+
+	return nil
+}
+
+// FindByScanID повертає дані сканування посторінково; limit <= 0 означає без обмеження
+func (r *SensorDataRepository) FindByScanID(ctx context.Context, scanID uuid.UUID, limit, offset int) ([]*domain.SensorData, error) {
+	result := r.filter(scanID, func(*domain.SensorData) bool { return true })
+
+	if offset > 0 {
+		if offset >= len(result) {
+			return nil, nil
+		}
+		result = result[offset:]
+	}
+
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+// FindBySensorType повертає всі дані сканування для заданого типу сенсора
+func (r *SensorDataRepository) FindBySensorType(ctx context.Context, scanID uuid.UUID, sensorType string) ([]*domain.SensorData, error) {
+	return r.filter(scanID, func(sample *domain.SensorData) bool {
+		return sample.SensorType == sensorType
+	}), nil
+}
+
+// FindByTimeRange повертає дані сканування у проміжку часу [start, end]
+func (r *SensorDataRepository) FindByTimeRange(ctx context.Context, scanID uuid.UUID, start, end time.Time) ([]*domain.SensorData, error) {
+	return r.filter(scanID, func(sample *domain.SensorData) bool {
+		return !sample.Timestamp.Before(start) && !sample.Timestamp.After(end)
+	}), nil
+}
+
+// filter повертає копії даних сканування, впорядковані як ORDER BY timestamp, id
+func (r *SensorDataRepository) filter(scanID uuid.UUID, keep func(sample *domain.SensorData) bool) []*domain.SensorData {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.SensorData
+	for _, sample := range r.byScan[scanID] {
+		sample := sample
+		if keep(&sample) {
+			result = append(result, &sample)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Timestamp.Equal(result[j].Timestamp) {
+			return result[i].Timestamp.Before(result[j].Timestamp)
+		}
+		return bytes.Compare(result[i].ID[:], result[j].ID[:]) < 0
+	})
+
+	return result
+}
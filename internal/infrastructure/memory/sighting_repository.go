@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"sort"
+	"sync"
+)
+
+// SightingRepository імплементує ports.SightingRepository у пам'яті
+type SightingRepository struct {
+	mu       sync.RWMutex
+	ids      map[uuid.UUID]struct{}
+	byObject map[uuid.UUID][]domain.Sighting
+}
+
+// NewSightingRepository створює новий екземпляр SightingRepository
+func NewSightingRepository() *SightingRepository {
+	return &SightingRepository{
+		ids:      make(map[uuid.UUID]struct{}),
+		byObject: make(map[uuid.UUID][]domain.Sighting),
+	}
+}
+
+// Save зберігає нове спостереження
+func (r *SightingRepository) Save(ctx context.Context, sighting *domain.Sighting) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.ids[sighting.ID]; exists {
+		return errors.New("sighting already exists")
+	}
+
+	r.ids[sighting.ID] = struct{}{}
+	r.byObject[sighting.DetectedObjectID] = append(r.byObject[sighting.DetectedObjectID], *sighting)
+
+	return nil
+}
+
+// FindByDetectedObjectID повертає історію спостережень об'єкта в хронологічному порядку
+func (r *SightingRepository) FindByDetectedObjectID(ctx context.Context, objectID uuid.UUID) ([]*domain.Sighting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sightings []*domain.Sighting
+	for _, sighting := range r.byObject[objectID] {
+		sighting := sighting
+		sightings = append(sightings, &sighting)
+	}
+
+	sort.SliceStable(sightings, func(i, j int) bool {
+		return sightings[i].SeenAt.Before(sightings[j].SeenAt)
+	})
+
+	return sightings, nil
+}
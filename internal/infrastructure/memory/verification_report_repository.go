@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"sort"
+	"sync"
+)
+
+// VerificationReportRepository імплементує ports.VerificationReportRepository у пам'яті
+type VerificationReportRepository struct {
+	mu       sync.RWMutex
+	ids      map[uuid.UUID]struct{}
+	byObject map[uuid.UUID][]domain.VerificationReport
+}
+
+// NewVerificationReportRepository створює новий екземпляр VerificationReportRepository
+func NewVerificationReportRepository() *VerificationReportRepository {
+	return &VerificationReportRepository{
+		ids:      make(map[uuid.UUID]struct{}),
+		byObject: make(map[uuid.UUID][]domain.VerificationReport),
+	}
+}
+
+// Save зберігає новий звіт про верифікацію
+func (r *VerificationReportRepository) Save(ctx context.Context, report *domain.VerificationReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.ids[report.ID]; exists {
+		return errors.New("verification report already exists")
+	}
+
+	r.ids[report.ID] = struct{}{}
+	r.byObject[report.DetectedObjectID] = append(r.byObject[report.DetectedObjectID], *report)
+
+	return nil
+}
+
+// FindByDetectedObjectID повертає історію звітів для об'єкта в хронологічному порядку
+func (r *VerificationReportRepository) FindByDetectedObjectID(ctx context.Context, objectID uuid.UUID) ([]*domain.VerificationReport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var reports []*domain.VerificationReport
+	for _, report := range r.byObject[objectID] {
+		report := report
+		reports = append(reports, &report)
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].ReportedAt.Before(reports[j].ReportedAt)
+	})
+
+	return reports, nil
+}
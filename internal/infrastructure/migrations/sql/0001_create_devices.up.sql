@@ -0,0 +1,13 @@
+-- Розгортання, створені до появи міграцій, уже мають таблицю devices, створену вручну:
+-- базова міграція приймає її як є замість того, щоб падати на повторному створенні
+CREATE TABLE IF NOT EXISTS devices (
+    id                 UUID PRIMARY KEY,
+    device_type        TEXT        NOT NULL,
+    serial_number      TEXT        NOT NULL UNIQUE,
+    config_json        JSONB,
+    status             TEXT        NOT NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_connection_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_devices_status ON devices (status);
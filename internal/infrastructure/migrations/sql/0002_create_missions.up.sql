@@ -0,0 +1,12 @@
+CREATE TABLE missions (
+    id              UUID PRIMARY KEY,
+    name            TEXT        NOT NULL,
+    description     TEXT        NOT NULL DEFAULT '',
+    boundaries_json JSONB,
+    status          TEXT        NOT NULL,
+    start_date      TIMESTAMPTZ NOT NULL,
+    end_date        TIMESTAMPTZ,
+    priority        INTEGER     NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_missions_status ON missions (status);
@@ -0,0 +1,13 @@
+CREATE TABLE scans (
+    id            UUID PRIMARY KEY,
+    mission_id    UUID        NOT NULL REFERENCES missions (id),
+    device_id     UUID        NOT NULL REFERENCES devices (id),
+    start_time    TIMESTAMPTZ NOT NULL,
+    end_time      TIMESTAMPTZ,
+    scan_type     TEXT        NOT NULL,
+    status        TEXT        NOT NULL,
+    metadata_json JSONB
+);
+
+CREATE INDEX idx_scans_mission_id ON scans (mission_id, start_time);
+CREATE INDEX idx_scans_device_id ON scans (device_id, start_time);
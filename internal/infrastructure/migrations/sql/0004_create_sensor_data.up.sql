@@ -0,0 +1,14 @@
+CREATE TABLE sensor_data (
+    id           UUID PRIMARY KEY,
+    scan_id      UUID             NOT NULL REFERENCES scans (id) ON DELETE CASCADE,
+    sensor_type  TEXT             NOT NULL,
+    timestamp    TIMESTAMPTZ      NOT NULL,
+    latitude     DOUBLE PRECISION NOT NULL,
+    longitude    DOUBLE PRECISION NOT NULL,
+    altitude     DOUBLE PRECISION NOT NULL,
+    data_json    JSONB,
+    quality_json JSONB
+);
+
+CREATE INDEX idx_sensor_data_scan_time ON sensor_data (scan_id, timestamp);
+CREATE INDEX idx_sensor_data_scan_type ON sensor_data (scan_id, sensor_type, timestamp);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS detected_objects;
@@ -0,0 +1,14 @@
+CREATE TABLE detected_objects (
+    id                  UUID PRIMARY KEY,
+    scan_id             UUID             NOT NULL REFERENCES scans (id) ON DELETE CASCADE,
+    latitude            DOUBLE PRECISION NOT NULL,
+    longitude           DOUBLE PRECISION NOT NULL,
+    depth               DOUBLE PRECISION NOT NULL,
+    object_type         TEXT             NOT NULL,
+    confidence          DOUBLE PRECISION NOT NULL,
+    danger_level        INTEGER          NOT NULL,
+    verification_status TEXT             NOT NULL
+);
+
+CREATE INDEX idx_detected_objects_scan_id ON detected_objects (scan_id);
+CREATE INDEX idx_detected_objects_location ON detected_objects (latitude, longitude);
@@ -0,0 +1,12 @@
+CREATE TABLE verification_reports (
+    id                 UUID PRIMARY KEY,
+    detected_object_id UUID             NOT NULL REFERENCES detected_objects (id) ON DELETE CASCADE,
+    outcome            TEXT             NOT NULL,
+    actual_object_type TEXT             NOT NULL DEFAULT '',
+    measured_depth     DOUBLE PRECISION,
+    team_id            TEXT             NOT NULL,
+    note               TEXT             NOT NULL DEFAULT '',
+    reported_at        TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX idx_verification_reports_object ON verification_reports (detected_object_id, reported_at);
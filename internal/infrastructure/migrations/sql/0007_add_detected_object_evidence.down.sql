@@ -0,0 +1,3 @@
+ALTER TABLE detected_objects
+    DROP COLUMN IF EXISTS plausibility,
+    DROP COLUMN IF EXISTS belief;
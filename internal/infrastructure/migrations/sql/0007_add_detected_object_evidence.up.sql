@@ -0,0 +1,6 @@
+ALTER TABLE detected_objects
+    ADD COLUMN belief       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN plausibility DOUBLE PRECISION NOT NULL DEFAULT 1;
+
+-- Для існуючих об'єктів інтервал невідомий, тож він вироджується в оцінку confidence
+UPDATE detected_objects SET belief = confidence, plausibility = confidence;
@@ -0,0 +1,6 @@
+CREATE TABLE detection_profiles (
+    mission_id  UUID PRIMARY KEY REFERENCES missions (id) ON DELETE CASCADE,
+    name        TEXT        NOT NULL,
+    config_json JSONB       NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE detected_objects DROP COLUMN IF EXISTS footprint_json;
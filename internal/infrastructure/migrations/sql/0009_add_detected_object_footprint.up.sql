@@ -0,0 +1 @@
+ALTER TABLE detected_objects ADD COLUMN footprint_json JSONB;
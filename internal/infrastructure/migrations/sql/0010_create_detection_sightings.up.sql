@@ -0,0 +1,21 @@
+CREATE TABLE detection_sightings (
+    id                 UUID PRIMARY KEY,
+    detected_object_id UUID             NOT NULL REFERENCES detected_objects (id) ON DELETE CASCADE,
+    scan_id            UUID             NOT NULL REFERENCES scans (id) ON DELETE CASCADE,
+    latitude           DOUBLE PRECISION NOT NULL,
+    longitude          DOUBLE PRECISION NOT NULL,
+    object_type        TEXT             NOT NULL,
+    confidence         DOUBLE PRECISION NOT NULL,
+    belief             DOUBLE PRECISION NOT NULL,
+    plausibility       DOUBLE PRECISION NOT NULL,
+    seen_at            TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX idx_detection_sightings_object ON detection_sightings (detected_object_id, seen_at);
+
+-- Кожен наявний об'єкт отримує перше спостереження зі свого сканування
+INSERT INTO detection_sightings (id, detected_object_id, scan_id, latitude, longitude, object_type, confidence, belief, plausibility, seen_at)
+SELECT d.id, d.id, d.scan_id, d.latitude, d.longitude, d.object_type, d.confidence, d.belief, d.plausibility,
+       COALESCE(s.end_time, s.start_time)
+FROM detected_objects d
+JOIN scans s ON s.id = d.scan_id;
@@ -0,0 +1 @@
+ALTER TABLE scans ADD COLUMN packet_stats_json JSONB;
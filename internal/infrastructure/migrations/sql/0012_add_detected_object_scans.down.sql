@@ -0,0 +1 @@
+ALTER TABLE detected_objects DROP COLUMN IF EXISTS scan_ids_json;
@@ -0,0 +1,12 @@
+ALTER TABLE detected_objects ADD COLUMN scan_ids_json JSONB;
+
+-- Сканування наявних об'єктів відновлюються з історії спостережень
+UPDATE detected_objects d
+SET scan_ids_json = COALESCE(
+    (SELECT jsonb_agg(s.scan_id ORDER BY s.first_seen)
+     FROM (SELECT scan_id, MIN(seen_at) AS first_seen
+           FROM detection_sightings
+           WHERE detected_object_id = d.id
+           GROUP BY scan_id) s),
+    jsonb_build_array(d.scan_id)
+);
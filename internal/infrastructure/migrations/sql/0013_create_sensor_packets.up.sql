@@ -0,0 +1,8 @@
+CREATE TABLE sensor_packets (
+    scan_id     UUID        NOT NULL REFERENCES scans (id) ON DELETE CASCADE,
+    epoch       INTEGER     NOT NULL,
+    sequence    BIGINT      NOT NULL,
+    digest      TEXT        NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scan_id, epoch, sequence)
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_detected_objects_scan_ids;
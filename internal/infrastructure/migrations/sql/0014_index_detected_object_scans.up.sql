@@ -0,0 +1,2 @@
+-- Пошук об'єктів за будь-яким скануванням, що їх спостерігало (scan_ids_json @> ...)
+CREATE INDEX idx_detected_objects_scan_ids ON detected_objects USING GIN (scan_ids_json jsonb_path_ops);
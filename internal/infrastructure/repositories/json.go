@@ -0,0 +1,26 @@
+package repositories
+
+import "encoding/json"
+
+// toJSON серіалізує довільне значення для збереження у JSONB-колонці
+func toJSON(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
+
+// fromJSON десеріалізує значення JSONB-колонки у довільну структуру
+func fromJSON(data []byte) (interface{}, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
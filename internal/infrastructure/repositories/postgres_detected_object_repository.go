@@ -0,0 +1,246 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"mine-detection-system/pkg/geo"
+)
+
+// PostgresDetectedObjectRepository імплементує DetectedObjectRepository для PostgreSQL
+type PostgresDetectedObjectRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDetectedObjectRepository створює новий екземпляр PostgresDetectedObjectRepository
+func NewPostgresDetectedObjectRepository(db *sql.DB) *PostgresDetectedObjectRepository {
+	return &PostgresDetectedObjectRepository{
+		db: db,
+	}
+}
+
+const detectedObjectColumns = `id, scan_id, latitude, longitude, depth, object_type, confidence, belief, plausibility, danger_level, verification_status, footprint_json, scan_ids_json`
+
+// Save зберігає новий виявлений об'єкт
+func (r *PostgresDetectedObjectRepository) Save(ctx context.Context, obj *domain.DetectedObject) error {
+	query := `
+        INSERT INTO detected_objects (` + detectedObjectColumns + `)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+    `
+
+	footprint, err := toJSON(obj.Footprint)
+	if err != nil {
+		return err
+	}
+
+	scanIDs, err := toJSON(obj.ScanIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		obj.ID,
+		obj.ScanID,
+		obj.Latitude,
+		obj.Longitude,
+		obj.Depth,
+		obj.ObjectType,
+		obj.Confidence,
+		obj.Belief,
+		obj.Plausibility,
+		obj.DangerLevel,
+		obj.VerificationStatus,
+		footprint,
+		scanIDs,
+	)
+
+	return err
+}
+
+// FindByID шукає виявлений об'єкт за ID
+func (r *PostgresDetectedObjectRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.DetectedObject, error) {
+	query := `SELECT ` + detectedObjectColumns + ` FROM detected_objects WHERE id = $1`
+
+	obj, err := scanDetectedObject(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrDetectedObjectNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// FindByScanID повертає всі об'єкти, які спостерігало сканування, зокрема
+// об'єкти попередніх сканувань, з якими злилися його детекції
+func (r *PostgresDetectedObjectRepository) FindByScanID(ctx context.Context, scanID uuid.UUID) ([]*domain.DetectedObject, error) {
+	query := `
+        SELECT ` + detectedObjectColumns + `
+        FROM detected_objects
+        WHERE scan_id = $1 OR scan_ids_json @> jsonb_build_array($2::text)
+        ORDER BY confidence DESC
+    `
+
+	return r.query(ctx, query, scanID, scanID.String())
+}
+
+// FindByCoordinates повертає об'єкти в радіусі radius метрів від точки (lat, lon).
+// Запит відбирає кандидатів за обмежувальним прямокутником, а точна відстань
+// перевіряється за формулою гаверсинусів.
+func (r *PostgresDetectedObjectRepository) FindByCoordinates(ctx context.Context, lat, lon float64, radius float64) ([]*domain.DetectedObject, error) {
+	if radius < 0 {
+		return nil, errors.New("radius must not be negative")
+	}
+
+	minLat, minLon, maxLat, maxLon := geo.BoundingBox(lat, lon, radius)
+
+	query := `
+        SELECT ` + detectedObjectColumns + `
+        FROM detected_objects
+        WHERE latitude BETWEEN $1 AND $2 AND longitude BETWEEN $3 AND $4
+    `
+
+	candidates, err := r.query(ctx, query, minLat, maxLat, minLon, maxLon)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*domain.DetectedObject
+	for _, obj := range candidates {
+		if geo.Distance(lat, lon, obj.Latitude, obj.Longitude) <= radius {
+			result = append(result, obj)
+		}
+	}
+
+	return result, nil
+}
+
+// Update оновлює інформацію про виявлений об'єкт
+func (r *PostgresDetectedObjectRepository) Update(ctx context.Context, obj *domain.DetectedObject) error {
+	query := `
+        UPDATE detected_objects
+        SET scan_id = $1, latitude = $2, longitude = $3, depth = $4, object_type = $5,
+            confidence = $6, belief = $7, plausibility = $8, danger_level = $9,
+            verification_status = $10, footprint_json = $11, scan_ids_json = $12
+        WHERE id = $13
+    `
+
+	footprint, err := toJSON(obj.Footprint)
+	if err != nil {
+		return err
+	}
+
+	scanIDs, err := toJSON(obj.ScanIDs)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		obj.ScanID,
+		obj.Latitude,
+		obj.Longitude,
+		obj.Depth,
+		obj.ObjectType,
+		obj.Confidence,
+		obj.Belief,
+		obj.Plausibility,
+		obj.DangerLevel,
+		obj.VerificationStatus,
+		footprint,
+		scanIDs,
+		obj.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrDetectedObjectNotFound
+	}
+
+	return nil
+}
+
+// query виконує запит і зчитує список виявлених об'єктів
+func (r *PostgresDetectedObjectRepository) query(ctx context.Context, query string, args ...interface{}) ([]*domain.DetectedObject, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []*domain.DetectedObject
+	for rows.Next() {
+		obj, err := scanDetectedObject(rows)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// scanDetectedObject зчитує виявлений об'єкт з рядка результату
+func scanDetectedObject(row rowScanner) (*domain.DetectedObject, error) {
+	var (
+		obj       domain.DetectedObject
+		footprint []byte
+		scanIDs   []byte
+	)
+
+	if err := row.Scan(
+		&obj.ID,
+		&obj.ScanID,
+		&obj.Latitude,
+		&obj.Longitude,
+		&obj.Depth,
+		&obj.ObjectType,
+		&obj.Confidence,
+		&obj.Belief,
+		&obj.Plausibility,
+		&obj.DangerLevel,
+		&obj.VerificationStatus,
+		&footprint,
+		&scanIDs,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(footprint) > 0 {
+		if err := json.Unmarshal(footprint, &obj.Footprint); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(scanIDs) > 0 {
+		if err := json.Unmarshal(scanIDs, &obj.ScanIDs); err != nil {
+			return nil, err
+		}
+	}
+	if len(obj.ScanIDs) == 0 {
+		obj.ScanIDs = []uuid.UUID{obj.ScanID}
+	}
+
+	return &obj, nil
+}
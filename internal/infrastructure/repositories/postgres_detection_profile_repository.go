@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+)
+
+// PostgresDetectionProfileRepository імплементує DetectionProfileRepository для PostgreSQL
+type PostgresDetectionProfileRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresDetectionProfileRepository створює новий екземпляр PostgresDetectionProfileRepository
+func NewPostgresDetectionProfileRepository(db *sql.DB) *PostgresDetectionProfileRepository {
+	return &PostgresDetectionProfileRepository{
+		db: db,
+	}
+}
+
+// Save зберігає профіль місії, замінюючи попередній
+func (r *PostgresDetectionProfileRepository) Save(ctx context.Context, profile *domain.DetectionProfile) error {
+	query := `
+        INSERT INTO detection_profiles (mission_id, name, config_json, updated_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (mission_id) DO UPDATE
+        SET name = EXCLUDED.name, config_json = EXCLUDED.config_json, updated_at = EXCLUDED.updated_at
+    `
+
+	config, err := toJSON(profile.Config)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		profile.MissionID,
+		profile.Name,
+		config,
+		profile.UpdatedAt,
+	)
+
+	return err
+}
+
+// FindByMissionID шукає профіль місії
+func (r *PostgresDetectionProfileRepository) FindByMissionID(ctx context.Context, missionID uuid.UUID) (*domain.DetectionProfile, error) {
+	query := `
+        SELECT mission_id, name, config_json, updated_at
+        FROM detection_profiles
+        WHERE mission_id = $1
+    `
+
+	var (
+		profile domain.DetectionProfile
+		config  []byte
+	)
+	err := r.db.QueryRowContext(ctx, query, missionID).Scan(
+		&profile.MissionID,
+		&profile.Name,
+		&config,
+		&profile.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrDetectionProfileNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.Config, err = fromJSON(config); err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// Delete видаляє профіль місії
+func (r *PostgresDetectionProfileRepository) Delete(ctx context.Context, missionID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM detection_profiles WHERE mission_id = $1`, missionID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrDetectionProfileNotFound
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"mine-detection-system/internal/domain"
+	"strconv"
 )
 
 // PostgresDeviceRepository імплементує DeviceRepository для PostgreSQL
@@ -27,13 +28,18 @@ func (r *PostgresDeviceRepository) Save(ctx context.Context, device *domain.Devi
         VALUES ($1, $2, $3, $4, $5, $6, $7)
     `
 
-	_, err := r.db.ExecContext(
+	config, err := toJSON(device.Configuration)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
 		ctx,
 		query,
 		device.ID,
 		device.DeviceType,
 		device.SerialNumber,
-		device.Configuration,
+		config,
 		device.Status,
 		device.CreatedAt,
 		device.LastConnectionAt,
@@ -50,12 +56,15 @@ func (r *PostgresDeviceRepository) FindByID(ctx context.Context, id uuid.UUID) (
         WHERE id = $1
     `
 
-	var device domain.Device
+	var (
+		device domain.Device
+		config []byte
+	)
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&device.ID,
 		&device.DeviceType,
 		&device.SerialNumber,
-		&device.Configuration,
+		&config,
 		&device.Status,
 		&device.CreatedAt,
 		&device.LastConnectionAt,
@@ -69,6 +78,10 @@ func (r *PostgresDeviceRepository) FindByID(ctx context.Context, id uuid.UUID) (
 		return nil, err
 	}
 
+	if device.Configuration, err = fromJSON(config); err != nil {
+		return nil, err
+	}
+
 	return &device, nil
 }
 
@@ -85,19 +98,19 @@ func (r *PostgresDeviceRepository) FindAll(ctx context.Context, filters map[stri
 
 	// Додавання фільтрів
 	if serialNumber, ok := filters["serial_number"]; ok {
-		query += " AND serial_number = $" + string(argIndex)
+		query += " AND serial_number = $" + strconv.Itoa(argIndex)
 		args = append(args, serialNumber)
 		argIndex++
 	}
 
 	if deviceType, ok := filters["device_type"]; ok {
-		query += " AND device_type = $" + string(argIndex)
+		query += " AND device_type = $" + strconv.Itoa(argIndex)
 		args = append(args, deviceType)
 		argIndex++
 	}
 
 	if status, ok := filters["status"]; ok {
-		query += " AND status = $" + string(argIndex)
+		query += " AND status = $" + strconv.Itoa(argIndex)
 		args = append(args, status)
 		argIndex++
 	}
@@ -110,18 +123,24 @@ func (r *PostgresDeviceRepository) FindAll(ctx context.Context, filters map[stri
 
 	var devices []*domain.Device
 	for rows.Next() {
-		var device domain.Device
+		var (
+			device domain.Device
+			config []byte
+		)
 		if err := rows.Scan(
 			&device.ID,
 			&device.DeviceType,
 			&device.SerialNumber,
-			&device.Configuration,
+			&config,
 			&device.Status,
 			&device.CreatedAt,
 			&device.LastConnectionAt,
 		); err != nil {
 			return nil, err
 		}
+		if device.Configuration, err = fromJSON(config); err != nil {
+			return nil, err
+		}
 		devices = append(devices, &device)
 	}
 
@@ -140,12 +159,17 @@ func (r *PostgresDeviceRepository) Update(ctx context.Context, device *domain.De
         WHERE id = $6
     `
 
+	config, err := toJSON(device.Configuration)
+	if err != nil {
+		return err
+	}
+
 	result, err := r.db.ExecContext(
 		ctx,
 		query,
 		device.DeviceType,
 		device.SerialNumber,
-		device.Configuration,
+		config,
 		device.Status,
 		device.LastConnectionAt,
 		device.ID,
@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"strconv"
+)
+
+// PostgresMissionRepository імплементує MissionRepository для PostgreSQL
+type PostgresMissionRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresMissionRepository створює новий екземпляр PostgresMissionRepository
+func NewPostgresMissionRepository(db *sql.DB) *PostgresMissionRepository {
+	return &PostgresMissionRepository{
+		db: db,
+	}
+}
+
+const missionColumns = `id, name, description, boundaries_json, status, start_date, end_date, priority`
+
+// Save зберігає нову місію
+func (r *PostgresMissionRepository) Save(ctx context.Context, mission *domain.Mission) error {
+	query := `
+        INSERT INTO missions (id, name, description, boundaries_json, status, start_date, end_date, priority)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    `
+
+	boundaries, err := toJSON(mission.Boundaries)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		mission.ID,
+		mission.Name,
+		mission.Description,
+		boundaries,
+		mission.Status,
+		mission.StartDate,
+		mission.EndDate,
+		mission.Priority,
+	)
+
+	return err
+}
+
+// FindByID шукає місію за ID
+func (r *PostgresMissionRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Mission, error) {
+	query := `SELECT ` + missionColumns + ` FROM missions WHERE id = $1`
+
+	mission, err := scanMission(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrMissionNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mission, nil
+}
+
+// FindAll шукає місії за фільтрами
+func (r *PostgresMissionRepository) FindAll(ctx context.Context, filters map[string]interface{}) ([]*domain.Mission, error) {
+	query := `SELECT ` + missionColumns + ` FROM missions WHERE 1=1`
+
+	var args []interface{}
+	argIndex := 1
+
+	// Додавання фільтрів
+	if status, ok := filters["status"]; ok {
+		query += " AND status = $" + strconv.Itoa(argIndex)
+		args = append(args, status)
+		argIndex++
+	}
+
+	if priority, ok := filters["priority"]; ok {
+		query += " AND priority = $" + strconv.Itoa(argIndex)
+		args = append(args, priority)
+		argIndex++
+	}
+
+	if name, ok := filters["name"]; ok {
+		query += " AND name = $" + strconv.Itoa(argIndex)
+		args = append(args, name)
+		argIndex++
+	}
+
+	query += " ORDER BY priority DESC, start_date"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var missions []*domain.Mission
+	for rows.Next() {
+		mission, err := scanMission(rows)
+		if err != nil {
+			return nil, err
+		}
+		missions = append(missions, mission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return missions, nil
+}
+
+// Update оновлює інформацію про місію
+func (r *PostgresMissionRepository) Update(ctx context.Context, mission *domain.Mission) error {
+	query := `
+        UPDATE missions
+        SET name = $1, description = $2, boundaries_json = $3, status = $4, start_date = $5, end_date = $6, priority = $7
+        WHERE id = $8
+    `
+
+	boundaries, err := toJSON(mission.Boundaries)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		mission.Name,
+		mission.Description,
+		boundaries,
+		mission.Status,
+		mission.StartDate,
+		mission.EndDate,
+		mission.Priority,
+		mission.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrMissionNotFound
+	}
+
+	return nil
+}
+
+// Delete видаляє місію
+func (r *PostgresMissionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM missions WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrMissionNotFound
+	}
+
+	return nil
+}
+
+// rowScanner узагальнює *sql.Row та *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanMission зчитує місію з рядка результату
+func scanMission(row rowScanner) (*domain.Mission, error) {
+	var (
+		mission    domain.Mission
+		boundaries []byte
+		endDate    sql.NullTime
+	)
+
+	if err := row.Scan(
+		&mission.ID,
+		&mission.Name,
+		&mission.Description,
+		&boundaries,
+		&mission.Status,
+		&mission.StartDate,
+		&endDate,
+		&mission.Priority,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(boundaries) > 0 {
+		if err := json.Unmarshal(boundaries, &mission.Boundaries); err != nil {
+			return nil, err
+		}
+	}
+
+	if endDate.Valid {
+		mission.EndDate = &endDate.Time
+	}
+
+	return &mission, nil
+}
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+)
+
+// PostgresScanRepository імплементує ScanRepository для PostgreSQL
+type PostgresScanRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresScanRepository створює новий екземпляр PostgresScanRepository
+func NewPostgresScanRepository(db *sql.DB) *PostgresScanRepository {
+	return &PostgresScanRepository{
+		db: db,
+	}
+}
+
+const scanColumns = `id, mission_id, device_id, start_time, end_time, scan_type, status, metadata_json, packet_stats_json`
+
+// Save зберігає нове сканування
+func (r *PostgresScanRepository) Save(ctx context.Context, scan *domain.Scan) error {
+	query := `
+        INSERT INTO scans (id, mission_id, device_id, start_time, end_time, scan_type, status, metadata_json, packet_stats_json)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `
+
+	metadata, err := toJSON(scan.Metadata)
+	if err != nil {
+		return err
+	}
+
+	packetStats, err := packetStatsJSON(scan.PacketStats)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		scan.ID,
+		scan.MissionID,
+		scan.DeviceID,
+		scan.StartTime,
+		scan.EndTime,
+		scan.ScanType,
+		scan.Status,
+		metadata,
+		packetStats,
+	)
+
+	return err
+}
+
+// FindByID шукає сканування за ID
+func (r *PostgresScanRepository) FindByID(ctx context.Context, id uuid.UUID) (*domain.Scan, error) {
+	query := `SELECT ` + scanColumns + ` FROM scans WHERE id = $1`
+
+	scan, err := scanScan(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ports.ErrScanNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scan, nil
+}
+
+// FindByMissionID шукає всі сканування місії
+func (r *PostgresScanRepository) FindByMissionID(ctx context.Context, missionID uuid.UUID) ([]*domain.Scan, error) {
+	query := `SELECT ` + scanColumns + ` FROM scans WHERE mission_id = $1 ORDER BY start_time`
+
+	return r.query(ctx, query, missionID)
+}
+
+// FindByDeviceID шукає всі сканування, виконані пристроєм
+func (r *PostgresScanRepository) FindByDeviceID(ctx context.Context, deviceID uuid.UUID) ([]*domain.Scan, error) {
+	query := `SELECT ` + scanColumns + ` FROM scans WHERE device_id = $1 ORDER BY start_time`
+
+	return r.query(ctx, query, deviceID)
+}
+
+// Update оновлює інформацію про сканування
+func (r *PostgresScanRepository) Update(ctx context.Context, scan *domain.Scan) error {
+	query := `
+        UPDATE scans
+        SET mission_id = $1, device_id = $2, start_time = $3, end_time = $4, scan_type = $5, status = $6, metadata_json = $7,
+            packet_stats_json = $8
+        WHERE id = $9
+    `
+
+	metadata, err := toJSON(scan.Metadata)
+	if err != nil {
+		return err
+	}
+
+	packetStats, err := packetStatsJSON(scan.PacketStats)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		scan.MissionID,
+		scan.DeviceID,
+		scan.StartTime,
+		scan.EndTime,
+		scan.ScanType,
+		scan.Status,
+		metadata,
+		packetStats,
+		scan.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrScanNotFound
+	}
+
+	return nil
+}
+
+// query виконує запит і зчитує список сканувань
+func (r *PostgresScanRepository) query(ctx context.Context, query string, args ...interface{}) ([]*domain.Scan, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scans []*domain.Scan
+	for rows.Next() {
+		scan, err := scanScan(rows)
+		if err != nil {
+			return nil, err
+		}
+		scans = append(scans, scan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return scans, nil
+}
+
+// scanScan зчитує сканування з рядка результату
+func scanScan(row rowScanner) (*domain.Scan, error) {
+	var (
+		scan        domain.Scan
+		endTime     sql.NullTime
+		metadata    []byte
+		packetStats []byte
+	)
+
+	if err := row.Scan(
+		&scan.ID,
+		&scan.MissionID,
+		&scan.DeviceID,
+		&scan.StartTime,
+		&endTime,
+		&scan.ScanType,
+		&scan.Status,
+		&metadata,
+		&packetStats,
+	); err != nil {
+		return nil, err
+	}
+
+	if endTime.Valid {
+		scan.EndTime = &endTime.Time
+	}
+
+	var err error
+	if scan.Metadata, err = fromJSON(metadata); err != nil {
+		return nil, err
+	}
+
+	if len(packetStats) > 0 {
+		if err := json.Unmarshal(packetStats, &scan.PacketStats); err != nil {
+			return nil, err
+		}
+	}
+
+	return &scan, nil
+}
+
+// packetStatsJSON серіалізує статистику пакетів; відсутня статистика зберігається як NULL
+func packetStatsJSON(stats *domain.PacketStats) ([]byte, error) {
+	if stats == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(stats)
+}
@@ -0,0 +1,288 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sensorDataBatchSize обмежує кількість рядків в одному INSERT,
+// щоб не перевищити ліміт PostgreSQL у 65535 параметрів на запит
+const sensorDataBatchSize = 1000
+
+// PostgresSensorDataRepository імплементує SensorDataRepository для PostgreSQL
+type PostgresSensorDataRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSensorDataRepository створює новий екземпляр PostgresSensorDataRepository
+func NewPostgresSensorDataRepository(db *sql.DB) *PostgresSensorDataRepository {
+	return &PostgresSensorDataRepository{
+		db: db,
+	}
+}
+
+const sensorDataColumns = `id, scan_id, sensor_type, timestamp, latitude, longitude, altitude, data_json, quality_json`
+
+// SaveBatch зберігає пакет даних сенсорів багаторядковими INSERT в одній транзакції
+func (r *PostgresSensorDataRepository) SaveBatch(ctx context.Context, data []*domain.SensorData) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertSensorDataBatches(ctx, tx, data); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SavePacket зберігає квитанцію пакета і його виміри в одній транзакції.
+// Первинний ключ квитанції не дає зберегти виміри повторно переданого пакета.
+func (r *PostgresSensorDataRepository) SavePacket(ctx context.Context, packet *domain.SensorPacket, data []*domain.SensorData) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+        INSERT INTO sensor_packets (` + sensorPacketColumns + `)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT DO NOTHING
+    `
+
+	result, err := tx.ExecContext(ctx, query, packet.ScanID, packet.Epoch, int64(packet.Sequence), packet.Digest, packet.ReceivedAt)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ports.ErrDuplicatePacket
+	}
+
+	if err := insertSensorDataBatches(ctx, tx, data); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const sensorPacketColumns = `scan_id, epoch, sequence, digest, received_at`
+
+// FindPacket шукає квитанцію пакета за скануванням, епохою і номером
+func (r *PostgresSensorDataRepository) FindPacket(ctx context.Context, scanID uuid.UUID, epoch int, sequence uint32) (*domain.SensorPacket, error) {
+	query := `SELECT ` + sensorPacketColumns + ` FROM sensor_packets WHERE scan_id = $1 AND epoch = $2 AND sequence = $3`
+
+	packet, err := scanSensorPacket(r.db.QueryRowContext(ctx, query, scanID, epoch, int64(sequence)))
+	if err == sql.ErrNoRows {
+		return nil, errors.New("sensor packet not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return packet, nil
+}
+
+// FindPackets повертає квитанції пакетів сканування за зростанням епохи і номера
+func (r *PostgresSensorDataRepository) FindPackets(ctx context.Context, scanID uuid.UUID) ([]*domain.SensorPacket, error) {
+	query := `SELECT ` + sensorPacketColumns + ` FROM sensor_packets WHERE scan_id = $1 ORDER BY epoch, sequence`
+
+	rows, err := r.db.QueryContext(ctx, query, scanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*domain.SensorPacket
+	for rows.Next() {
+		packet, err := scanSensorPacket(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, packet)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// scanSensorPacket зчитує квитанцію пакета з рядка результату
+func scanSensorPacket(row rowScanner) (*domain.SensorPacket, error) {
+	var (
+		packet   domain.SensorPacket
+		sequence int64
+	)
+
+	if err := row.Scan(&packet.ScanID, &packet.Epoch, &sequence, &packet.Digest, &packet.ReceivedAt); err != nil {
+		return nil, err
+	}
+	packet.Sequence = uint32(sequence)
+
+	return &packet, nil
+}
+
+// insertSensorDataBatches зберігає виміри частинами по sensorDataBatchSize рядків
+func insertSensorDataBatches(ctx context.Context, tx *sql.Tx, data []*domain.SensorData) error {
+	for start := 0; start < len(data); start += sensorDataBatchSize {
+		end := start + sensorDataBatchSize
+		if end > len(data) {
+			end = len(data)
+		}
+
+		if err := insertSensorData(ctx, tx, data[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertSensorData формує та виконує один багаторядковий INSERT
+func insertSensorData(ctx context.Context, tx *sql.Tx, data []*domain.SensorData) error {
+	const columnCount = 9
+
+	var query strings.Builder
+	query.WriteString(`INSERT INTO sensor_data (` + sensorDataColumns + `) VALUES `)
+
+	args := make([]interface{}, 0, len(data)*columnCount)
+	for i, sample := range data {
+		payload, err := toJSON(sample.Data)
+		if err != nil {
+			return err
+		}
+
+		quality, err := toJSON(sample.QualityIndicators)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for j := 1; j <= columnCount; j++ {
+			if j > 1 {
+				query.WriteString(", ")
+			}
+			query.WriteString("$" + strconv.Itoa(i*columnCount+j))
+		}
+		query.WriteString(")")
+
+		args = append(args,
+			sample.ID,
+			sample.ScanID,
+			sample.SensorType,
+			sample.Timestamp,
+			sample.Latitude,
+			sample.Longitude,
+			sample.Altitude,
+			payload,
+			quality,
+		)
+	}
+
+	_, err := tx.ExecContext(ctx, query.String(), args...)
+	return err
+}
+
+// FindByScanID повертає дані сканування посторінково; limit <= 0 означає без обмеження
+func (r *PostgresSensorDataRepository) FindByScanID(ctx context.Context, scanID uuid.UUID, limit, offset int) ([]*domain.SensorData, error) {
+	query := `SELECT ` + sensorDataColumns + ` FROM sensor_data WHERE scan_id = $1 ORDER BY timestamp, id`
+	args := []interface{}{scanID}
+
+	if limit > 0 {
+		query += " LIMIT $" + strconv.Itoa(len(args)+1)
+		args = append(args, limit)
+	}
+
+	if offset > 0 {
+		query += " OFFSET $" + strconv.Itoa(len(args)+1)
+		args = append(args, offset)
+	}
+
+	return r.query(ctx, query, args...)
+}
+
+// FindBySensorType повертає всі дані сканування для заданого типу сенсора
+func (r *PostgresSensorDataRepository) FindBySensorType(ctx context.Context, scanID uuid.UUID, sensorType string) ([]*domain.SensorData, error) {
+	query := `SELECT ` + sensorDataColumns + ` FROM sensor_data WHERE scan_id = $1 AND sensor_type = $2 ORDER BY timestamp, id`
+
+	return r.query(ctx, query, scanID, sensorType)
+}
+
+// FindByTimeRange повертає дані сканування у проміжку часу [start, end]
+func (r *PostgresSensorDataRepository) FindByTimeRange(ctx context.Context, scanID uuid.UUID, start, end time.Time) ([]*domain.SensorData, error) {
+	query := `SELECT ` + sensorDataColumns + ` FROM sensor_data WHERE scan_id = $1 AND timestamp BETWEEN $2 AND $3 ORDER BY timestamp, id`
+
+	return r.query(ctx, query, scanID, start, end)
+}
+
+// query виконує запит і зчитує список даних сенсорів
+func (r *PostgresSensorDataRepository) query(ctx context.Context, query string, args ...interface{}) ([]*domain.SensorData, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*domain.SensorData
+	for rows.Next() {
+		var (
+			sample  domain.SensorData
+			payload []byte
+			quality []byte
+		)
+
+		if err := rows.Scan(
+			&sample.ID,
+			&sample.ScanID,
+			&sample.SensorType,
+			&sample.Timestamp,
+			&sample.Latitude,
+			&sample.Longitude,
+			&sample.Altitude,
+			&payload,
+			&quality,
+		); err != nil {
+			return nil, err
+		}
+
+		if sample.Data, err = fromJSON(payload); err != nil {
+			return nil, err
+		}
+		if sample.QualityIndicators, err = fromJSON(quality); err != nil {
+			return nil, err
+		}
+
+		result = append(result, &sample)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+)
+
+// PostgresSightingRepository імплементує SightingRepository для PostgreSQL
+type PostgresSightingRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresSightingRepository створює новий екземпляр PostgresSightingRepository
+func NewPostgresSightingRepository(db *sql.DB) *PostgresSightingRepository {
+	return &PostgresSightingRepository{
+		db: db,
+	}
+}
+
+// Save зберігає нове спостереження
+func (r *PostgresSightingRepository) Save(ctx context.Context, sighting *domain.Sighting) error {
+	query := `
+        INSERT INTO detection_sightings (id, detected_object_id, scan_id, latitude, longitude, object_type, confidence, belief, plausibility, seen_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    `
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		sighting.ID,
+		sighting.DetectedObjectID,
+		sighting.ScanID,
+		sighting.Latitude,
+		sighting.Longitude,
+		sighting.ObjectType,
+		sighting.Confidence,
+		sighting.Belief,
+		sighting.Plausibility,
+		sighting.SeenAt,
+	)
+
+	return err
+}
+
+// FindByDetectedObjectID повертає історію спостережень об'єкта в хронологічному порядку
+func (r *PostgresSightingRepository) FindByDetectedObjectID(ctx context.Context, objectID uuid.UUID) ([]*domain.Sighting, error) {
+	query := `
+        SELECT id, detected_object_id, scan_id, latitude, longitude, object_type, confidence, belief, plausibility, seen_at
+        FROM detection_sightings
+        WHERE detected_object_id = $1
+        ORDER BY seen_at
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, objectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sightings []*domain.Sighting
+	for rows.Next() {
+		var sighting domain.Sighting
+		if err := rows.Scan(
+			&sighting.ID,
+			&sighting.DetectedObjectID,
+			&sighting.ScanID,
+			&sighting.Latitude,
+			&sighting.Longitude,
+			&sighting.ObjectType,
+			&sighting.Confidence,
+			&sighting.Belief,
+			&sighting.Plausibility,
+			&sighting.SeenAt,
+		); err != nil {
+			return nil, err
+		}
+		sightings = append(sightings, &sighting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sightings, nil
+}
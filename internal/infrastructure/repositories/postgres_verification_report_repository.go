@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/domain"
+)
+
+// PostgresVerificationReportRepository імплементує VerificationReportRepository для PostgreSQL
+type PostgresVerificationReportRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresVerificationReportRepository створює новий екземпляр PostgresVerificationReportRepository
+func NewPostgresVerificationReportRepository(db *sql.DB) *PostgresVerificationReportRepository {
+	return &PostgresVerificationReportRepository{
+		db: db,
+	}
+}
+
+// Save зберігає новий звіт про верифікацію
+func (r *PostgresVerificationReportRepository) Save(ctx context.Context, report *domain.VerificationReport) error {
+	query := `
+        INSERT INTO verification_reports (id, detected_object_id, outcome, actual_object_type, measured_depth, team_id, note, reported_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    `
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		report.ID,
+		report.DetectedObjectID,
+		report.Outcome,
+		report.ActualObjectType,
+		report.MeasuredDepth,
+		report.TeamID,
+		report.Note,
+		report.ReportedAt,
+	)
+
+	return err
+}
+
+// FindByDetectedObjectID повертає історію звітів для об'єкта в хронологічному порядку
+func (r *PostgresVerificationReportRepository) FindByDetectedObjectID(ctx context.Context, objectID uuid.UUID) ([]*domain.VerificationReport, error) {
+	query := `
+        SELECT id, detected_object_id, outcome, actual_object_type, measured_depth, team_id, note, reported_at
+        FROM verification_reports
+        WHERE detected_object_id = $1
+        ORDER BY reported_at
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, objectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []*domain.VerificationReport
+	for rows.Next() {
+		var (
+			report domain.VerificationReport
+			depth  sql.NullFloat64
+		)
+		if err := rows.Scan(
+			&report.ID,
+			&report.DetectedObjectID,
+			&report.Outcome,
+			&report.ActualObjectType,
+			&depth,
+			&report.TeamID,
+			&report.Note,
+			&report.ReportedAt,
+		); err != nil {
+			return nil, err
+		}
+		if depth.Valid {
+			report.MeasuredDepth = &depth.Float64
+		}
+		reports = append(reports, &report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
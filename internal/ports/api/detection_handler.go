@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/application"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"net/http"
+	"strconv"
+)
+
+// defaultSearchRadius радіус пошуку об'єктів за замовчуванням (у метрах)
+const defaultSearchRadius = 50.0
+
+// DetectionHandler обробляє HTTP-запити, пов'язані з виявленими об'єктами
+type DetectionHandler struct {
+	detectionService    *application.DetectionService
+	verificationService *application.VerificationService
+}
+
+// NewDetectionHandler створює новий DetectionHandler
+func NewDetectionHandler(
+	detectionService *application.DetectionService,
+	verificationService *application.VerificationService,
+) *DetectionHandler {
+	return &DetectionHandler{
+		detectionService:    detectionService,
+		verificationService: verificationService,
+	}
+}
+
+// RegisterRoutes реєструє маршрути для DetectionHandler
+func (h *DetectionHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/detections", func(r chi.Router) {
+		r.Get("/", h.SearchDetections)
+		r.Get("/{id}", h.GetDetection)
+		r.Get("/{id}/sightings", h.ListSightings)
+		r.Get("/{id}/reports", h.ListReports)
+		r.Post("/{id}/reports", h.SubmitReport)
+	})
+}
+
+// SearchDetections обробляє GET /detections?lat=&lon=&radius=
+func (h *DetectionHandler) SearchDetections(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil {
+		http.Error(w, "Invalid or missing lat", http.StatusBadRequest)
+		return
+	}
+
+	lon, err := strconv.ParseFloat(query.Get("lon"), 64)
+	if err != nil {
+		http.Error(w, "Invalid or missing lon", http.StatusBadRequest)
+		return
+	}
+
+	radius := defaultSearchRadius
+	if value := query.Get("radius"); value != "" {
+		if radius, err = strconv.ParseFloat(value, 64); err != nil {
+			http.Error(w, "Invalid radius", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := r.Context()
+	detections, err := h.detectionService.FindNearby(ctx, lat, lon, radius)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detections)
+}
+
+// GetDetection обробляє GET /detections/{id}
+func (h *DetectionHandler) GetDetection(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid detection ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	detection, err := h.detectionService.GetDetectionByID(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), detectionErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detection)
+}
+
+// ListSightings обробляє GET /detections/{id}/sightings
+func (h *DetectionHandler) ListSightings(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid detection ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	sightings, err := h.detectionService.ListSightings(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), detectionErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sightings)
+}
+
+// ListReports обробляє GET /detections/{id}/reports
+func (h *DetectionHandler) ListReports(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid detection ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	reports, err := h.verificationService.ListReports(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), detectionErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reports)
+}
+
+// SubmitReport обробляє POST /detections/{id}/reports
+func (h *DetectionHandler) SubmitReport(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid detection ID", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Outcome          string   `json:"outcome"`
+		ActualObjectType string   `json:"actual_object_type"`
+		MeasuredDepth    *float64 `json:"measured_depth"`
+		TeamID           string   `json:"team_id"`
+		Note             string   `json:"note"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	report, detection, err := h.verificationService.SubmitReport(ctx, id, application.VerificationInput{
+		Outcome:          domain.VerificationStatus(request.Outcome),
+		ActualObjectType: request.ActualObjectType,
+		MeasuredDepth:    request.MeasuredDepth,
+		TeamID:           request.TeamID,
+		Note:             request.Note,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), detectionErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"report":    report,
+		"detection": detection,
+	})
+}
+
+// detectionErrorStatus підбирає HTTP-статус для помилки DetectionService або VerificationService
+func detectionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ports.ErrDetectedObjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrInvalidReport):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -2,22 +2,26 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"mine-detection-system/internal/application"
 	"mine-detection-system/internal/domain"
 	"net/http"
+	"time"
 )
 
 // DeviceHandler обробляє HTTP-запити, пов'язані з пристроями
 type DeviceHandler struct {
-	deviceService *application.DeviceService
+	deviceService  *application.DeviceService
+	commandService *application.CommandService
 }
 
 // NewDeviceHandler створює новий DeviceHandler
-func NewDeviceHandler(deviceService *application.DeviceService) *DeviceHandler {
+func NewDeviceHandler(deviceService *application.DeviceService, commandService *application.CommandService) *DeviceHandler {
 	return &DeviceHandler{
-		deviceService: deviceService,
+		deviceService:  deviceService,
+		commandService: commandService,
 	}
 }
 
@@ -29,6 +33,9 @@ func (h *DeviceHandler) RegisterRoutes(r chi.Router) {
 		r.Get("/{id}", h.GetDevice)
 		r.Put("/{id}/status", h.UpdateDeviceStatus)
 		r.Put("/{id}/config", h.UpdateDeviceConfig)
+		r.Get("/{id}/commands", h.ListDeviceCommands)
+		r.Post("/{id}/commands", h.SendDeviceCommand)
+		r.Get("/{id}/commands/{commandId}", h.GetDeviceCommand)
 	})
 }
 
@@ -153,11 +160,126 @@ func (h *DeviceHandler) UpdateDeviceConfig(w http.ResponseWriter, r *http.Reques
 	}
 
 	ctx := r.Context()
-	err = h.deviceService.UpdateDeviceConfiguration(ctx, id, config)
+	command, err := h.deviceService.UpdateDeviceConfiguration(ctx, id, config)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	// Для підключеного пристрою повертається стан команди apply_config
+	if command == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(command); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// ListDeviceCommands обробляє GET /devices/{id}/commands
+func (h *DeviceHandler) ListDeviceCommands(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid device ID", http.StatusBadRequest)
+		return
+	}
+
+	commands := h.commandService.ListCommands(id)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(commands); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// SendDeviceCommand обробляє POST /devices/{id}/commands.
+// З параметром wait запит очікує відповіді пристрою або спливання таймауту команди.
+func (h *DeviceHandler) SendDeviceCommand(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid device ID", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Type           domain.CommandType     `json:"type"`
+		Params         map[string]interface{} `json:"params"`
+		TimeoutSeconds float64                `json:"timeout_seconds"`
+		Wait           bool                   `json:"wait"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	timeout := time.Duration(request.TimeoutSeconds * float64(time.Second))
+	command, err := h.commandService.SendCommand(ctx, id, request.Type, request.Params, timeout)
+	if err != nil {
+		http.Error(w, err.Error(), commandErrorStatus(err))
+		return
+	}
+
+	status := http.StatusAccepted
+	if request.Wait && command.Status == domain.CommandStatusSent {
+		command, err = h.commandService.WaitForAck(ctx, command.ID)
+		if err != nil {
+			http.Error(w, err.Error(), commandErrorStatus(err))
+			return
+		}
+		status = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(command); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetDeviceCommand обробляє GET /devices/{id}/commands/{commandId}
+func (h *DeviceHandler) GetDeviceCommand(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid device ID", http.StatusBadRequest)
+		return
+	}
+
+	commandID, err := uuid.Parse(chi.URLParam(r, "commandId"))
+	if err != nil {
+		http.Error(w, "Invalid command ID", http.StatusBadRequest)
+		return
+	}
+
+	command, err := h.commandService.GetCommand(id, commandID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(command); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// commandErrorStatus підбирає HTTP-статус для помилки CommandService
+func commandErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, application.ErrInvalidCommand):
+		return http.StatusBadRequest
+	default:
+		// Решта помилок означає, що пристрій або команду не знайдено
+		return http.StatusNotFound
+	}
 }
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/application"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// MissionHandler обробляє HTTP-запити, пов'язані з місіями
+type MissionHandler struct {
+	missionService      *application.MissionService
+	scanService         *application.ScanService
+	verificationService *application.VerificationService
+	profileService      *application.DetectionProfileService
+}
+
+// NewMissionHandler створює новий MissionHandler
+func NewMissionHandler(
+	missionService *application.MissionService,
+	scanService *application.ScanService,
+	verificationService *application.VerificationService,
+	profileService *application.DetectionProfileService,
+) *MissionHandler {
+	return &MissionHandler{
+		missionService:      missionService,
+		scanService:         scanService,
+		verificationService: verificationService,
+		profileService:      profileService,
+	}
+}
+
+// RegisterRoutes реєструє маршрути для MissionHandler
+func (h *MissionHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/missions", func(r chi.Router) {
+		r.Get("/", h.ListMissions)
+		r.Post("/", h.CreateMission)
+		r.Get("/{id}", h.GetMission)
+		r.Put("/{id}", h.UpdateMission)
+		r.Put("/{id}/status", h.UpdateMissionStatus)
+		r.Get("/{id}/scans", h.ListMissionScans)
+		r.Get("/{id}/clearance", h.GetMissionClearance)
+		r.Get("/{id}/profile", h.GetMissionProfile)
+		r.Put("/{id}/profile", h.UpdateMissionProfile)
+		r.Delete("/{id}/profile", h.ResetMissionProfile)
+	})
+	r.Get("/detection-presets", h.ListDetectionPresets)
+}
+
+// ListMissions обробляє GET /missions
+func (h *MissionHandler) ListMissions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Отримання фільтрів з query parameters
+	filters := make(map[string]interface{})
+	if status := r.URL.Query().Get("status"); status != "" {
+		filters["status"] = status
+	}
+	if priority := r.URL.Query().Get("priority"); priority != "" {
+		value, err := strconv.Atoi(priority)
+		if err != nil {
+			http.Error(w, "Invalid priority", http.StatusBadRequest)
+			return
+		}
+		filters["priority"] = value
+	}
+
+	missions, err := h.missionService.ListMissions(ctx, filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, missions)
+}
+
+// CreateMission обробляє POST /missions
+func (h *MissionHandler) CreateMission(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description"`
+		Boundaries  domain.GeoJSON `json:"boundaries"`
+		StartDate   time.Time      `json:"start_date"`
+		Priority    int            `json:"priority"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	mission, err := h.missionService.CreateMission(
+		ctx,
+		request.Name,
+		request.Description,
+		request.Boundaries,
+		request.StartDate,
+		request.Priority,
+	)
+	if err != nil {
+		http.Error(w, err.Error(), missionErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, mission)
+}
+
+// GetMission обробляє GET /missions/{id}
+func (h *MissionHandler) GetMission(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid mission ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	mission, err := h.missionService.GetMissionByID(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mission)
+}
+
+// UpdateMission обробляє PUT /missions/{id}
+func (h *MissionHandler) UpdateMission(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid mission ID", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Name        *string        `json:"name"`
+		Description *string        `json:"description"`
+		Boundaries  domain.GeoJSON `json:"boundaries"`
+		StartDate   *time.Time     `json:"start_date"`
+		Priority    *int           `json:"priority"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	mission, err := h.missionService.UpdateMission(ctx, id, application.MissionUpdate{
+		Name:        request.Name,
+		Description: request.Description,
+		Boundaries:  request.Boundaries,
+		StartDate:   request.StartDate,
+		Priority:    request.Priority,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), missionErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mission)
+}
+
+// UpdateMissionStatus обробляє PUT /missions/{id}/status
+func (h *MissionHandler) UpdateMissionStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid mission ID", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Status string `json:"status"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	mission, err := h.missionService.ChangeMissionStatus(ctx, id, domain.MissionStatus(request.Status))
+	if err != nil {
+		http.Error(w, err.Error(), missionErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, mission)
+}
+
+// ListMissionScans обробляє GET /missions/{id}/scans
+func (h *MissionHandler) ListMissionScans(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid mission ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	scans, err := h.scanService.ListMissionScans(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, scans)
+}
+
+// GetMissionClearance обробляє GET /missions/{id}/clearance
+func (h *MissionHandler) GetMissionClearance(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid mission ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := h.missionService.GetMissionByID(ctx, id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	summary, err := h.verificationService.GetMissionClearance(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summary)
+}
+
+// GetMissionProfile обробляє GET /missions/{id}/profile
+func (h *MissionHandler) GetMissionProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid mission ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	profile, err := h.profileService.GetMissionProfile(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), missionErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// UpdateMissionProfile обробляє PUT /missions/{id}/profile
+func (h *MissionHandler) UpdateMissionProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid mission ID", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		Name   string          `json:"name"`
+		Config json.RawMessage `json:"config"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	profile, err := h.profileService.SetMissionProfile(ctx, id, request.Name, request.Config)
+	if err != nil {
+		http.Error(w, err.Error(), missionErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// ResetMissionProfile обробляє DELETE /missions/{id}/profile
+func (h *MissionHandler) ResetMissionProfile(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid mission ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.profileService.ResetMissionProfile(ctx, id); err != nil {
+		http.Error(w, err.Error(), missionErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDetectionPresets обробляє GET /detection-presets
+func (h *MissionHandler) ListDetectionPresets(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.profileService.Presets())
+}
+
+// missionErrorStatus підбирає HTTP-статус для помилки MissionService
+func missionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ports.ErrMissionNotFound), errors.Is(err, ports.ErrDetectionProfileNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, application.ErrInvalidMission), errors.Is(err, application.ErrInvalidProfile):
+		return http.StatusBadRequest
+	case errors.Is(err, application.ErrInvalidMissionTransition), errors.Is(err, application.ErrMissionClosed):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
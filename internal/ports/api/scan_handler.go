@@ -0,0 +1,126 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/application"
+	"mine-detection-system/internal/ports"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultPageSize кількість записів даних сенсорів на сторінці за замовчуванням
+	defaultPageSize = 100
+	// maxPageSize максимальна кількість записів на сторінці
+	maxPageSize = 1000
+)
+
+// ScanHandler обробляє HTTP-запити, пов'язані зі скануваннями
+type ScanHandler struct {
+	scanService      *application.ScanService
+	detectionService *application.DetectionService
+}
+
+// NewScanHandler створює новий ScanHandler
+func NewScanHandler(scanService *application.ScanService, detectionService *application.DetectionService) *ScanHandler {
+	return &ScanHandler{
+		scanService:      scanService,
+		detectionService: detectionService,
+	}
+}
+
+// RegisterRoutes реєструє маршрути для ScanHandler
+func (h *ScanHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/scans", func(r chi.Router) {
+		r.Get("/{id}", h.GetScan)
+		r.Get("/{id}/sensor-data", h.ListSensorData)
+		r.Get("/{id}/detections", h.ListDetections)
+	})
+}
+
+// GetScan обробляє GET /scans/{id}
+func (h *ScanHandler) GetScan(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid scan ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	scan, err := h.scanService.GetScanByID(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), scanErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, scan)
+}
+
+// ListSensorData обробляє GET /scans/{id}/sensor-data?limit=&offset=.
+// Якщо сторінка заповнена повністю, посилання на наступну повертається в заголовку Link.
+func (h *ScanHandler) ListSensorData(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid scan ID", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := queryInt(r, "limit", defaultPageSize)
+	if err != nil || limit <= 0 || limit > maxPageSize {
+		http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxPageSize), http.StatusBadRequest)
+		return
+	}
+
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	data, err := h.scanService.GetSensorData(ctx, id, limit, offset)
+	if err != nil {
+		http.Error(w, err.Error(), scanErrorStatus(err))
+		return
+	}
+
+	if len(data) == limit {
+		next := *r.URL
+		query := next.Query()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset+limit))
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+// ListDetections обробляє GET /scans/{id}/detections
+func (h *ScanHandler) ListDetections(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid scan ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	detections, err := h.detectionService.ListScanDetections(ctx, id)
+	if err != nil {
+		http.Error(w, err.Error(), scanErrorStatus(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, detections)
+}
+
+// scanErrorStatus підбирає HTTP-статус для помилки ScanService
+func scanErrorStatus(err error) int {
+	if errors.Is(err, ports.ErrScanNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,47 @@
+package ports
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Типи подій, які отримують консолі операторів
+const (
+	EventDetectionProvisional = "detection.provisional"
+	EventDetectionCreated     = "detection.created"
+	EventDetectionUpdated     = "detection.updated"
+	EventDeviceConnected      = "device.connected"
+	EventDeviceDisconnected   = "device.disconnected"
+	EventDeviceStatus         = "device.status"
+	EventScanStarted          = "scan.started"
+	EventScanEnded            = "scan.ended"
+	EventScanCoverage         = "scan.coverage"
+	EventDeviceCommand        = "device.command"
+)
+
+// Event представляє подію системи для live-стрічки операторів.
+// MissionID, ScanID та DeviceID дорівнюють uuid.Nil, якщо подія до них не належить.
+type Event struct {
+	Type      string
+	MissionID uuid.UUID
+	ScanID    uuid.UUID
+	DeviceID  uuid.UUID
+	Time      time.Time
+	Payload   interface{}
+}
+
+// EventBus визначає методи внутрішньопроцесної шини подій
+type EventBus interface {
+	// Publish надсилає подію всім підписникам і ніколи не блокується
+	Publish(event Event)
+	// Subscribe повертає канал подій та функцію скасування підписки
+	Subscribe(buffer int) (<-chan Event, func())
+}
+
+// DeviceTransport визначає методи доставки повідомлень підключеним пристроям
+type DeviceTransport interface {
+	// Send надсилає JSON-повідомлення пристрою; повертає помилку, якщо пристрій не підключений
+	Send(deviceID uuid.UUID, message interface{}) error
+	// IsConnected повідомляє, чи має пристрій активне з'єднання
+	IsConnected(deviceID uuid.UUID) bool
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"mine-detection-system/internal/domain"
 	"time"
@@ -16,6 +17,9 @@ type DeviceRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+// ErrMissionNotFound повертається, якщо місії з таким ID немає
+var ErrMissionNotFound = errors.New("mission not found")
+
 // MissionRepository визначає методи для роботи з місіями
 type MissionRepository interface {
 	Save(ctx context.Context, mission *domain.Mission) error
@@ -25,6 +29,9 @@ type MissionRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+// ErrScanNotFound повертається, якщо сканування з таким ID немає
+var ErrScanNotFound = errors.New("scan not found")
+
 // ScanRepository визначає методи для роботи зі скануваннями
 type ScanRepository interface {
 	Save(ctx context.Context, scan *domain.Scan) error
@@ -34,14 +41,26 @@ type ScanRepository interface {
 	Update(ctx context.Context, scan *domain.Scan) error
 }
 
+// ErrDuplicatePacket повертається, якщо квитанція пакета з тим самим ключем уже збережена
+var ErrDuplicatePacket = errors.New("duplicate sensor packet")
+
 // SensorDataRepository визначає методи для роботи з даними сенсорів
 type SensorDataRepository interface {
 	SaveBatch(ctx context.Context, data []*domain.SensorData) error
+	// SavePacket зберігає квитанцію пакета і його виміри атомарно. Якщо квитанція
+	// з тими самими скануванням, епохою і номером уже є, нічого не зберігається
+	// і повертається ErrDuplicatePacket.
+	SavePacket(ctx context.Context, packet *domain.SensorPacket, data []*domain.SensorData) error
+	FindPacket(ctx context.Context, scanID uuid.UUID, epoch int, sequence uint32) (*domain.SensorPacket, error)
+	FindPackets(ctx context.Context, scanID uuid.UUID) ([]*domain.SensorPacket, error)
 	FindByScanID(ctx context.Context, scanID uuid.UUID, limit, offset int) ([]*domain.SensorData, error)
 	FindBySensorType(ctx context.Context, scanID uuid.UUID, sensorType string) ([]*domain.SensorData, error)
 	FindByTimeRange(ctx context.Context, scanID uuid.UUID, start, end time.Time) ([]*domain.SensorData, error)
 }
 
+// ErrDetectedObjectNotFound повертається, якщо виявленого об'єкта з таким ID немає
+var ErrDetectedObjectNotFound = errors.New("detected object not found")
+
 // DetectedObjectRepository визначає методи для роботи з виявленими об'єктами
 type DetectedObjectRepository interface {
 	Save(ctx context.Context, obj *domain.DetectedObject) error
@@ -50,3 +69,25 @@ type DetectedObjectRepository interface {
 	FindByCoordinates(ctx context.Context, lat, lon float64, radius float64) ([]*domain.DetectedObject, error)
 	Update(ctx context.Context, obj *domain.DetectedObject) error
 }
+
+// VerificationReportRepository визначає методи для роботи зі звітами про верифікацію
+type VerificationReportRepository interface {
+	Save(ctx context.Context, report *domain.VerificationReport) error
+	FindByDetectedObjectID(ctx context.Context, objectID uuid.UUID) ([]*domain.VerificationReport, error)
+}
+
+// ErrDetectionProfileNotFound повертається, якщо місія не має власного профілю детекції
+var ErrDetectionProfileNotFound = errors.New("detection profile not found")
+
+// DetectionProfileRepository визначає методи для роботи з профілями детекції місій
+type DetectionProfileRepository interface {
+	Save(ctx context.Context, profile *domain.DetectionProfile) error
+	FindByMissionID(ctx context.Context, missionID uuid.UUID) (*domain.DetectionProfile, error)
+	Delete(ctx context.Context, missionID uuid.UUID) error
+}
+
+// SightingRepository визначає методи для роботи з історією спостережень об'єктів
+type SightingRepository interface {
+	Save(ctx context.Context, sighting *domain.Sighting) error
+	FindByDetectedObjectID(ctx context.Context, objectID uuid.UUID) ([]*domain.Sighting, error)
+}
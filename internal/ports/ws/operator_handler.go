@@ -0,0 +1,261 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"log"
+	"mine-detection-system/internal/ports"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// operatorEventBuffer кількість подій, які можуть очікувати відправки одному оператору
+	operatorEventBuffer = 256
+	// operatorPingInterval інтервал ping-повідомлень консолі оператора
+	operatorPingInterval = 30 * time.Second
+	// operatorPongWait час очікування відповіді на ping
+	operatorPongWait = 2 * operatorPingInterval
+	// operatorWriteWait час на запис одного повідомлення
+	operatorWriteWait = 10 * time.Second
+)
+
+// OperatorHandler обробляє WebSocket з'єднання консолей операторів командного пункту.
+// Оператор підписується на місії або сканування і отримує події з шини подій.
+// Події пристроїв не прив'язані до місії, тому надсилаються всім операторам.
+type OperatorHandler struct {
+	eventBus ports.EventBus
+}
+
+// NewOperatorHandler створює новий OperatorHandler
+func NewOperatorHandler(eventBus ports.EventBus) *OperatorHandler {
+	return &OperatorHandler{
+		eventBus: eventBus,
+	}
+}
+
+// operatorSubscriptions набір підписок одного з'єднання
+type operatorSubscriptions struct {
+	mu       sync.RWMutex
+	missions map[uuid.UUID]bool
+	scans    map[uuid.UUID]bool
+}
+
+// matches перевіряє, чи має оператор отримати подію
+func (s *operatorSubscriptions) matches(event ports.Event) bool {
+	if event.MissionID == uuid.Nil && event.ScanID == uuid.Nil {
+		return true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return (event.MissionID != uuid.Nil && s.missions[event.MissionID]) ||
+		(event.ScanID != uuid.Nil && s.scans[event.ScanID])
+}
+
+// HandleConnection оброблює WebSocket з'єднання оператора.
+// Початкові підписки можна передати параметрами mission_id та scan_id (допускаються повтори).
+func (h *OperatorHandler) HandleConnection(w http.ResponseWriter, r *http.Request) {
+	subscriptions := &operatorSubscriptions{
+		missions: make(map[uuid.UUID]bool),
+		scans:    make(map[uuid.UUID]bool),
+	}
+
+	query := r.URL.Query()
+	for key, target := range map[string]map[uuid.UUID]bool{"mission_id": subscriptions.missions, "scan_id": subscriptions.scans} {
+		for _, value := range query[key] {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				http.Error(w, "Invalid "+key, http.StatusBadRequest)
+				return
+			}
+			target[id] = true
+		}
+	}
+
+	// В продакшені тут має бути аутентифікація оператора
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Error upgrading operator connection: %v", err)
+		return
+	}
+
+	events, cancel := h.eventBus.Subscribe(operatorEventBuffer)
+	replies := make(chan interface{}, 16)
+	done := make(chan struct{})
+
+	go h.writeLoop(conn, subscriptions, events, replies, done)
+	go func() {
+		h.readLoop(conn, subscriptions, replies)
+		cancel()
+		close(done)
+	}()
+}
+
+// readLoop обробляє керуючі повідомлення оператора
+func (h *OperatorHandler) readLoop(conn *websocket.Conn, subscriptions *operatorSubscriptions, replies chan<- interface{}) {
+	conn.SetReadDeadline(time.Now().Add(operatorPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(operatorPongWait))
+	})
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Operator WebSocket error: %v", err)
+			}
+			return
+		}
+
+		if messageType != websocket.TextMessage {
+			continue
+		}
+
+		reply := h.handleControlMessage(subscriptions, data)
+		select {
+		case replies <- reply:
+		default:
+			log.Printf("Dropping reply to operator: too many pending replies")
+		}
+	}
+}
+
+// handleControlMessage обробляє subscribe/unsubscribe і повертає відповідь оператору
+func (h *OperatorHandler) handleControlMessage(subscriptions *operatorSubscriptions, data []byte) interface{} {
+	var message map[string]interface{}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return map[string]interface{}{"type": "error", "error": "invalid JSON"}
+	}
+
+	messageType, _ := message["type"].(string)
+	subscribe := messageType == "subscribe"
+	if !subscribe && messageType != "unsubscribe" {
+		return map[string]interface{}{"type": "error", "request": messageType, "error": "unknown message type"}
+	}
+
+	// Усі ідентифікатори перевіряються до зміни підписок, щоб помилка в одному
+	// не залишила запит застосованим частково
+	ids := make(map[string]uuid.UUID, 2)
+	for _, key := range []string{"mission_id", "scan_id"} {
+		if _, present := message[key]; !present {
+			continue
+		}
+
+		id, err := uuidField(message, key)
+		if err != nil {
+			return map[string]interface{}{"type": "error", "request": messageType, "error": err.Error()}
+		}
+		ids[key] = id
+	}
+
+	if len(ids) == 0 {
+		return map[string]interface{}{"type": "error", "request": messageType, "error": "missing mission_id or scan_id"}
+	}
+
+	reply := map[string]interface{}{"type": messageType + "d"}
+	targets := map[string]map[uuid.UUID]bool{"mission_id": subscriptions.missions, "scan_id": subscriptions.scans}
+
+	subscriptions.mu.Lock()
+	defer subscriptions.mu.Unlock()
+
+	for key, id := range ids {
+		if subscribe {
+			targets[key][id] = true
+		} else {
+			delete(targets[key], id)
+		}
+		reply[key] = id
+	}
+
+	return reply
+}
+
+// writeLoop надсилає оператору відповіді та події, що відповідають його підпискам
+func (h *OperatorHandler) writeLoop(conn *websocket.Conn, subscriptions *operatorSubscriptions, events <-chan ports.Event, replies <-chan interface{}, done <-chan struct{}) {
+	ticker := time.NewTicker(operatorPingInterval)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+
+		case reply := <-replies:
+			if err := writeOperatorJSON(conn, reply); err != nil {
+				return
+			}
+
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !subscriptions.matches(event) {
+				continue
+			}
+			if err := writeOperatorJSON(conn, operatorEvent(event)); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(operatorWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// operatorEvent формує повідомлення про подію для консолі оператора
+func operatorEvent(event ports.Event) map[string]interface{} {
+	message := map[string]interface{}{
+		"type":  "event",
+		"event": event.Type,
+		"time":  event.Time,
+		"data":  event.Payload,
+	}
+
+	if event.MissionID != uuid.Nil {
+		message["mission_id"] = event.MissionID
+	}
+	if event.ScanID != uuid.Nil {
+		message["scan_id"] = event.ScanID
+	}
+	if event.DeviceID != uuid.Nil {
+		message["device_id"] = event.DeviceID
+	}
+
+	return message
+}
+
+// writeOperatorJSON записує JSON-повідомлення з обмеженням часу
+func writeOperatorJSON(conn *websocket.Conn, message interface{}) error {
+	conn.SetWriteDeadline(time.Now().Add(operatorWriteWait))
+	if err := conn.WriteJSON(message); err != nil {
+		log.Printf("Error sending message to operator: %v", err)
+		return err
+	}
+	return nil
+}
+
+// uuidField витягує UUID з текстового поля JSON-повідомлення
+func uuidField(message map[string]interface{}, key string) (uuid.UUID, error) {
+	value, ok := message[key].(string)
+	if !ok || value == "" {
+		return uuid.Nil, errors.New("missing " + key)
+	}
+
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, errors.New("invalid " + key)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,96 @@
+package ws
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mine-detection-system/internal/application"
+	"mine-detection-system/pkg/protocol"
+)
+
+// packetReadings перетворює пакет на виміри для SensorFusionService.
+// Кадр PacketTypeBatch містить кілька вимірів зі спільними індикаторами якості.
+func packetReadings(packet *protocol.Packet) ([]application.SensorReading, error) {
+	if packet.Type != protocol.PacketTypeBatch {
+		sensorType, ok := packetSensorType(packet.Type)
+		if !ok {
+			return nil, fmt.Errorf("unknown packet type 0x%02x", packet.Type)
+		}
+		return []application.SensorReading{{
+			SensorType: sensorType,
+			Data:       packet.Payload,
+			Metadata:   packetMetadata(packet.Metadata),
+		}}, nil
+	}
+
+	samples, err := protocol.DecodeBatch(packet)
+	if err != nil {
+		return nil, err
+	}
+
+	readings := make([]application.SensorReading, len(samples))
+	for i, sample := range samples {
+		sensorType, ok := packetSensorType(sample.Type)
+		if !ok {
+			return nil, fmt.Errorf("batch sample %d has unknown packet type 0x%02x", i, sample.Type)
+		}
+		readings[i] = application.SensorReading{
+			SensorType: sensorType,
+			Data:       sample.Payload,
+			Metadata:   packetMetadata(sample.Metadata),
+		}
+	}
+
+	return readings, nil
+}
+
+// packetMetadata перетворює метадані пакета на формат, який очікує SensorFusionService
+func packetMetadata(meta protocol.Metadata) map[string]interface{} {
+	q := meta.Quality
+	quality := make(map[string]interface{})
+	if q.FixQuality != nil {
+		quality["fixQuality"] = *q.FixQuality
+	}
+	if q.HDOP != nil {
+		quality["hdop"] = *q.HDOP
+	}
+	if q.Heading != nil {
+		quality["heading"] = *q.Heading
+	}
+	if q.Battery != nil {
+		quality["battery"] = *q.Battery
+	}
+	if q.Temperature != nil {
+		quality["temperature"] = *q.Temperature
+	}
+	if q.SignalStrength != nil {
+		quality["signalStrength"] = *q.SignalStrength
+	}
+
+	metadata := map[string]interface{}{
+		"latitude":  meta.Latitude,
+		"longitude": meta.Longitude,
+		"altitude":  meta.Altitude,
+		"quality":   quality,
+	}
+	if !meta.DeviceTime.IsZero() {
+		metadata["timestamp"] = meta.DeviceTime
+	}
+
+	return metadata
+}
+
+// packetDigest обчислює контрольну суму вмісту пакета без прапорців доставки
+// і стиснення, тож повторна передача того самого пакета дає ту саму суму
+func packetDigest(packet *protocol.Packet) (string, error) {
+	normalized := *packet
+	normalized.Flags = 0
+
+	data, err := protocol.EncodePacket(&normalized)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
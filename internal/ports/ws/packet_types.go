@@ -0,0 +1,49 @@
+package ws
+
+import (
+	"fmt"
+	"mine-detection-system/pkg/fusion"
+	"mine-detection-system/pkg/protocol"
+	"sync"
+)
+
+// packetTypes зіставляє код типу бінарного пакета з типом сенсора
+var packetTypes = struct {
+	mu      sync.RWMutex
+	sensors map[byte]string
+}{
+	sensors: map[byte]string{
+		protocol.PacketTypeLidar:    fusion.SensorLidar,
+		protocol.PacketTypeMagnetic: fusion.SensorMagnetic,
+		protocol.PacketTypeAcoustic: fusion.SensorAcoustic,
+		protocol.PacketTypeGPR:      fusion.SensorGPR,
+		protocol.PacketTypeThermal:  fusion.SensorThermal,
+	},
+}
+
+// RegisterPacketType додає код типу бінарного пакета для сенсора.
+// Тип сенсора має бути зареєстрований у fusion, щоб його дані можна було декодувати.
+func RegisterPacketType(code byte, sensorType string) error {
+	if !fusion.IsSensorType(sensorType) {
+		return fmt.Errorf("unsupported sensor type %q", sensorType)
+	}
+
+	packetTypes.mu.Lock()
+	defer packetTypes.mu.Unlock()
+
+	if existing, ok := packetTypes.sensors[code]; ok {
+		return fmt.Errorf("packet type 0x%02x is already used by %s", code, existing)
+	}
+
+	packetTypes.sensors[code] = sensorType
+	return nil
+}
+
+// packetSensorType повертає тип сенсора для коду типу пакета
+func packetSensorType(code byte) (string, bool) {
+	packetTypes.mu.RLock()
+	defer packetTypes.mu.RUnlock()
+
+	sensorType, ok := packetTypes.sensors[code]
+	return sensorType, ok
+}
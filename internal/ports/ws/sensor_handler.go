@@ -4,15 +4,30 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 	"log"
 	"mine-detection-system/internal/application"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/ports"
+	"mine-detection-system/pkg/protocol"
 	"net/http"
 	"sync"
 	"time"
 )
 
+// deviceWriteWait час на запис одного повідомлення пристрою
+const deviceWriteWait = 10 * time.Second
+
+const (
+	// maxDeviceMessageSize найбільший розмір повідомлення пристрою; з'єднання,
+	// що надсилає більше, закривається
+	maxDeviceMessageSize = 1 << 20
+	// maxDevicePayloadSize найбільший розмір навантаження пакета після розпакування
+	maxDevicePayloadSize = 4 << 20
+)
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -23,24 +38,58 @@ var upgrader = websocket.Upgrader{
 
 // SensorHandler обробляє WebSocket з'єднання для даних з сенсорів
 type SensorHandler struct {
-	sensorService *application.SensorFusionService
-	deviceService *application.DeviceService
-	connections   map[uuid.UUID]*websocket.Conn
-	connectionsMu sync.Mutex
+	sensorService  *application.SensorFusionService
+	deviceService  *application.DeviceService
+	scanService    *application.ScanService
+	commandService *application.CommandService
+	eventBus       ports.EventBus
+	// requireChecksum відхиляти пакети без CRC-32 (версій 1 і 2)
+	requireChecksum bool
+	connections     map[uuid.UUID]*deviceConnection
+	connectionsMu   sync.Mutex
+}
+
+// deviceConnection з'єднання пристрою. Gorilla WebSocket не допускає
+// одночасного запису, а повідомлення пристрою надсилаються як з циклу
+// обробки, так і з REST-запитів (команди), тому запис серіалізується.
+type deviceConnection struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// close надсилає пристрою кадр закриття з причиною і закриває з'єднання
+func (c *deviceConnection) close(reason string) {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, reason)
+	c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+	c.conn.Close()
 }
 
 // NewSensorHandler створює новий SensorHandler
 func NewSensorHandler(
 	sensorService *application.SensorFusionService,
 	deviceService *application.DeviceService,
+	scanService *application.ScanService,
+	commandService *application.CommandService,
+	eventBus ports.EventBus,
 ) *SensorHandler {
 	return &SensorHandler{
-		sensorService: sensorService,
-		deviceService: deviceService,
-		connections:   make(map[uuid.UUID]*websocket.Conn),
+		sensorService:  sensorService,
+		deviceService:  deviceService,
+		scanService:    scanService,
+		commandService: commandService,
+		eventBus:       eventBus,
+		connections:    make(map[uuid.UUID]*deviceConnection),
 	}
 }
 
+// RequireChecksum вмикає відхилення пакетів версій 1 і 2: вони не містять CRC-32,
+// тож пошкоджені під час передачі виміри були б збережені як справжні.
+// Пакети з порядковим номером з'являються лише у версії 3, тож на відхилений
+// пакет пристрій отримує повідомлення error замість packet_nack.
+func (h *SensorHandler) RequireChecksum(require bool) {
+	h.requireChecksum = require
+}
+
 // HandleConnection оброблює WebSocket з'єднання
 func (h *SensorHandler) HandleConnection(w http.ResponseWriter, r *http.Request) {
 	// Аутентифікація та авторизація
@@ -65,24 +114,61 @@ func (h *SensorHandler) HandleConnection(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Реєстрація з'єднання
+	// Реєстрація з'єднання. Попереднє з'єднання пристрою після перепідключення
+	// закривається, щоб не залишалося двох циклів обробки одного пристрою.
+	connection := &deviceConnection{conn: conn}
 	h.connectionsMu.Lock()
-	h.connections[deviceID] = conn
+	previous := h.connections[deviceID]
+	h.connections[deviceID] = connection
 	h.connectionsMu.Unlock()
 
-	// Запуск горутин для обробки повідомлень
-	go h.handleMessages(ctx, deviceID, conn)
+	if previous != nil {
+		previous.close("replaced by a new connection")
+	}
+
+	h.eventBus.Publish(ports.Event{
+		Type:     ports.EventDeviceConnected,
+		DeviceID: deviceID,
+		Payload:  map[string]interface{}{"device_id": deviceID},
+	})
+
+	// Запуск горутин для обробки повідомлень.
+	// Контекст запиту скасовується після повернення з обробника, тому
+	// для довготривалого з'єднання використовується окремий контекст.
+	go h.handleMessages(context.Background(), deviceID, connection)
+
+	// Конфігурація могла змінитися, поки пристрій був поза мережею
+	if _, err := h.deviceService.SyncConfiguration(context.Background(), deviceID); err != nil {
+		log.Printf("Error pushing configuration to device %s: %v", deviceID, err)
+	}
 }
 
 // handleMessages обробляє повідомлення від пристрою
-func (h *SensorHandler) handleMessages(ctx context.Context, deviceID uuid.UUID, conn *websocket.Conn) {
+func (h *SensorHandler) handleMessages(ctx context.Context, deviceID uuid.UUID, connection *deviceConnection) {
+	conn := connection.conn
+
 	defer func() {
 		conn.Close()
 
+		// Пристрій міг перепідключитися: тоді видаляється лише власне з'єднання,
+		// а пристрій залишається на зв'язку через нове
 		h.connectionsMu.Lock()
-		delete(h.connections, deviceID)
+		current := h.connections[deviceID] == connection
+		if current {
+			delete(h.connections, deviceID)
+		}
 		h.connectionsMu.Unlock()
 
+		if !current {
+			return
+		}
+
+		h.eventBus.Publish(ports.Event{
+			Type:     ports.EventDeviceDisconnected,
+			DeviceID: deviceID,
+			Payload:  map[string]interface{}{"device_id": deviceID},
+		})
+
 		// Оновлення статусу пристрою при від'єднанні
 		err := h.deviceService.UpdateDeviceStatus(context.Background(), deviceID, "inactive")
 		if err != nil {
@@ -90,6 +176,8 @@ func (h *SensorHandler) handleMessages(ctx context.Context, deviceID uuid.UUID,
 		}
 	}()
 
+	conn.SetReadLimit(maxDeviceMessageSize)
+
 	// Налаштування ping/pong для підтримки з'єднання
 	conn.SetPingHandler(func(string) error {
 		if err := conn.WriteControl(websocket.PongMessage, []byte{}, time.Now().Add(time.Second)); err != nil {
@@ -118,80 +206,117 @@ func (h *SensorHandler) handleMessages(ctx context.Context, deviceID uuid.UUID,
 	}
 }
 
-// handleBinaryMessage обробляє бінарні повідомлення з даними сенсорів
+// handleBinaryMessage обробляє бінарні повідомлення з даними сенсорів.
+// На пакети з прапорцем FlagAckRequested пристрій отримує packet_ack після
+// збереження даних або packet_nack з причиною, якщо дані не збережено.
 func (h *SensorHandler) handleBinaryMessage(ctx context.Context, deviceID uuid.UUID, data []byte) {
-	// Розбір заголовка бінарного повідомлення
-	if len(data) < 8 {
-		log.Printf("Invalid binary message format")
+	// Розбір заголовка, метаданих та навантаження пакета з урахуванням версії протоколу
+	packet, err := protocol.DecodePacketLimit(data, maxDevicePayloadSize)
+	if err != nil {
+		log.Printf("Invalid binary message from device %s: %v", deviceID, err)
+		// Пошкоджений під час передачі пакет варто надіслати ще раз, некоректно сформований - ні
+		if scanID, sequence, ok := protocol.PeekAckRequest(data); ok {
+			h.sendPacketNack(deviceID, scanID, sequence, err, errors.Is(err, protocol.ErrChecksumMismatch))
+		}
 		return
 	}
 
-	// Парсинг бінарного заголовка (магічне число, тип пакету тощо)
-	if data[0] != 0xAA || data[1] != 0x55 {
-		log.Printf("Invalid magic number in binary message")
+	if h.requireChecksum && !packet.Verified() {
+		err := fmt.Errorf("%w: protocol version %d, version %d required", protocol.ErrUnverifiedPacket, packet.Version, protocol.ProtocolVersion3)
+		log.Printf("Rejected packet from device %s for scan %s: %v", deviceID, packet.ScanID, err)
+		h.sendError(deviceID, "", err)
 		return
 	}
 
-	// Тип пакету
-	packetType := data[3]
+	// Тип пакету визначає тип сенсора; нові типи додаються через RegisterPacketType.
+	// Кадр містить кілька вимірів і зберігається цілком.
+	readings, err := packetReadings(packet)
+	if err != nil {
+		log.Printf("Invalid packet from device %s: %v", deviceID, err)
+		if packet.AckRequested() {
+			h.sendPacketNack(deviceID, packet.ScanID, packet.Sequence, err, false)
+		}
+		return
+	}
 
-	// Парсинг метаданих та даних залежно від типу пакету
-	switch packetType {
-	case 0x01: // Пакет з даними ЛІДАР
-		scanID, err := extractScanID(data)
-		if err != nil {
-			log.Printf("Error extracting scan ID: %v", err)
-			return
+	// Пакет з порядковим номером зберігається разом з квитанцією, тож повторна
+	// передача не збережеться вдруге навіть після перезапуску сервера
+	if packet.Sequenced() {
+		var digest string
+		if digest, err = packetDigest(packet); err == nil {
+			err = h.scanService.ReceivePacket(ctx, deviceID, packet.ScanID, packet.Sequence, digest, readings)
 		}
+	} else {
+		err = h.sensorService.ProcessSensorBatch(ctx, packet.ScanID, readings)
+	}
 
-		metadata, dataStart := extractMetadata(data)
-		sensorData := data[dataStart:]
+	switch {
+	case err == nil:
 
-		err = h.sensorService.ProcessSensorData(ctx, scanID, "lidar", sensorData, metadata)
-		if err != nil {
-			log.Printf("Error processing LIDAR data: %v", err)
+	case errors.Is(err, ports.ErrDuplicatePacket):
+		// Дублікат уже збережено: підтвердження дозволяє пристрою видалити його з буфера
+		if packet.AckRequested() {
+			h.sendMessage(deviceID, protocol.PacketAck{ScanID: packet.ScanID, Sequence: packet.Sequence, Duplicate: true})
 		}
+		return
 
-	case 0x02: // Пакет з даними магнітометра
-		// Аналогічно обробці ЛІДАР...
-
-	case 0x03: // Пакет з акустичними даними
-		// Аналогічно обробці ЛІДАР...
+	case errors.Is(err, application.ErrStreamingFusion):
+		// Помилка потокового злиття не означає втрати даних: виміри вже збережено
+		log.Printf("Error processing sensor data for scan %s: %v", packet.ScanID, err)
 
 	default:
-		log.Printf("Unknown packet type: %d", packetType)
+		log.Printf("Error processing sensor data for scan %s: %v", packet.ScanID, err)
+		if packet.AckRequested() {
+			// Некоректний пакет і пакет завершеного сканування не приймуться і з повторної спроби
+			rejected := errors.Is(err, protocol.ErrMalformedPayload) || errors.Is(err, protocol.ErrTruncatedPayload) ||
+				errors.Is(err, application.ErrScanNotActive)
+			h.sendPacketNack(deviceID, packet.ScanID, packet.Sequence, err, !rejected)
+		}
+		return
 	}
-}
 
-// handleTextMessage обробляє текстові повідомлення у форматі JSON
-func (h *SensorHandler) handleTextMessage(ctx context.Context, deviceID uuid.UUID, data []byte) {
-	var message map[string]interface{}
-	if err := json.Unmarshal(data, &message); err != nil {
-		log.Printf("Error unmarshaling JSON: %v", err)
-		return
+	if packet.AckRequested() {
+		h.sendMessage(deviceID, protocol.PacketAck{ScanID: packet.ScanID, Sequence: packet.Sequence})
 	}
+}
 
-	messageType, ok := message["type"].(string)
-	if !ok {
-		log.Printf("Missing message type")
+// handleTextMessage обробляє керуючі повідомлення у форматі JSON
+func (h *SensorHandler) handleTextMessage(ctx context.Context, deviceID uuid.UUID, data []byte) {
+	message, err := protocol.DecodeControl(data)
+	if err != nil {
+		// Про помилку в запиті відомого типу повідомляється пристрою
+		var controlErr *protocol.ControlError
+		if errors.As(err, &controlErr) {
+			h.sendError(deviceID, controlErr.Type, controlErr.Err)
+			return
+		}
+		log.Printf("Invalid control message from device %s: %v", deviceID, err)
 		return
 	}
 
-	switch messageType {
-	case "heartbeat":
+	switch message := message.(type) {
+	case *protocol.Heartbeat:
 		// Обробка heartbeat-повідомлень
-		h.handleHeartbeat(ctx, deviceID, message)
+		h.handleHeartbeat(ctx, deviceID)
 
-	case "scan_start":
+	case *protocol.ScanStart:
 		// Обробка початку сканування
 		h.handleScanStart(ctx, deviceID, message)
 
-	case "scan_end":
+	case *protocol.ScanEnd:
 		// Обробка завершення сканування
 		h.handleScanEnd(ctx, deviceID, message)
 
+	case *protocol.CommandAck:
+		// Відповідь пристрою на команду сервера
+		h.handleCommandAck(deviceID, message)
+
+	case *protocol.Resume:
+		// Пристрій перепідключився і з'ясовує, які пакети треба надіслати повторно
+		h.handleResume(ctx, deviceID, message)
+
 	default:
-		log.Printf("Unknown message type: %s", messageType)
+		log.Printf("Unexpected message type from device %s: %s", deviceID, message.MessageType())
 	}
 }
 
@@ -207,76 +332,155 @@ func authenticateDevice(r *http.Request) (uuid.UUID, error) {
 	return uuid.Parse(token)
 }
 
-// extractScanID витягує ID сканування з бінарного пакету
-func extractScanID(data []byte) (uuid.UUID, error) {
-	// Припускаємо, що ID сканування знаходиться у байтах 8-23
-	if len(data) < 24 {
-		return uuid.Nil, errors.New("data too short to contain scan ID")
+// Допоміжні методи для обробки повідомлень
+
+func (h *SensorHandler) handleHeartbeat(ctx context.Context, deviceID uuid.UUID) {
+	// Оновлення останнього з'єднання для пристрою
+	err := h.deviceService.UpdateDeviceStatus(ctx, deviceID, "active")
+	if err != nil {
+		log.Printf("Error updating device status: %v", err)
 	}
 
-	return uuid.FromBytes(data[8:24])
+	// Відправка відповіді на heartbeat
+	h.sendMessage(deviceID, protocol.HeartbeatAck{Time: time.Now().Unix()})
 }
 
-// extractMetadata витягує метадані з бінарного пакету
-func extractMetadata(data []byte) (map[string]interface{}, int) {
-	// Спрощена реалізація для прикладу
-	// В реальному сценарії потрібно розбирати TLV структуру
-	metadata := map[string]interface{}{
-		"latitude":  float64(int32(data[24])<<24|int32(data[25])<<16|int32(data[26])<<8|int32(data[27])) / 1000000.0,
-		"longitude": float64(int32(data[28])<<24|int32(data[29])<<16|int32(data[30])<<8|int32(data[31])) / 1000000.0,
-		"altitude":  float64(int32(data[32])<<24|int32(data[33])<<16|int32(data[34])<<8|int32(data[35])) / 100.0,
-		"quality": map[string]interface{}{
-			"signalStrength": int(data[36]),
-		},
+func (h *SensorHandler) handleScanStart(ctx context.Context, deviceID uuid.UUID, message *protocol.ScanStart) {
+	scan, err := h.scanService.StartScan(ctx, deviceID, message.MissionID, message.ScanType, message.Metadata)
+	if err != nil {
+		log.Printf("Error starting scan for device %s: %v", deviceID, err)
+		h.sendError(deviceID, protocol.MessageScanStart, err)
+		return
 	}
 
-	return metadata, 40 // Повертаємо початок області даних після метаданих
+	h.eventBus.Publish(ports.Event{
+		Type:      ports.EventScanStarted,
+		MissionID: scan.MissionID,
+		ScanID:    scan.ID,
+		DeviceID:  deviceID,
+		Payload:   *scan,
+	})
+
+	// Пристрій використовує отриманий ID у заголовках бінарних пакетів
+	h.sendMessage(deviceID, protocol.ScanStartAck{
+		ScanID:    scan.ID,
+		MissionID: scan.MissionID,
+		Time:      scan.StartTime.Unix(),
+	})
 }
 
-// Допоміжні методи для обробки повідомлень
+func (h *SensorHandler) handleScanEnd(ctx context.Context, deviceID uuid.UUID, message *protocol.ScanEnd) {
+	// Якщо пристрій не вказав статус, вважаємо сканування успішним
+	status := domain.ScanStatusCompleted
+	if message.Status != "" {
+		status = domain.ScanStatus(message.Status)
+	}
 
-func (h *SensorHandler) handleHeartbeat(ctx context.Context, deviceID uuid.UUID, message map[string]interface{}) {
-	// Оновлення останнього з'єднання для пристрою
-	err := h.deviceService.UpdateDeviceStatus(ctx, deviceID, "active")
+	scan, detections, err := h.scanService.EndScan(ctx, deviceID, message.ScanID, status)
 	if err != nil {
-		log.Printf("Error updating device status: %v", err)
+		log.Printf("Error ending scan %s: %v", message.ScanID, err)
+		// Сканування могло бути закрите навіть якщо злиття даних завершилося помилкою
+		if scan == nil {
+			h.sendError(deviceID, protocol.MessageScanEnd, err)
+			return
+		}
 	}
 
-	// Відправка відповіді на heartbeat
-	response := map[string]interface{}{
-		"type": "heartbeat_ack",
-		"time": time.Now().Unix(),
+	h.eventBus.Publish(ports.Event{
+		Type:      ports.EventScanEnded,
+		MissionID: scan.MissionID,
+		ScanID:    scan.ID,
+		DeviceID:  deviceID,
+		Payload: map[string]interface{}{
+			"scan":       *scan,
+			"detections": len(detections),
+		},
+	})
+
+	h.sendMessage(deviceID, protocol.ScanEndAck{
+		ScanID:     scan.ID,
+		Status:     string(scan.Status),
+		Detections: len(detections),
+	})
+}
+
+func (h *SensorHandler) handleCommandAck(deviceID uuid.UUID, message *protocol.CommandAck) {
+	if err := h.commandService.HandleAck(deviceID, message.CommandID, message.Status, message.Error); err != nil {
+		log.Printf("Error handling command ack from device %s: %v", deviceID, err)
+		h.sendError(deviceID, protocol.MessageCommandAck, err)
+	}
+}
+
+func (h *SensorHandler) handleResume(ctx context.Context, deviceID uuid.UUID, message *protocol.Resume) {
+	point, err := h.scanService.PacketResumePoint(ctx, deviceID, message.ScanID, message.Reset)
+	if err != nil {
+		h.sendError(deviceID, protocol.MessageResume, err)
+		return
 	}
 
-	h.sendMessage(deviceID, response)
+	h.sendMessage(deviceID, protocol.ResumeAck{
+		ScanID:       message.ScanID,
+		LastSequence: point.LastSequence,
+		Missing:      point.Missing,
+	})
 }
 
-func (h *SensorHandler) handleScanStart(ctx context.Context, deviceID uuid.UUID, message map[string]interface{}) {
-	// Логіка обробки початку сканування...
+// sendPacketNack повідомляє пристрій, що пакет не збережено
+func (h *SensorHandler) sendPacketNack(deviceID, scanID uuid.UUID, sequence uint32, err error, retry bool) {
+	h.sendMessage(deviceID, protocol.PacketNack{
+		ScanID:   scanID,
+		Sequence: sequence,
+		Reason:   err.Error(),
+		Retry:    retry,
+	})
 }
 
-func (h *SensorHandler) handleScanEnd(ctx context.Context, deviceID uuid.UUID, message map[string]interface{}) {
-	// Логіка обробки завершення сканування...
+// sendError повідомляє пристрій про помилку обробки його запиту
+func (h *SensorHandler) sendError(deviceID uuid.UUID, request string, err error) {
+	h.sendMessage(deviceID, protocol.ErrorMessage{Request: request, Error: err.Error()})
 }
 
 // sendMessage відправляє повідомлення пристрою
 func (h *SensorHandler) sendMessage(deviceID uuid.UUID, message interface{}) {
+	if err := h.Send(deviceID, message); err != nil {
+		log.Printf("Error sending message to device %s: %v", deviceID, err)
+	}
+}
+
+// Send надсилає JSON-повідомлення підключеному пристрою.
+// Керуючі повідомлення протоколу серіалізуються разом із полем type.
+func (h *SensorHandler) Send(deviceID uuid.UUID, message interface{}) error {
 	h.connectionsMu.Lock()
-	conn, exists := h.connections[deviceID]
+	connection, exists := h.connections[deviceID]
 	h.connectionsMu.Unlock()
 
 	if !exists {
-		log.Printf("Device %s not connected", deviceID)
-		return
+		return errors.New("device not connected")
 	}
 
-	data, err := json.Marshal(message)
+	var data []byte
+	var err error
+	if control, ok := message.(protocol.ControlMessage); ok {
+		data, err = protocol.EncodeControl(control)
+	} else {
+		data, err = json.Marshal(message)
+	}
 	if err != nil {
-		log.Printf("Error marshaling message: %v", err)
-		return
+		return err
 	}
 
-	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		log.Printf("Error sending message: %v", err)
-	}
+	connection.writeMu.Lock()
+	defer connection.writeMu.Unlock()
+
+	connection.conn.SetWriteDeadline(time.Now().Add(deviceWriteWait))
+	return connection.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// IsConnected повідомляє, чи має пристрій активне з'єднання
+func (h *SensorHandler) IsConnected(deviceID uuid.UUID) bool {
+	h.connectionsMu.Lock()
+	defer h.connectionsMu.Unlock()
+
+	_, exists := h.connections[deviceID]
+	return exists
 }
@@ -0,0 +1,113 @@
+package ws
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"mine-detection-system/internal/application"
+	"mine-detection-system/internal/domain"
+	"mine-detection-system/internal/infrastructure/events"
+	"mine-detection-system/internal/infrastructure/memory"
+	"mine-detection-system/pkg/fusion"
+	"mine-detection-system/pkg/protocol"
+	"testing"
+	"time"
+)
+
+// newTestSensorHandler створює обробник поверх сховищ у пам'яті та запускає сканування
+func newTestSensorHandler(t *testing.T) (*SensorHandler, *memory.SensorDataRepository, uuid.UUID, *domain.Scan) {
+	t.Helper()
+	ctx := context.Background()
+
+	devices := memory.NewDeviceRepository()
+	missions := memory.NewMissionRepository()
+	scans := memory.NewScanRepository()
+	sensorData := memory.NewSensorDataRepository()
+	bus := events.NewBus()
+
+	profiles := application.NewDetectionProfileService(memory.NewDetectionProfileRepository(), missions, fusion.DefaultClassifier())
+	sensorService := application.NewSensorFusionService(sensorData, memory.NewDetectedObjectRepository(), scans,
+		memory.NewSightingRepository(), profiles, bus)
+	scanService := application.NewScanService(scans, devices, missions, sensorData, sensorService)
+	commandService := application.NewCommandService(devices, bus)
+	deviceService := application.NewDeviceService(devices, bus, commandService)
+
+	device := &domain.Device{ID: uuid.New(), DeviceType: "multi", SerialNumber: "SN-1", Status: domain.DeviceStatusActive}
+	if err := devices.Save(ctx, device); err != nil {
+		t.Fatalf("save device: %v", err)
+	}
+	mission := &domain.Mission{ID: uuid.New(), Name: "test", Status: domain.MissionStatusActive, StartDate: time.Now()}
+	if err := missions.Save(ctx, mission); err != nil {
+		t.Fatalf("save mission: %v", err)
+	}
+	scan, err := scanService.StartScan(ctx, device.ID, mission.ID, "lane", nil)
+	if err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+
+	handler := NewSensorHandler(sensorService, deviceService, scanService, commandService, bus)
+	return handler, sensorData, device.ID, scan
+}
+
+// encodeMagneticPacket формує пакет заданої версії з одним виміром магнітометра
+func encodeMagneticPacket(t *testing.T, version byte, scanID uuid.UUID) []byte {
+	t.Helper()
+
+	payload, err := protocol.EncodeMagnetic(&protocol.MagneticVector{
+		SampleRate: 10,
+		Readings:   []protocol.MagneticReading{{X: 48000, Y: 100, Z: -200}},
+	})
+	if err != nil {
+		t.Fatalf("EncodeMagnetic: %v", err)
+	}
+
+	packet := &protocol.Packet{
+		Version:  version,
+		Type:     protocol.PacketTypeMagnetic,
+		ScanID:   scanID,
+		Metadata: protocol.Metadata{Latitude: 50.4501, Longitude: 30.5234, Altitude: 123.45},
+		Payload:  payload,
+	}
+	if version >= protocol.ProtocolVersion3 {
+		packet.Sequence = 1
+		packet.Flags = protocol.FlagAckRequested
+	}
+
+	data, err := protocol.EncodePacket(packet)
+	if err != nil {
+		t.Fatalf("EncodePacket: %v", err)
+	}
+	return data
+}
+
+func TestHandleBinaryMessageRequireChecksum(t *testing.T) {
+	tests := []struct {
+		name            string
+		requireChecksum bool
+		version         byte
+		wantStored      bool
+	}{
+		{name: "legacy accepted by default", version: protocol.ProtocolVersionLegacy, wantStored: true},
+		{name: "version 2 accepted by default", version: protocol.ProtocolVersion2, wantStored: true},
+		{name: "version 3 accepted by default", version: protocol.ProtocolVersion3, wantStored: true},
+		{name: "legacy rejected without checksum", requireChecksum: true, version: protocol.ProtocolVersionLegacy},
+		{name: "version 2 rejected without checksum", requireChecksum: true, version: protocol.ProtocolVersion2},
+		{name: "version 3 accepted with checksum", requireChecksum: true, version: protocol.ProtocolVersion3, wantStored: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, sensorData, deviceID, scan := newTestSensorHandler(t)
+			handler.RequireChecksum(tt.requireChecksum)
+
+			handler.handleBinaryMessage(context.Background(), deviceID, encodeMagneticPacket(t, tt.version, scan.ID))
+
+			stored, err := sensorData.FindByScanID(context.Background(), scan.ID, 10, 0)
+			if err != nil {
+				t.Fatalf("FindByScanID: %v", err)
+			}
+			if got := len(stored) > 0; got != tt.wantStored {
+				t.Errorf("stored = %v, want %v", got, tt.wantStored)
+			}
+		})
+	}
+}
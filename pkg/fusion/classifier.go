@@ -0,0 +1,368 @@
+package fusion
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+)
+
+//go:embed models/default_classifier.json
+var defaultClassifierModel []byte
+
+// probabilityTolerance допустима похибка суми ймовірностей у таблицях моделі
+const probabilityTolerance = 1e-6
+
+// ObjectClass описує клас об'єкта, який розрізняє класифікатор
+type ObjectClass struct {
+	Name string `json:"name"`
+	// Hazardous true для вибухонебезпечних класів, що підтримують гіпотезу "міна"
+	Hazardous bool `json:"hazardous"`
+	// DangerLevel рівень небезпеки 1..5; 0 (не задано) означає, що він залежить від
+	// умов району і береться з DefaultDangerLevel налаштувань детектора
+	DangerLevel int `json:"danger_level,omitempty"`
+	// TypicalDepth типова глибина залягання, м
+	TypicalDepth float64 `json:"typical_depth"`
+}
+
+// FeatureNode дискретизований вузол ознаки сенсора.
+// Bins задає зростаючі межі інтервалів, тож вузол має len(Bins)+1 станів,
+// а CPT містить для кожного класу розподіл P(стан | клас).
+type FeatureNode struct {
+	Sensor string               `json:"sensor"`
+	Bins   []float64            `json:"bins"`
+	CPT    map[string][]float64 `json:"cpt"`
+}
+
+// ClassifierModel структура байєсівської мережі та її таблиці умовних ймовірностей:
+// тип ґрунту -> клас об'єкта -> ознаки сенсорів
+type ClassifierModel struct {
+	Name           string                        `json:"name"`
+	SoilPrior      map[string]float64            `json:"soil_prior"`
+	Classes        []ObjectClass                 `json:"classes"`
+	ClassGivenSoil map[string]map[string]float64 `json:"class_given_soil"`
+	Features       []FeatureNode                 `json:"features"`
+}
+
+// Classifier байєсівський класифікатор об'єктів у комірці сітки
+type Classifier struct {
+	model    ClassifierModel
+	features map[string]FeatureNode
+}
+
+// DefaultClassifier повертає класифікатор з вбудованою моделлю за замовчуванням
+func DefaultClassifier() *Classifier {
+	classifier, err := ParseClassifier(defaultClassifierModel)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded classifier model: %v", err))
+	}
+	return classifier
+}
+
+// LoadClassifier завантажує модель класифікатора з JSON-файлу
+func LoadClassifier(path string) (*Classifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	classifier, err := ParseClassifier(data)
+	if err != nil {
+		return nil, fmt.Errorf("classifier model %s: %w", path, err)
+	}
+
+	return classifier, nil
+}
+
+// ParseClassifier розбирає та перевіряє JSON-модель класифікатора
+func ParseClassifier(data []byte) (*Classifier, error) {
+	var model ClassifierModel
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, err
+	}
+
+	return NewClassifier(model)
+}
+
+// NewClassifier створює класифікатор з моделі після перевірки її узгодженості
+func NewClassifier(model ClassifierModel) (*Classifier, error) {
+	if err := model.validate(); err != nil {
+		return nil, err
+	}
+
+	features := make(map[string]FeatureNode, len(model.Features))
+	for _, feature := range model.Features {
+		features[feature.Sensor] = feature
+	}
+
+	return &Classifier{model: model, features: features}, nil
+}
+
+// Model повертає модель класифікатора
+func (c *Classifier) Model() ClassifierModel {
+	return c.model
+}
+
+// HasSoilType перевіряє, чи відомий моделі тип ґрунту
+func (c *Classifier) HasSoilType(soil string) bool {
+	_, ok := c.model.SoilPrior[soil]
+	return ok
+}
+
+// Posterior обчислює апостеріорний розподіл класів за оцінками сенсорів.
+// Якщо тип ґрунту порожній, він маргіналізується за апріорним розподілом.
+// Оцінки сенсорів, яких немає в моделі, ігноруються.
+func (c *Classifier) Posterior(estimates map[string]SensorEstimate, soil string) map[string]float64 {
+	likelihoods := make(map[string]float64, len(c.model.Classes))
+	for _, class := range c.model.Classes {
+		likelihood := 1.0
+		for sensorType, estimate := range estimates {
+			if feature, ok := c.features[sensorType]; ok {
+				likelihood *= feature.likelihood(class.Name, estimate)
+			}
+		}
+		likelihoods[class.Name] = likelihood
+	}
+
+	posterior := make(map[string]float64, len(c.model.Classes))
+	total := 0.0
+	for soilType, soilPrior := range c.model.SoilPrior {
+		if soil != "" && soilType != soil {
+			continue
+		}
+		for className, likelihood := range likelihoods {
+			joint := soilPrior * c.model.ClassGivenSoil[soilType][className] * likelihood
+			posterior[className] += joint
+			total += joint
+		}
+	}
+
+	if total == 0 {
+		// Докази несумісні з моделлю: повертаємо рівномірний розподіл
+		for _, class := range c.model.Classes {
+			posterior[class.Name] = 1 / float64(len(c.model.Classes))
+		}
+		return posterior
+	}
+
+	for className := range posterior {
+		posterior[className] /= total
+	}
+
+	return posterior
+}
+
+// HazardProbability обчислює ймовірність вибухонебезпечного об'єкта за доказами
+// одного сенсора без урахування апріорних ймовірностей класів між групами.
+// Неінформативний доказ дає 0.5, тож результат придатний як маса сенсора
+// для комбінування за Демпстером-Шефером. Друге значення false, якщо сенсора немає в моделі.
+func (c *Classifier) HazardProbability(sensorType string, estimate SensorEstimate, soil string) (float64, bool) {
+	feature, ok := c.features[sensorType]
+	if !ok {
+		return 0, false
+	}
+
+	prior := c.classPrior(soil)
+	hazard, hazardWeight, clutter, clutterWeight := 0.0, 0.0, 0.0, 0.0
+	for _, class := range c.model.Classes {
+		likelihood := prior[class.Name] * feature.likelihood(class.Name, estimate)
+		if class.Hazardous {
+			hazard += likelihood
+			hazardWeight += prior[class.Name]
+		} else {
+			clutter += likelihood
+			clutterWeight += prior[class.Name]
+		}
+	}
+
+	if hazardWeight == 0 || clutterWeight == 0 {
+		return 0.5, true
+	}
+
+	// Правдоподібність доказу за кожною з гіпотез
+	hazard /= hazardWeight
+	clutter /= clutterWeight
+	if hazard+clutter == 0 {
+		return 0.5, true
+	}
+
+	return hazard / (hazard + clutter), true
+}
+
+// MostLikely повертає найімовірніший клас апостеріорного розподілу
+func (c *Classifier) MostLikely(posterior map[string]float64) ObjectClass {
+	best := c.model.Classes[0]
+	for _, class := range c.model.Classes[1:] {
+		if posterior[class.Name] > posterior[best.Name] {
+			best = class
+		}
+	}
+	return best
+}
+
+// classPrior обчислює апріорний розподіл класів для типу ґрунту
+func (c *Classifier) classPrior(soil string) map[string]float64 {
+	prior := make(map[string]float64, len(c.model.Classes))
+	for soilType, soilPrior := range c.model.SoilPrior {
+		if soil != "" && soilType != soil {
+			continue
+		}
+		for className, probability := range c.model.ClassGivenSoil[soilType] {
+			prior[className] += soilPrior * probability
+		}
+	}
+	return prior
+}
+
+// likelihood обчислює P(оцінка | клас) як м'який доказ: стан вузла зважується
+// ймовірністю потрапляння в інтервал за гаусовим розподілом N(Value, Covariance)
+func (f FeatureNode) likelihood(className string, estimate SensorEstimate) float64 {
+	table := f.CPT[className]
+	weights := f.binWeights(estimate)
+
+	likelihood := 0.0
+	for i, weight := range weights {
+		likelihood += weight * table[i]
+	}
+	return likelihood
+}
+
+// binWeights розподіляє оцінку ознаки між інтервалами вузла
+func (f FeatureNode) binWeights(estimate SensorEstimate) []float64 {
+	weights := make([]float64, len(f.Bins)+1)
+
+	deviation := math.Sqrt(estimate.Covariance)
+	if deviation == 0 || math.IsNaN(deviation) {
+		weights[sort.SearchFloat64s(f.Bins, estimate.Value)] = 1
+		return weights
+	}
+
+	cdf := func(x float64) float64 {
+		return 0.5 * (1 + math.Erf((x-estimate.Value)/(deviation*math.Sqrt2)))
+	}
+
+	previous := 0.0
+	for i, edge := range f.Bins {
+		current := cdf(edge)
+		weights[i] = current - previous
+		previous = current
+	}
+	weights[len(f.Bins)] = 1 - previous
+
+	return weights
+}
+
+// validate перевіряє структуру моделі та нормованість таблиць ймовірностей
+func (m ClassifierModel) validate() error {
+	if len(m.Classes) == 0 {
+		return errors.New("model defines no object classes")
+	}
+
+	if err := validateDistribution("soil prior", m.SoilPrior); err != nil {
+		return err
+	}
+
+	classes := make(map[string]bool, len(m.Classes))
+	for _, class := range m.Classes {
+		if class.Name == "" {
+			return errors.New("object class name is required")
+		}
+		if classes[class.Name] {
+			return fmt.Errorf("duplicate object class %q", class.Name)
+		}
+		if class.DangerLevel < 0 || class.DangerLevel > 5 {
+			return fmt.Errorf("object class %q has danger level %d outside 0..5", class.Name, class.DangerLevel)
+		}
+		if class.TypicalDepth < 0 {
+			return fmt.Errorf("object class %q has negative typical depth", class.Name)
+		}
+		classes[class.Name] = true
+	}
+
+	for soil := range m.SoilPrior {
+		distribution, ok := m.ClassGivenSoil[soil]
+		if !ok {
+			return fmt.Errorf("class distribution for soil %q is missing", soil)
+		}
+		if err := validateClassDistribution("class given soil "+soil, distribution, classes); err != nil {
+			return err
+		}
+	}
+
+	seen := make(map[string]bool, len(m.Features))
+	for _, feature := range m.Features {
+		if !IsSensorType(feature.Sensor) {
+			return fmt.Errorf("feature node references unsupported sensor %q", feature.Sensor)
+		}
+		if seen[feature.Sensor] {
+			return fmt.Errorf("duplicate feature node for sensor %q", feature.Sensor)
+		}
+		seen[feature.Sensor] = true
+
+		if !sort.Float64sAreSorted(feature.Bins) {
+			return fmt.Errorf("bins of feature %q must be ascending", feature.Sensor)
+		}
+
+		for class := range classes {
+			table, ok := feature.CPT[class]
+			if !ok {
+				return fmt.Errorf("feature %q has no CPT row for class %q", feature.Sensor, class)
+			}
+			if len(table) != len(feature.Bins)+1 {
+				return fmt.Errorf("feature %q CPT row for class %q has %d states, need %d", feature.Sensor, class, len(table), len(feature.Bins)+1)
+			}
+			if err := validateRow(fmt.Sprintf("feature %q CPT row for class %q", feature.Sensor, class), table); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateClassDistribution перевіряє розподіл класів на повноту та нормованість
+func validateClassDistribution(name string, distribution map[string]float64, classes map[string]bool) error {
+	for class := range distribution {
+		if !classes[class] {
+			return fmt.Errorf("%s references unknown class %q", name, class)
+		}
+	}
+	for class := range classes {
+		if _, ok := distribution[class]; !ok {
+			return fmt.Errorf("%s has no probability for class %q", name, class)
+		}
+	}
+	return validateDistribution(name, distribution)
+}
+
+// validateDistribution перевіряє, що ймовірності лежать у [0, 1] і дають у сумі 1
+func validateDistribution(name string, distribution map[string]float64) error {
+	if len(distribution) == 0 {
+		return fmt.Errorf("%s is empty", name)
+	}
+
+	values := make([]float64, 0, len(distribution))
+	for _, value := range distribution {
+		values = append(values, value)
+	}
+	return validateRow(name, values)
+}
+
+// validateRow перевіряє рядок таблиці ймовірностей
+func validateRow(name string, values []float64) error {
+	sum := 0.0
+	for _, value := range values {
+		if err := validateProbability(name, value); err != nil {
+			return err
+		}
+		sum += value
+	}
+
+	if math.Abs(sum-1) > probabilityTolerance {
+		return fmt.Errorf("%s sums to %v, not 1", name, sum)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package fusion
+
+import (
+	"math"
+	"mine-detection-system/pkg/geo"
+	"sort"
+)
+
+// Extent описує розміри кластера підозрілих комірок, м
+type Extent struct {
+	Length float64 `json:"length"`
+	Width  float64 `json:"width"`
+	Area   float64 `json:"area"`
+}
+
+// clusterCells об'єднує комірки з імовірністю міни не нижче порогу у зв'язні компоненти.
+// Сусідніми вважаються комірки, що мають спільну сторону або кут, тож один великий
+// об'єкт, який займає кілька комірок, дає один кластер. Порядок кластерів і комірок
+// у них детермінований.
+func (d *Detector) clusterCells(grid map[CellKey]*Classification) [][]*Classification {
+	suspicious := make(map[CellKey]*Classification)
+	for key, classification := range grid {
+		if classification.MineProbability >= d.confidenceThreshold {
+			suspicious[key] = classification
+		}
+	}
+
+	keys := make([]CellKey, 0, len(suspicious))
+	for key := range suspicious {
+		keys = append(keys, key)
+	}
+	sortCellKeys(keys)
+
+	visited := make(map[CellKey]bool, len(suspicious))
+	var clusters [][]*Classification
+
+	for _, start := range keys {
+		if visited[start] {
+			continue
+		}
+
+		// Обхід компоненти в ширину
+		var cluster []*Classification
+		queue := []CellKey{start}
+		visited[start] = true
+		for len(queue) > 0 {
+			key := queue[0]
+			queue = queue[1:]
+			cluster = append(cluster, suspicious[key])
+
+			for dRow := -1; dRow <= 1; dRow++ {
+				for dCol := -1; dCol <= 1; dCol++ {
+					neighbour := CellKey{Row: key.Row + dRow, Col: key.Col + dCol}
+					if _, ok := suspicious[neighbour]; ok && !visited[neighbour] {
+						visited[neighbour] = true
+						queue = append(queue, neighbour)
+					}
+				}
+			}
+		}
+
+		sort.Slice(cluster, func(i, j int) bool {
+			return cellKeyLess(cluster[i].Cell.Key, cluster[j].Cell.Key)
+		})
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters
+}
+
+// clusterDetection зводить кластер комірок до однієї детекції.
+// Координати - центроїд центрів комірок, зважений імовірністю міни; докази
+// беруться з найпевнішої комірки, а клас - з апостеріорних розподілів комірок,
+// зважених тією ж імовірністю.
+func (d *Detector) clusterDetection(projection geo.Grid, cluster []*Classification) Detection {
+	peak := cluster[0]
+	weightSum, latSum, lonSum := 0.0, 0.0, 0.0
+	posterior := make(map[string]float64)
+	minRow, maxRow := cluster[0].Cell.Key.Row, cluster[0].Cell.Key.Row
+	minCol, maxCol := cluster[0].Cell.Key.Col, cluster[0].Cell.Key.Col
+	corners := make([]geo.Point, 0, 4*len(cluster))
+
+	for _, classification := range cluster {
+		if classification.MineProbability > peak.MineProbability {
+			peak = classification
+		}
+
+		weight := classification.MineProbability
+		weightSum += weight
+		latSum += weight * classification.Cell.Latitude
+		lonSum += weight * classification.Cell.Longitude
+		for className, probability := range classification.Posterior {
+			posterior[className] += weight * probability
+		}
+
+		key := classification.Cell.Key
+		minRow, maxRow = minInt(minRow, key.Row), maxInt(maxRow, key.Row)
+		minCol, maxCol = minInt(minCol, key.Col), maxInt(maxCol, key.Col)
+
+		minLat, minLon, maxLat, maxLon := projection.Bounds(key.Row, key.Col)
+		corners = append(corners,
+			geo.Point{Lat: minLat, Lon: minLon},
+			geo.Point{Lat: minLat, Lon: maxLon},
+			geo.Point{Lat: maxLat, Lon: maxLon},
+			geo.Point{Lat: maxLat, Lon: minLon},
+		)
+	}
+
+	for className := range posterior {
+		posterior[className] /= weightSum
+	}
+
+	cellSize := projection.CellSize
+	rows := float64(maxRow-minRow+1) * cellSize
+	cols := float64(maxCol-minCol+1) * cellSize
+
+	detection := Detection{
+		Latitude:     latSum / weightSum,
+		Longitude:    lonSum / weightSum,
+		Confidence:   peak.MineProbability,
+		Belief:       peak.Evidence.Mass.Belief(),
+		Plausibility: peak.Evidence.Mass.Plausibility(),
+		Conflict:     peak.Evidence.Conflict,
+		Posterior:    posterior,
+		Cells:        len(cluster),
+		Extent: Extent{
+			Length: math.Max(rows, cols),
+			Width:  math.Min(rows, cols),
+			Area:   float64(len(cluster)) * cellSize * cellSize,
+		},
+	}
+
+	for _, point := range geo.ConvexHull(corners) {
+		detection.Footprint = append(detection.Footprint, []float64{point.Lon, point.Lat})
+	}
+
+	class := d.classifier.MostLikely(posterior)
+	detection.ObjectType = class.Name
+	detection.DangerLevel = d.dangerLevel(class)
+
+	// Додавання глибини відповідно до політики профілю
+	if d.depthPolicy == DepthPolicyClass {
+		detection.Depth = class.TypicalDepth
+	} else {
+		detection.Depth = d.defaultDepth
+	}
+
+	return detection
+}
+
+// dangerLevel повертає рівень небезпеки класу або значення з налаштувань детектора,
+// якщо модель класифікатора його не задає
+func (d *Detector) dangerLevel(class ObjectClass) int {
+	if class.DangerLevel == 0 {
+		return d.defaultDangerLevel
+	}
+	return class.DangerLevel
+}
+
+// sortCellKeys впорядковує ключі комірок за рядком, потім за стовпцем
+func sortCellKeys(keys []CellKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		return cellKeyLess(keys[i], keys[j])
+	})
+}
+
+func cellKeyLess(a, b CellKey) bool {
+	if a.Row != b.Row {
+		return a.Row < b.Row
+	}
+	return a.Col < b.Col
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
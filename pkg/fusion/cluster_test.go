@@ -0,0 +1,181 @@
+package fusion
+
+import (
+	"mine-detection-system/pkg/geo"
+	"reflect"
+	"testing"
+)
+
+// classifiedCells будує класифіковану сітку: комірки з keys отримують високу
+// імовірність міни, з background - низьку
+func classifiedCells(t *testing.T, projection geo.Grid, keys []CellKey, background ...CellKey) map[CellKey]*Classification {
+	t.Helper()
+
+	grid := &SpatialGrid{Projection: projection, Cells: make(map[CellKey]*GridCell)}
+	classified := make(map[CellKey]*Classification)
+
+	add := func(key CellKey, probability float64) {
+		lat, lon := projection.Center(key.Row, key.Col)
+		cell := grid.cell(lat, lon)
+		if cell.Key != key {
+			t.Fatalf("centre of cell %+v maps to cell %+v", key, cell.Key)
+		}
+		classified[key] = &Classification{Cell: cell, MineProbability: probability}
+	}
+	for _, key := range keys {
+		add(key, 0.9)
+	}
+	for _, key := range background {
+		add(key, 0.1)
+	}
+	return classified
+}
+
+// clusterKeys повертає ключі комірок кожного кластера
+func clusterKeys(clusters [][]*Classification) [][]CellKey {
+	result := make([][]CellKey, len(clusters))
+	for i, cluster := range clusters {
+		for _, classification := range cluster {
+			result[i] = append(result[i], classification.Cell.Key)
+		}
+	}
+	return result
+}
+
+func TestClusterCells(t *testing.T) {
+	projection := geo.NewGrid(DefaultGridCellSize, 50.4501)
+
+	tests := []struct {
+		name       string
+		cells      []CellKey
+		background []CellKey
+		want       [][]CellKey
+	}{
+		{
+			name: "no suspicious cells",
+			background: []CellKey{
+				{Row: 0, Col: 0}, {Row: 0, Col: 1},
+			},
+			want: [][]CellKey{},
+		},
+		{
+			name:  "side neighbours merge",
+			cells: []CellKey{{Row: 0, Col: 1}, {Row: 0, Col: 0}, {Row: 1, Col: 0}},
+			want:  [][]CellKey{{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 0}}},
+		},
+		{
+			// Об'єкт, що лежить навскіс до сітки, займає комірки, які торкаються лише кутами
+			name:  "diagonal neighbours merge",
+			cells: []CellKey{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 2}, {Row: 3, Col: 1}},
+			want:  [][]CellKey{{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 2}, {Row: 3, Col: 1}}},
+		},
+		{
+			name:  "anti-diagonal across negative indices",
+			cells: []CellKey{{Row: -1, Col: 0}, {Row: 0, Col: -1}},
+			want:  [][]CellKey{{{Row: -1, Col: 0}, {Row: 0, Col: -1}}},
+		},
+		{
+			name:  "one empty cell keeps blobs apart",
+			cells: []CellKey{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 0, Col: 3}, {Row: 2, Col: 0}},
+			want: [][]CellKey{
+				{{Row: 0, Col: 0}, {Row: 0, Col: 1}},
+				{{Row: 0, Col: 3}},
+				{{Row: 2, Col: 0}},
+			},
+		},
+		{
+			name:       "cells below threshold do not bridge blobs",
+			cells:      []CellKey{{Row: 0, Col: 0}, {Row: 0, Col: 2}},
+			background: []CellKey{{Row: 0, Col: 1}},
+			want:       [][]CellKey{{{Row: 0, Col: 0}}, {{Row: 0, Col: 2}}},
+		},
+		{
+			// Компонента, що згинається, не розпадається на частини за порядком обходу
+			name: "U-shaped blob",
+			cells: []CellKey{
+				{Row: 0, Col: 0}, {Row: 0, Col: 2},
+				{Row: 1, Col: 0}, {Row: 1, Col: 2},
+				{Row: 2, Col: 0}, {Row: 2, Col: 1}, {Row: 2, Col: 2},
+			},
+			want: [][]CellKey{{
+				{Row: 0, Col: 0}, {Row: 0, Col: 2},
+				{Row: 1, Col: 0}, {Row: 1, Col: 2},
+				{Row: 2, Col: 0}, {Row: 2, Col: 1}, {Row: 2, Col: 2},
+			}},
+		},
+	}
+
+	detector := NewDetector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := clusterKeys(detector.clusterCells(classifiedCells(t, projection, tt.cells, tt.background...)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clusters = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterDetectionFootprint(t *testing.T) {
+	projection := geo.NewGrid(DefaultGridCellSize, -33.8688)
+
+	tests := []struct {
+		name        string
+		cells       []CellKey
+		wantLength  float64
+		wantWidth   float64
+		wantCorners int
+	}{
+		{name: "single cell", cells: []CellKey{{Row: -4, Col: 7}}, wantLength: 0.5, wantWidth: 0.5, wantCorners: 4},
+		{name: "collinear row", cells: []CellKey{{Row: 0, Col: 0}, {Row: 0, Col: 1}, {Row: 0, Col: 2}}, wantLength: 1.5, wantWidth: 0.5, wantCorners: 4},
+		{name: "collinear column", cells: []CellKey{{Row: -2, Col: -1}, {Row: -1, Col: -1}}, wantLength: 1, wantWidth: 0.5, wantCorners: 4},
+		{name: "diagonal", cells: []CellKey{{Row: 0, Col: 0}, {Row: 1, Col: 1}, {Row: 2, Col: 2}}, wantLength: 1.5, wantWidth: 1.5, wantCorners: 6},
+	}
+
+	detector := NewDetector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusters := detector.clusterCells(classifiedCells(t, projection, tt.cells))
+			if len(clusters) != 1 {
+				t.Fatalf("got %d clusters, want 1", len(clusters))
+			}
+			detection := detector.clusterDetection(projection, clusters[0])
+
+			if detection.Cells != len(tt.cells) {
+				t.Errorf("cells = %d, want %d", detection.Cells, len(tt.cells))
+			}
+			if !approxEqual(detection.Extent.Length, tt.wantLength) || !approxEqual(detection.Extent.Width, tt.wantWidth) {
+				t.Errorf("extent = %+v, want %v x %v", detection.Extent, tt.wantLength, tt.wantWidth)
+			}
+
+			// Контур охоплює комірки повністю, тож навіть одна комірка чи лінія комірок
+			// дає невироджений полігон, а не точку чи відрізок
+			if n := len(detection.Footprint) - 1; n != tt.wantCorners {
+				t.Errorf("footprint has %d corners, want %d: %v", n, tt.wantCorners, detection.Footprint)
+			}
+			geometry := map[string]interface{}{
+				"type":        "Polygon",
+				"coordinates": [][][]float64{detection.Footprint},
+			}
+			if err := geo.ValidatePolygon(geometry); err != nil {
+				t.Errorf("footprint is not a valid polygon: %v", err)
+			}
+
+			// Центр детекції лежить усередині контуру
+			if !insideFootprint(detection.Footprint, detection.Longitude, detection.Latitude) {
+				t.Errorf("centre (%v, %v) outside footprint %v", detection.Latitude, detection.Longitude, detection.Footprint)
+			}
+		})
+	}
+}
+
+// insideFootprint перевіряє, чи лежить точка всередині опуклого кільця проти годинникової стрілки
+func insideFootprint(ring [][]float64, lon, lat float64) bool {
+	for i := 0; i+1 < len(ring); i++ {
+		a, b := ring[i], ring[i+1]
+		if (b[0]-a[0])*(lat-a[1])-(b[1]-a[1])*(lon-a[0]) <= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package fusion
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Політики визначення глибини виявленого об'єкта
+const (
+	// DepthPolicyClass бере типову глибину найімовірнішого класу з моделі класифікатора
+	DepthPolicyClass = "class"
+	// DepthPolicyFixed завжди використовує DefaultDepth
+	DepthPolicyFixed = "fixed"
+)
+
+// DetectorConfig налаштування детектора, які можна зберегти як профіль місії
+type DetectorConfig struct {
+	// ConfidenceThreshold мінімальна ймовірність міни для створення детекції
+	ConfidenceThreshold float64 `json:"confidence_threshold"`
+	// GridCellSize розмір комірки геопросторової сітки, м
+	GridCellSize float64 `json:"grid_cell_size"`
+	// ConflictThreshold рівень конфлікту доказів, вище якого застосовується правило Ягера
+	ConflictThreshold float64 `json:"conflict_threshold"`
+	// KalmanParams параметри фільтра для типів сенсорів; відсутні типи беруть значення за замовчуванням
+	KalmanParams map[string]KalmanParams `json:"kalman_params,omitempty"`
+	// ClassifierModel власна модель класифікатора; nil означає модель за замовчуванням
+	ClassifierModel *ClassifierModel `json:"classifier_model,omitempty"`
+	// SoilType тип ґрунту району; порожній, якщо невідомий
+	SoilType string `json:"soil_type,omitempty"`
+	// DepthPolicy спосіб визначення глибини: class або fixed
+	DepthPolicy string `json:"depth_policy"`
+	// DefaultDepth глибина для політики fixed, м
+	DefaultDepth float64 `json:"default_depth"`
+	// DefaultDangerLevel рівень небезпеки для класів, яким модель класифікатора
+	// його не задає (danger_level 0)
+	DefaultDangerLevel int `json:"default_danger_level"`
+	// AssociationRadius радіус, у межах якого нова детекція вважається повторним
+	// спостереженням уже відомого об'єкта місії, м; 0 вимикає об'єднання
+	AssociationRadius float64 `json:"association_radius"`
+}
+
+// DefaultDetectorConfig повертає налаштування детектора за замовчуванням
+func DefaultDetectorConfig() DetectorConfig {
+	return DetectorConfig{
+		ConfidenceThreshold: 0.7,
+		GridCellSize:        DefaultGridCellSize,
+		ConflictThreshold:   DefaultConflictThreshold,
+		KalmanParams:        DefaultKalmanParams(),
+		DepthPolicy:         DepthPolicyClass,
+		DefaultDepth:        0.15,
+		DefaultDangerLevel:  3,
+		AssociationRadius:   1.0,
+	}
+}
+
+// DetectorPresets повертає іменовані набори налаштувань для типових умов роботи
+func DetectorPresets() map[string]DetectorConfig {
+	// Сільськогосподарські угіддя: однорідний ґрунт і мало сміття, тож детектор
+	// працює з дрібнішою сіткою та нижчим порогом, щоб не пропустити ППМ
+	farmland := DefaultDetectorConfig()
+	farmland.ConfidenceThreshold = 0.6
+	farmland.GridCellSize = 0.25
+	farmland.SoilType = "loam"
+	farmland.AssociationRadius = 0.5
+
+	// Узбіччя доріг: багато металевого сміття та ущільнений ґрунт, тож поріг
+	// вищий, магнітометру довіряють менше, а конфлікт доказів обробляється обережніше.
+	// Біля доріг частіше трапляються протитранспортні боєприпаси, тож класи без
+	// власного рівня небезпеки отримують вищий рівень
+	roadside := DefaultDetectorConfig()
+	roadside.ConfidenceThreshold = 0.8
+	roadside.GridCellSize = 1.0
+	roadside.ConflictThreshold = 0.3
+	roadside.SoilType = "sand"
+	roadside.KalmanParams[SensorMagnetic] = KalmanParams{ProcessNoise: 4.0, MeasurementNoise: 100.0}
+	roadside.DefaultDangerLevel = 4
+	roadside.AssociationRadius = 1.5
+
+	return map[string]DetectorConfig{
+		"default":  DefaultDetectorConfig(),
+		"farmland": farmland,
+		"roadside": roadside,
+	}
+}
+
+// DetectorPresetNames повертає відсортовані назви наборів налаштувань
+func DetectorPresetNames() []string {
+	presets := DetectorPresets()
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate перевіряє налаштування, не залежні від моделі класифікатора
+func (c DetectorConfig) Validate() error {
+	if c.ConfidenceThreshold <= 0 || c.ConfidenceThreshold > 1 || math.IsNaN(c.ConfidenceThreshold) {
+		return fmt.Errorf("confidence threshold must be within (0, 1], got %v", c.ConfidenceThreshold)
+	}
+	if c.GridCellSize <= 0 || math.IsNaN(c.GridCellSize) || math.IsInf(c.GridCellSize, 0) {
+		return errors.New("grid cell size must be positive")
+	}
+	if err := validateProbability("conflict threshold", c.ConflictThreshold); err != nil {
+		return err
+	}
+
+	for sensorType, params := range c.KalmanParams {
+		if !IsSensorType(sensorType) {
+			return fmt.Errorf("kalman params for unsupported sensor type %q", sensorType)
+		}
+		if err := params.Validate(); err != nil {
+			return fmt.Errorf("kalman params for %s: %w", sensorType, err)
+		}
+	}
+
+	switch c.DepthPolicy {
+	case DepthPolicyClass, DepthPolicyFixed:
+	default:
+		return fmt.Errorf("unknown depth policy %q (expected %s or %s)", c.DepthPolicy, DepthPolicyClass, DepthPolicyFixed)
+	}
+	if c.DefaultDepth < 0 || math.IsNaN(c.DefaultDepth) {
+		return errors.New("default depth must not be negative")
+	}
+	if c.DefaultDangerLevel < 1 || c.DefaultDangerLevel > 5 {
+		return fmt.Errorf("default danger level must be within 1..5, got %d", c.DefaultDangerLevel)
+	}
+	if c.AssociationRadius < 0 || math.IsNaN(c.AssociationRadius) || math.IsInf(c.AssociationRadius, 0) {
+		return errors.New("association radius must not be negative")
+	}
+
+	return nil
+}
+
+// NewDetectorWithConfig створює детектор із заданими налаштуваннями.
+// Якщо конфігурація не містить моделі, використовується fallback,
+// а за його відсутності - вбудована модель класифікатора.
+func NewDetectorWithConfig(config DetectorConfig, fallback *Classifier) (*Detector, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	classifier := fallback
+	if config.ClassifierModel != nil {
+		var err error
+		if classifier, err = NewClassifier(*config.ClassifierModel); err != nil {
+			return nil, fmt.Errorf("classifier model: %w", err)
+		}
+	}
+	if classifier == nil {
+		classifier = DefaultClassifier()
+	}
+
+	kalmanParams := DefaultKalmanParams()
+	for sensorType, params := range config.KalmanParams {
+		kalmanParams[sensorType] = params
+	}
+
+	detector := &Detector{
+		confidenceThreshold: config.ConfidenceThreshold,
+		gridCellSize:        config.GridCellSize,
+		kalmanParams:        kalmanParams,
+		conflictThreshold:   config.ConflictThreshold,
+		classifier:          classifier,
+		depthPolicy:         config.DepthPolicy,
+		defaultDepth:        config.DefaultDepth,
+		defaultDangerLevel:  config.DefaultDangerLevel,
+		associationRadius:   config.AssociationRadius,
+	}
+
+	if err := detector.SetSoilType(config.SoilType); err != nil {
+		return nil, err
+	}
+
+	return detector, nil
+}
@@ -0,0 +1,37 @@
+package fusion
+
+import "mine-detection-system/pkg/protocol"
+
+// ErrTruncatedPayload повертається, якщо навантаження коротше, ніж вимагає його заголовок
+var ErrTruncatedPayload = protocol.ErrTruncatedPayload
+
+// ErrMalformedPayload повертається, якщо навантаження містить некоректні значення
+var ErrMalformedPayload = protocol.ErrMalformedPayload
+
+// Публічні функції для обробки різних типів даних сенсорів.
+// Формати навантажень описані в пакеті protocol.
+
+// ProcessLidarData декодує пакет ЛІДАР
+func ProcessLidarData(data []byte) (*LidarPointCloud, error) {
+	return protocol.DecodeLidar(data)
+}
+
+// ProcessMagneticData декодує пакет трикомпонентного магнітометра
+func ProcessMagneticData(data []byte) (*MagneticVector, error) {
+	return protocol.DecodeMagnetic(data)
+}
+
+// ProcessAcousticData декодує PCM-кадри акустичного сенсора
+func ProcessAcousticData(data []byte) (*AcousticWaveform, error) {
+	return protocol.DecodeAcoustic(data)
+}
+
+// ProcessGPRData декодує серію A-сканів георадара
+func ProcessGPRData(data []byte) (*GPRScan, error) {
+	return protocol.DecodeGPR(data)
+}
+
+// ProcessThermalData декодує кадр тепловізора
+func ProcessThermalData(data []byte) (*ThermalImage, error) {
+	return protocol.DecodeThermal(data)
+}
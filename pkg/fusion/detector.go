@@ -3,10 +3,27 @@ package fusion
 import (
 	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+	"mine-detection-system/pkg/geo"
+	"time"
 )
 
+// DefaultGridCellSize розмір комірки геопросторової сітки за замовчуванням (у метрах)
+const DefaultGridCellSize = 0.5
+
+// Sample один вимір сенсора, прив'язаний до координат
+type Sample struct {
+	// SensorType тип сенсора з реєстру, наприклад SensorMagnetic
+	SensorType string
+	Timestamp  time.Time
+	Latitude   float64
+	Longitude  float64
+	Altitude   float64
+	// Data декодоване навантаження, яке повертає DecodeSensorData
+	Data interface{}
+	// Quality індикатори якості виміру; сила сигналу береться з ключа signalStrength
+	Quality interface{}
+}
+
 // Detection представляє результат виявлення міни
 type Detection struct {
 	Latitude    float64
@@ -15,332 +32,244 @@ type Detection struct {
 	ObjectType  string
 	Confidence  float64
 	DangerLevel int
+	// Інтервал [Belief, Plausibility] меж ймовірності міни за теорією Демпстера-Шефера
+	Belief       float64
+	Plausibility float64
+	// Conflict конфлікт між доказами сенсорів
+	Conflict float64
+	// Posterior апостеріорний розподіл класів об'єкта
+	Posterior map[string]float64
+	// Cells кількість комірок сітки, які займає об'єкт
+	Cells  int
+	Extent Extent
+	// Footprint контур об'єкта: замкнене кільце точок [lon, lat]; nil, якщо контур вироджений
+	Footprint [][]float64
 }
 
 // Detector реалізує алгоритми для злиття даних з різних сенсорів
 type Detector struct {
 	// Налаштування детектора
 	confidenceThreshold float64
+	gridCellSize        float64
+	kalmanParams        map[string]KalmanParams
+	conflictThreshold   float64
+	classifier          *Classifier
+	// soilType тип ґрунту району сканування; порожній, якщо невідомий
+	soilType           string
+	depthPolicy        string
+	defaultDepth       float64
+	defaultDangerLevel int
+	associationRadius  float64
 }
 
-// NewDetector створює новий екземпляр Detector
+// NewDetector створює новий екземпляр Detector з налаштуваннями за замовчуванням
 func NewDetector() *Detector {
-	return &Detector{
-		confidenceThreshold: 0.7, // За замовчуванням
+	detector, err := NewDetectorWithConfig(DefaultDetectorConfig(), nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default detector config: %v", err))
 	}
+	return detector
 }
 
-// FuseAndDetect об'єднує дані з різних сенсорів та виявляє потенційні міни
-func (d *Detector) FuseAndDetect(
-	lidarData interface{},
-	magneticData interface{},
-	acousticData interface{},
-) ([]Detection, error) {
-	if lidarData == nil && magneticData == nil && acousticData == nil {
-		return nil, errors.New("no sensor data provided")
+// SetGridCellSize змінює розмір комірки геопросторової сітки (у метрах)
+func (d *Detector) SetGridCellSize(meters float64) error {
+	if meters <= 0 {
+		return errors.New("grid cell size must be positive")
 	}
 
-	// Створення геопросторової сітки для аналізу
-	grid := d.createSpatialGrid(lidarData, magneticData, acousticData)
-
-	// Виконання аналізу Калманівської фільтрації
-	fusedGrid := d.performKalmanFiltering(grid)
-
-	// Застосування байєсівської мережі для класифікації
-	classifiedGrid := d.applyBayesianNetwork(fusedGrid)
-
-	// Виявлення підозрілих областей
-	detections := d.detectSuspiciousRegions(classifiedGrid)
-
-	return detections, nil
+	d.gridCellSize = meters
+	return nil
 }
 
-// createSpatialGrid створює геопросторову сітку, об'єднуючи дані з різних сенсорів
-func (d *Detector) createSpatialGrid(
-	lidarData interface{},
-	magneticData interface{},
-	acousticData interface{},
-) map[string]interface{} {
-	// Спрощена реалізація для прикладу
-	grid := make(map[string]interface{})
-
-	// Імітація додавання даних до сітки
-	grid["sample_point"] = map[string]interface{}{
-		"lidar":    lidarData,
-		"magnetic": magneticData,
-		"acoustic": acousticData,
+// SetKalmanParams змінює параметри фільтра Калмана для типу сенсора
+func (d *Detector) SetKalmanParams(sensorType string, params KalmanParams) error {
+	if !IsSensorType(sensorType) {
+		return fmt.Errorf("unsupported sensor type %q", sensorType)
+	}
+	if err := params.Validate(); err != nil {
+		return err
 	}
 
-	return grid
+	d.kalmanParams[sensorType] = params
+	return nil
 }
 
-// performKalmanFiltering виконує Калманівську фільтрацію даних
-func (d *Detector) performKalmanFiltering(grid map[string]interface{}) map[string]interface{} {
-	// Спрощена реалізація для прикладу
-	result := make(map[string]interface{})
+// SetConflictThreshold змінює рівень конфлікту, вище якого застосовується правило Ягера.
+// Значення 1 вимикає правило Ягера, крім випадку повного конфлікту.
+func (d *Detector) SetConflictThreshold(threshold float64) error {
+	if err := validateProbability("conflict threshold", threshold); err != nil {
+		return err
+	}
 
-	for key, value := range grid {
-		gridPoint := value.(map[string]interface{})
+	d.conflictThreshold = threshold
+	return nil
+}
 
-		// Створення результатів фільтрації
-		filteredPoint := make(map[string]interface{})
+// SetClassifier замінює байєсівський класифікатор детектора
+func (d *Detector) SetClassifier(classifier *Classifier) error {
+	if classifier == nil {
+		return errors.New("classifier is required")
+	}
+	if d.soilType != "" && !classifier.HasSoilType(d.soilType) {
+		return fmt.Errorf("classifier model does not know soil type %q", d.soilType)
+	}
 
-		// Якщо є дані ЛІДАР, виконати фільтрацію
-		if lidarData, ok := gridPoint["lidar"]; ok {
-			filteredPoint["lidar_filtered"] = filterLidarData(lidarData)
-		}
+	d.classifier = classifier
+	return nil
+}
 
-		// Якщо є магнітометричні дані, виконати фільтрацію
-		if magneticData, ok := gridPoint["magnetic"]; ok {
-			filteredPoint["magnetic_filtered"] = filterMagneticData(magneticData)
-		}
+// SetSoilType задає тип ґрунту району сканування; порожній рядок означає невідомий ґрунт
+func (d *Detector) SetSoilType(soil string) error {
+	if soil != "" && !d.classifier.HasSoilType(soil) {
+		return fmt.Errorf("classifier model does not know soil type %q", soil)
+	}
 
-		// Якщо є акустичні дані, виконати фільтрацію
-		if acousticData, ok := gridPoint["acoustic"]; ok {
-			filteredPoint["acoustic_filtered"] = filterAcousticData(acousticData)
-		}
+	d.soilType = soil
+	return nil
+}
 
-		// Якщо доступні дані з кількох сенсорів, виконати злиття
-		if _, hasLidar := filteredPoint["lidar_filtered"]; hasLidar {
-			if _, hasMagnetic := filteredPoint["magnetic_filtered"]; hasMagnetic {
-				filteredPoint["fused_lidar_magnetic"] = fuseLidarAndMagnetic(
-					filteredPoint["lidar_filtered"],
-					filteredPoint["magnetic_filtered"],
-				)
-			}
-		}
+// AssociationRadius повертає радіус об'єднання повторних детекцій, м
+func (d *Detector) AssociationRadius() float64 {
+	return d.associationRadius
+}
 
-		result[key] = filteredPoint
+// FuseAndDetect об'єднує дані з різних сенсорів та виявляє потенційні міни.
+// Виміри можуть належати будь-якому підтримуваному типу сенсора в довільному порядку.
+func (d *Detector) FuseAndDetect(samples []Sample) ([]Detection, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("no sensor data provided")
 	}
 
-	return result
-}
-
-// applyBayesianNetwork застосовує байєсівську мережу для класифікації
-func (d *Detector) applyBayesianNetwork(grid map[string]interface{}) map[string]interface{} {
-	// Спрощена реалізація для прикладу
-	result := make(map[string]interface{})
-
-	for key, value := range grid {
-		gridPoint := value.(map[string]interface{})
-
-		// Застосування класифікатора
-		classification := make(map[string]interface{})
-
-		// Базова ймовірність наявності міни
-		mineProb := 0.0
-
-		// Агрегація доказів з різних джерел даних
-		if fusedData, ok := gridPoint["fused_lidar_magnetic"]; ok {
-			mineProb = calculateMineProbability(fusedData)
-		} else {
-			// Використання окремих сенсорів, якщо об'єднані дані недоступні
-			lidarProb := 0.0
-			magneticProb := 0.0
-			acousticProb := 0.0
-
-			if lidarData, ok := gridPoint["lidar_filtered"]; ok {
-				lidarProb = calculateLidarMineProbability(lidarData)
-			}
-
-			if magneticData, ok := gridPoint["magnetic_filtered"]; ok {
-				magneticProb = calculateMagneticMineProbability(magneticData)
-			}
-
-			if acousticData, ok := gridPoint["acoustic_filtered"]; ok {
-				acousticProb = calculateAcousticMineProbability(acousticData)
-			}
-
-			// Об'єднання ймовірностей за допомогою методу Демпстера-Шефера
-			mineProb = combineProbabilities(lidarProb, magneticProb, acousticProb)
-		}
+	// Створення геопросторової сітки для аналізу
+	grid := d.createSpatialGrid(samples)
 
-		classification["mine_probability"] = mineProb
+	// Виконання аналізу Калманівської фільтрації
+	fusedGrid := d.performKalmanFiltering(grid)
 
-		// Визначення типу об'єкта на основі патернів
-		if mineProb > 0.8 {
-			classification["object_type"] = determineObjectType(gridPoint)
-			classification["danger_level"] = determineDangerLevel(gridPoint)
-		}
+	// Застосування байєсівської мережі для класифікації
+	classifiedGrid := d.applyBayesianNetwork(fusedGrid)
 
-		result[key] = classification
-	}
+	// Виявлення підозрілих областей
+	detections := d.detectSuspiciousRegions(grid, classifiedGrid)
 
-	return result
+	return detections, nil
 }
 
-// detectSuspiciousRegions виявляє підозрілі області на основі класифікації
-func (d *Detector) detectSuspiciousRegions(grid map[string]interface{}) []Detection {
-	var detections []Detection
+// createSpatialGrid створює геопросторову сітку, об'єднуючи дані з різних сенсорів.
+// Кожен вимір потрапляє до комірки за своїми координатами, а комірка зберігає
+// окремі списки вимірів для кожного типу сенсора. Виміри невідомих типів або
+// з пошкодженим навантаженням пропускаються, щоб не блокувати аналіз решти даних.
+func (d *Detector) createSpatialGrid(samples []Sample) *SpatialGrid {
+	grid := &SpatialGrid{
+		Cells: make(map[CellKey]*GridCell),
+	}
 
-	for key, value := range grid {
-		classification := value.(map[string]interface{})
+	// Опорна широта проєкції береться з першого виміру, щоб усі комірки мали однаковий масштаб
+	gridReady := false
 
-		mineProb, ok := classification["mine_probability"].(float64)
+	for _, sample := range samples {
+		spec, ok := lookupSensor(sample.SensorType)
 		if !ok {
 			continue
 		}
 
-		// Перевірка, чи перевищує ймовірність наявності міни порогове значення
-		if mineProb >= d.confidenceThreshold {
-			// Розбір координат з ключа сітки
-			lat, lon := parseGridKey(key)
-
-			// Створення об'єкту детекції
-			detection := Detection{
-				Latitude:   lat,
-				Longitude:  lon,
-				Confidence: mineProb,
-			}
-
-			// Додавання інформації про тип об'єкта, якщо доступна
-			if objectType, ok := classification["object_type"].(string); ok {
-				detection.ObjectType = objectType
-			} else {
-				detection.ObjectType = "unknown"
-			}
-
-			// Додавання інформації про рівень небезпеки, якщо доступна
-			if dangerLevel, ok := classification["danger_level"].(int); ok {
-				detection.DangerLevel = dangerLevel
-			} else {
-				detection.DangerLevel = 3 // Середній рівень за замовчуванням
-			}
-
-			// Додавання глибини, якщо доступна
-			if depth, ok := classification["depth"].(float64); ok {
-				detection.Depth = depth
-			} else {
-				detection.Depth = 0.15 // Стандартна глибина за замовчуванням
-			}
-
-			detections = append(detections, detection)
+		value, ok, err := spec.Feature(sample.Data)
+		if err != nil || !ok {
+			continue
 		}
-	}
 
-	return detections
-}
-
-// Допоміжні функції
-
-// generateGridKey генерує ключ для геопросторової сітки
-func generateGridKey(lat, lon float64) string {
-	// Округлення координат до певної точності для створення сітки
-	return fmt.Sprintf("%.6f:%.6f", lat, lon)
-}
+		if !gridReady {
+			grid.Projection = geo.NewGrid(d.gridCellSize, sample.Latitude)
+			gridReady = true
+		}
 
-// parseGridKey розбирає ключ сітки на координати
-func parseGridKey(key string) (float64, float64) {
-	parts := strings.Split(key, ":")
-	if len(parts) != 2 {
-		return 0, 0
+		cell := grid.cell(sample.Latitude, sample.Longitude)
+		cell.Observations[sample.SensorType] = append(cell.Observations[sample.SensorType], Observation{
+			Sample: sample,
+			Value:  value,
+		})
 	}
 
-	lat, _ := strconv.ParseFloat(parts[0], 64)
-	lon, _ := strconv.ParseFloat(parts[1], 64)
-
-	return lat, lon
+	return grid
 }
 
-// Імітація функцій обробки даних (в реальній системі тут складні алгоритми)
-
-func filterLidarData(data interface{}) interface{} {
-	// Імітація фільтрації ЛІДАР-даних
-	return data
-}
+// performKalmanFiltering виконує Калманівську фільтрацію даних.
+// Для кожної комірки та кожного типу сенсора працює окремий фільтр,
+// тож оцінки різних каналів не змішуються до етапу класифікації.
+func (d *Detector) performKalmanFiltering(grid *SpatialGrid) map[CellKey]*FilteredCell {
+	result := make(map[CellKey]*FilteredCell, len(grid.Cells))
 
-func filterMagneticData(data interface{}) interface{} {
-	// Імітація фільтрації магнітометричних даних
-	return data
-}
+	for key, cell := range grid.Cells {
+		filtered := &FilteredCell{
+			Cell:      cell,
+			Estimates: make(map[string]SensorEstimate, len(cell.Observations)),
+		}
 
-func filterAcousticData(data interface{}) interface{} {
-	// Імітація фільтрації акустичних даних
-	return data
-}
+		for sensorType, observations := range cell.Observations {
+			filtered.Estimates[sensorType] = filterObservations(observations, d.kalmanParams[sensorType])
+		}
 
-func fuseLidarAndMagnetic(lidarData, magneticData interface{}) interface{} {
-	// Імітація злиття даних ЛІДАР та магнітометра
-	return map[string]interface{}{
-		"lidar":    lidarData,
-		"magnetic": magneticData,
+		result[key] = filtered
 	}
-}
 
-func calculateMineProbability(data interface{}) float64 {
-	// Імітація розрахунку ймовірності наявності міни
-	return 0.75
+	return result
 }
 
-func calculateLidarMineProbability(data interface{}) float64 {
-	// Імітація розрахунку ймовірності наявності міни за даними ЛІДАР
-	return 0.6
-}
+// applyBayesianNetwork застосовує байєсівську мережу для класифікації
+func (d *Detector) applyBayesianNetwork(grid map[CellKey]*FilteredCell) map[CellKey]*Classification {
+	result := make(map[CellKey]*Classification, len(grid))
 
-func calculateMagneticMineProbability(data interface{}) float64 {
-	// Імітація розрахунку ймовірності наявності міни за магнітометричними даними
-	return 0.7
-}
+	for key, filtered := range grid {
+		result[key] = d.classifyCell(filtered)
+	}
 
-func calculateAcousticMineProbability(data interface{}) float64 {
-	// Імітація розрахунку ймовірності наявності міни за акустичними даними
-	return 0.8
+	return result
 }
 
-func combineProbabilities(probs ...float64) float64 {
-	// Спрощена імітація комбінування ймовірностей
-	sum := 0.0
-	count := 0
+// classifyCell класифікує одну комірку за відфільтрованими оцінками сенсорів
+func (d *Detector) classifyCell(filtered *FilteredCell) *Classification {
+	classification := &Classification{Cell: filtered.Cell}
 
-	for _, prob := range probs {
-		if prob > 0 {
-			sum += prob
-			count++
+	// Кожен сенсор дає призначення мас; надійність залежить від коваріації оцінки
+	var masses []MassFunction
+	for _, sensorType := range SensorTypes() {
+		estimate, ok := filtered.Estimates[sensorType]
+		if !ok {
+			continue
 		}
-	}
+		spec, _ := lookupSensor(sensorType)
 
-	if count == 0 {
-		return 0
+		probability, ok := d.classifier.HazardProbability(sensorType, estimate, d.soilType)
+		if !ok {
+			continue
+		}
+		reliability := estimateReliability(estimate, d.kalmanParams[sensorType], spec.Reliability)
+		masses = append(masses, sensorMass(probability, reliability))
 	}
 
-	return sum / float64(count)
-}
+	// Об'єднання доказів за допомогою методу Демпстера-Шефера
+	classification.Evidence = combineEvidence(masses, d.conflictThreshold)
+	classification.MineProbability = classification.Evidence.Mass.Pignistic()
 
-func determineObjectType(data map[string]interface{}) string {
-	// Імітація визначення типу об'єкта
-	return "anti_personnel_mine"
-}
+	// Визначення типу об'єкта за апостеріорним розподілом класів
+	classification.Posterior = d.classifier.Posterior(filtered.Estimates, d.soilType)
+	class := d.classifier.MostLikely(classification.Posterior)
+	classification.ObjectType = class.Name
+	classification.DangerLevel = d.dangerLevel(class)
+	depth := class.TypicalDepth
+	classification.Depth = &depth
 
-func determineDangerLevel(data map[string]interface{}) int {
-	// Імітація визначення рівня небезпеки
-	return 4
+	return classification
 }
 
-// Публічні функції для обробки різних типів даних сенсорів
-
-// ProcessLidarData обробляє дані ЛІДАР
-func ProcessLidarData(data []byte) (interface{}, error) {
-	// Обробка бінарних даних ЛІДАР
-	return map[string]interface{}{
-		"processed": true,
-		"type":      "lidar",
-	}, nil
-}
+// detectSuspiciousRegions виявляє підозрілі області на основі класифікації.
+// Суміжні комірки над порогом об'єднуються в кластер, і кожен кластер дає одну детекцію.
+func (d *Detector) detectSuspiciousRegions(grid *SpatialGrid, classified map[CellKey]*Classification) []Detection {
+	var detections []Detection
 
-// ProcessMagneticData обробляє дані магнітометра
-func ProcessMagneticData(data []byte) (interface{}, error) {
-	// Обробка бінарних даних магнітометра
-	return map[string]interface{}{
-		"processed": true,
-		"type":      "magnetic",
-	}, nil
-}
+	for _, cluster := range d.clusterCells(classified) {
+		detections = append(detections, d.clusterDetection(grid.Projection, cluster))
+	}
 
-// ProcessAcousticData обробляє акустичні дані
-func ProcessAcousticData(data []byte) (interface{}, error) {
-	// Обробка бінарних акустичних даних
-	return map[string]interface{}{
-		"processed": true,
-		"type":      "acoustic",
-	}, nil
+	return detections
 }
@@ -0,0 +1,143 @@
+package fusion
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultConflictThreshold рівень конфлікту, вище якого замість правила Демпстера
+// застосовується правило Ягера
+const DefaultConflictThreshold = 0.5
+
+// MassFunction базове призначення мас (BPA) на фреймі розрізнення {mine, clutter}.
+// Unknown - маса, віднесена до всього фрейму, тобто незнання.
+type MassFunction struct {
+	Mine    float64 `json:"mine"`
+	Clutter float64 `json:"clutter"`
+	Unknown float64 `json:"unknown"`
+}
+
+// vacuousMass повне незнання: вся маса на фреймі
+var vacuousMass = MassFunction{Unknown: 1}
+
+// Belief повертає міру довіри до гіпотези "міна" - нижню межу ймовірності
+func (m MassFunction) Belief() float64 {
+	return m.Mine
+}
+
+// Plausibility повертає правдоподібність гіпотези "міна" - верхню межу ймовірності
+func (m MassFunction) Plausibility() float64 {
+	return m.Mine + m.Unknown
+}
+
+// Pignistic повертає пігністичну ймовірність гіпотези "міна" (BetP),
+// яка розподіляє незнання порівну між гіпотезами
+func (m MassFunction) Pignistic() float64 {
+	return m.Mine + m.Unknown/2
+}
+
+// sensorMass будує призначення мас з ймовірності міни за даними сенсора.
+// Надійність reliability в [0, 1] визначає частку маси, яка віддається
+// конкретним гіпотезам; решта залишається на незнанні. Отже ймовірність 0
+// від надійного сенсора - сильний доказ на користь clutter, а не відсутність доказу.
+func sensorMass(probability, reliability float64) MassFunction {
+	probability = clamp(probability, 0, 1)
+	reliability = clamp(reliability, 0, 1)
+
+	return MassFunction{
+		Mine:    probability * reliability,
+		Clutter: (1 - probability) * reliability,
+		Unknown: 1 - reliability,
+	}
+}
+
+// estimateReliability обчислює надійність сенсора за коваріацією оцінки Калмана.
+// Оцінка з одного виміру (коваріація дорівнює шуму вимірювання) має половину
+// базової надійності, а зі зменшенням коваріації надійність прямує до базової.
+func estimateReliability(estimate SensorEstimate, params KalmanParams, base float64) float64 {
+	if estimate.Samples == 0 {
+		return 0
+	}
+
+	return base * params.MeasurementNoise / (params.MeasurementNoise + estimate.Covariance)
+}
+
+// combineDempster комбінує два призначення мас за правилом Демпстера.
+// Повертає результат і конфлікт K - масу, віднесену до порожньої множини.
+// При повному конфлікті (K = 1) правило не визначене і повертається false.
+func combineDempster(a, b MassFunction) (MassFunction, float64, bool) {
+	mine, clutter, unknown, conflict := intersect(a, b)
+	if conflict >= 1 {
+		return MassFunction{}, conflict, false
+	}
+
+	normalization := 1 - conflict
+	return MassFunction{
+		Mine:    mine / normalization,
+		Clutter: clutter / normalization,
+		Unknown: unknown / normalization,
+	}, conflict, true
+}
+
+// combineYager комбінує два призначення мас за правилом Ягера:
+// конфліктна маса не нормалізується, а переноситься на незнання
+func combineYager(a, b MassFunction) (MassFunction, float64) {
+	mine, clutter, unknown, conflict := intersect(a, b)
+
+	return MassFunction{
+		Mine:    mine,
+		Clutter: clutter,
+		Unknown: unknown + conflict,
+	}, conflict
+}
+
+// intersect обчислює кон'юнктивну комбінацію мас без нормалізації
+func intersect(a, b MassFunction) (mine, clutter, unknown, conflict float64) {
+	mine = a.Mine*b.Mine + a.Mine*b.Unknown + a.Unknown*b.Mine
+	clutter = a.Clutter*b.Clutter + a.Clutter*b.Unknown + a.Unknown*b.Clutter
+	unknown = a.Unknown * b.Unknown
+	conflict = a.Mine*b.Clutter + a.Clutter*b.Mine
+	return mine, clutter, unknown, conflict
+}
+
+// EvidenceResult результат комбінування доказів від кількох сенсорів
+type EvidenceResult struct {
+	Mass MassFunction
+	// Conflict найбільший конфлікт K, виміряний на кроках комбінування
+	Conflict float64
+	// YagerApplied true, якщо хоча б на одному кроці застосовано правило Ягера
+	YagerApplied bool
+}
+
+// combineEvidence послідовно комбінує призначення мас сенсорів.
+// Якщо конфлікт на кроці перевищує conflictThreshold, крок виконується за
+// правилом Ягера, щоб нормалізація Демпстера не посилювала суперечливі докази.
+func combineEvidence(masses []MassFunction, conflictThreshold float64) EvidenceResult {
+	result := EvidenceResult{Mass: vacuousMass}
+
+	for _, mass := range masses {
+		combined, conflict, ok := combineDempster(result.Mass, mass)
+		if !ok || conflict > conflictThreshold {
+			combined, conflict = combineYager(result.Mass, mass)
+			result.YagerApplied = true
+		}
+
+		result.Mass = combined
+		result.Conflict = math.Max(result.Conflict, conflict)
+	}
+
+	return result
+}
+
+// validateProbability перевіряє, що значення лежить у [0, 1]
+func validateProbability(name string, value float64) error {
+	if math.IsNaN(value) || value < 0 || value > 1 {
+		return fmt.Errorf("%s must be within [0, 1], got %v", name, value)
+	}
+	return nil
+}
+
+// clamp обмежує значення діапазоном [lower, upper]
+func clamp(value, lower, upper float64) float64 {
+	return math.Max(lower, math.Min(upper, value))
+}
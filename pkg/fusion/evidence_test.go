@@ -0,0 +1,226 @@
+package fusion
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func massEqual(a, b MassFunction) bool {
+	return approxEqual(a.Mine, b.Mine) && approxEqual(a.Clutter, b.Clutter) && approxEqual(a.Unknown, b.Unknown)
+}
+
+// checkMass перевіряє, що маси невід'ємні, їх сума дорівнює 1,
+// а довіра не перевищує правдоподібності
+func checkMass(t *testing.T, name string, m MassFunction) {
+	t.Helper()
+
+	if m.Mine < -tolerance || m.Clutter < -tolerance || m.Unknown < -tolerance {
+		t.Errorf("%s: negative mass %+v", name, m)
+	}
+	if sum := m.Mine + m.Clutter + m.Unknown; !approxEqual(sum, 1) {
+		t.Errorf("%s: masses %+v sum to %v, want 1", name, m, sum)
+	}
+	if m.Belief() > m.Pignistic()+tolerance || m.Pignistic() > m.Plausibility()+tolerance {
+		t.Errorf("%s: belief %v, pignistic %v, plausibility %v are not ordered", name, m.Belief(), m.Pignistic(), m.Plausibility())
+	}
+}
+
+func TestSensorMass(t *testing.T) {
+	tests := []struct {
+		name        string
+		probability float64
+		reliability float64
+		want        MassFunction
+	}{
+		{name: "unreliable sensor", probability: 0.9, reliability: 0, want: vacuousMass},
+		{name: "reliable mine", probability: 1, reliability: 1, want: MassFunction{Mine: 1}},
+		{name: "reliable absence is evidence of clutter", probability: 0, reliability: 0.8, want: MassFunction{Clutter: 0.8, Unknown: 0.2}},
+		{name: "partial", probability: 0.75, reliability: 0.4, want: MassFunction{Mine: 0.3, Clutter: 0.1, Unknown: 0.6}},
+		{name: "out of range values are clamped", probability: 1.5, reliability: -0.2, want: vacuousMass},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mass := sensorMass(tt.probability, tt.reliability)
+			if !massEqual(mass, tt.want) {
+				t.Errorf("got %+v, want %+v", mass, tt.want)
+			}
+			checkMass(t, tt.name, mass)
+		})
+	}
+}
+
+func TestCombineDempster(t *testing.T) {
+	agreeing := MassFunction{Mine: 0.6, Clutter: 0.2, Unknown: 0.2}
+	other := MassFunction{Mine: 0.5, Clutter: 0.3, Unknown: 0.2}
+
+	tests := []struct {
+		name         string
+		a, b         MassFunction
+		want         MassFunction
+		wantConflict float64
+	}{
+		{name: "vacuous mass on the left", a: vacuousMass, b: agreeing, want: agreeing},
+		{name: "vacuous mass on the right", a: agreeing, b: vacuousMass, want: agreeing},
+		{name: "two vacuous masses", a: vacuousMass, b: vacuousMass, want: vacuousMass},
+		{
+			// Кон'юнкція: mine 0.52, clutter 0.16, unknown 0.04, конфлікт 0.28
+			name:         "partially conflicting sensors",
+			a:            agreeing,
+			b:            other,
+			want:         MassFunction{Mine: 0.52 / 0.72, Clutter: 0.16 / 0.72, Unknown: 0.04 / 0.72},
+			wantConflict: 0.28,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mass, conflict, ok := combineDempster(tt.a, tt.b)
+			if !ok {
+				t.Fatal("Dempster's rule reported total conflict")
+			}
+			if !massEqual(mass, tt.want) {
+				t.Errorf("got %+v, want %+v", mass, tt.want)
+			}
+			if !approxEqual(conflict, tt.wantConflict) {
+				t.Errorf("conflict = %v, want %v", conflict, tt.wantConflict)
+			}
+			checkMass(t, tt.name, mass)
+		})
+	}
+}
+
+func TestCombineDempsterTotalConflict(t *testing.T) {
+	_, conflict, ok := combineDempster(MassFunction{Mine: 1}, MassFunction{Clutter: 1})
+	if ok {
+		t.Error("Dempster's rule is undefined for total conflict, got a result")
+	}
+	if !approxEqual(conflict, 1) {
+		t.Errorf("conflict = %v, want 1", conflict)
+	}
+}
+
+func TestCombineYagerMovesConflictToUnknown(t *testing.T) {
+	a := MassFunction{Mine: 0.9, Unknown: 0.1}
+	b := MassFunction{Clutter: 0.9, Unknown: 0.1}
+
+	mass, conflict := combineYager(a, b)
+	want := MassFunction{Mine: 0.09, Clutter: 0.09, Unknown: 0.01 + 0.81}
+	if !massEqual(mass, want) || !approxEqual(conflict, 0.81) {
+		t.Errorf("got %+v with conflict %v, want %+v with conflict 0.81", mass, conflict, want)
+	}
+	checkMass(t, "yager", mass)
+}
+
+func TestCombineEvidence(t *testing.T) {
+	tests := []struct {
+		name         string
+		masses       []MassFunction
+		threshold    float64
+		want         MassFunction
+		wantConflict float64
+		wantYager    bool
+	}{
+		{
+			name:      "no sensors",
+			threshold: DefaultConflictThreshold,
+			want:      vacuousMass,
+		},
+		{
+			name:      "vacuous sensor does not change the result",
+			masses:    []MassFunction{sensorMass(0.8, 0.5), vacuousMass},
+			threshold: DefaultConflictThreshold,
+			want:      sensorMass(0.8, 0.5),
+		},
+		{
+			name:         "agreeing sensors use Dempster's rule",
+			masses:       []MassFunction{sensorMass(0.9, 0.5), sensorMass(0.8, 0.5)},
+			threshold:    DefaultConflictThreshold,
+			want:         MassFunction{Mine: 0.605 / 0.935, Clutter: 0.08 / 0.935, Unknown: 0.25 / 0.935},
+			wantConflict: 0.065,
+		},
+		{
+			// Надійні сенсори суперечать один одному: конфлікт 0.9025 переноситься на незнання
+			name:         "near-total conflict switches to Yager",
+			masses:       []MassFunction{sensorMass(1, 0.95), sensorMass(0, 0.95)},
+			threshold:    DefaultConflictThreshold,
+			want:         MassFunction{Mine: 0.0475, Clutter: 0.0475, Unknown: 0.0025 + 0.9025},
+			wantConflict: 0.9025,
+			wantYager:    true,
+		},
+		{
+			name:         "total conflict falls back to Yager at any threshold",
+			masses:       []MassFunction{{Mine: 1}, {Clutter: 1}},
+			threshold:    1,
+			want:         vacuousMass,
+			wantConflict: 1,
+			wantYager:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := combineEvidence(tt.masses, tt.threshold)
+			if !massEqual(result.Mass, tt.want) {
+				t.Errorf("mass = %+v, want %+v", result.Mass, tt.want)
+			}
+			if !approxEqual(result.Conflict, tt.wantConflict) {
+				t.Errorf("conflict = %v, want %v", result.Conflict, tt.wantConflict)
+			}
+			if result.YagerApplied != tt.wantYager {
+				t.Errorf("Yager applied = %v, want %v", result.YagerApplied, tt.wantYager)
+			}
+			checkMass(t, tt.name, result.Mass)
+		})
+	}
+}
+
+func TestCombineEvidenceKeepsMassesValid(t *testing.T) {
+	values := []float64{0, 0.1, 0.5, 0.9, 1}
+
+	// Усі комбінації трьох сенсорів: маси завжди утворюють коректне призначення
+	var masses []MassFunction
+	for _, probability := range values {
+		for _, reliability := range values {
+			masses = append(masses, sensorMass(probability, reliability))
+		}
+	}
+	for _, a := range masses {
+		for _, b := range masses {
+			for _, c := range masses {
+				result := combineEvidence([]MassFunction{a, b, c}, DefaultConflictThreshold)
+				checkMass(t, "combined", result.Mass)
+				if result.Conflict < 0 || result.Conflict > 1+tolerance {
+					t.Fatalf("conflict %v out of [0, 1] for %+v, %+v, %+v", result.Conflict, a, b, c)
+				}
+			}
+		}
+	}
+}
+
+func TestEstimateReliability(t *testing.T) {
+	params := KalmanParams{MeasurementNoise: 4}
+
+	if got := estimateReliability(SensorEstimate{}, params, 0.9); got != 0 {
+		t.Errorf("reliability without samples = %v, want 0", got)
+	}
+	if got := estimateReliability(SensorEstimate{Covariance: 4, Samples: 1}, params, 0.9); !approxEqual(got, 0.45) {
+		t.Errorf("reliability of a single measurement = %v, want half of base", got)
+	}
+
+	// Зі зменшенням коваріації надійність зростає і прямує до базової
+	previous := 0.0
+	for _, covariance := range []float64{4, 2, 1, 0.1, 0} {
+		got := estimateReliability(SensorEstimate{Covariance: covariance, Samples: 10}, params, 0.9)
+		if got < previous || got > 0.9+tolerance {
+			t.Errorf("reliability at covariance %v = %v, want within [%v, 0.9]", covariance, got, previous)
+		}
+		previous = got
+	}
+}
@@ -0,0 +1,79 @@
+package fusion
+
+import "mine-detection-system/pkg/geo"
+
+// CellKey ідентифікує комірку геопросторової сітки
+type CellKey struct {
+	Row int
+	Col int
+}
+
+// Observation представляє один вимір сенсора, прив'язаний до комірки
+type Observation struct {
+	Sample Sample
+	// Value скалярна ознака, обчислена з корисного навантаження
+	Value float64
+}
+
+// GridCell представляє комірку сітки з вимірами, згрупованими за типом сенсора
+type GridCell struct {
+	Key          CellKey
+	Latitude     float64 // Широта центру комірки
+	Longitude    float64 // Довгота центру комірки
+	Observations map[string][]Observation
+}
+
+// SpatialGrid представляє геопросторову сітку, побудовану за даними сканування
+type SpatialGrid struct {
+	Projection geo.Grid
+	Cells      map[CellKey]*GridCell
+}
+
+// SensorEstimate представляє оцінку ознаки сенсора в комірці
+type SensorEstimate struct {
+	Value float64
+	// Covariance апостеріорна дисперсія оцінки фільтра Калмана
+	Covariance float64
+	Samples    int
+}
+
+// FilteredCell представляє комірку з відфільтрованими оцінками для кожного сенсора
+type FilteredCell struct {
+	Cell      *GridCell
+	Estimates map[string]SensorEstimate
+}
+
+// Classification представляє результат класифікації комірки
+type Classification struct {
+	Cell *GridCell
+	// Evidence об'єднані докази сенсорів за теорією Демпстера-Шефера
+	Evidence EvidenceResult
+	// MineProbability пігністична ймовірність міни, отримана з Evidence
+	MineProbability float64
+	// Posterior апостеріорний розподіл класів об'єкта за байєсівським класифікатором
+	Posterior   map[string]float64
+	ObjectType  string
+	DangerLevel int
+	// Depth оцінка глибини залягання, м; nil якщо оцінка недоступна
+	Depth *float64
+}
+
+// cell повертає комірку для точки, створюючи її за потреби
+func (g *SpatialGrid) cell(lat, lon float64) *GridCell {
+	row, col := g.Projection.Cell(lat, lon)
+	key := CellKey{Row: row, Col: col}
+
+	cell, ok := g.Cells[key]
+	if !ok {
+		centerLat, centerLon := g.Projection.Center(row, col)
+		cell = &GridCell{
+			Key:          key,
+			Latitude:     centerLat,
+			Longitude:    centerLon,
+			Observations: make(map[string][]Observation),
+		}
+		g.Cells[key] = cell
+	}
+
+	return cell
+}
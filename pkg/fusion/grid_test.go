@@ -0,0 +1,130 @@
+package fusion
+
+import (
+	"mine-detection-system/pkg/geo"
+	"testing"
+)
+
+func TestSpatialGridCellIndexing(t *testing.T) {
+	tests := []struct {
+		name     string
+		refLat   float64
+		lat, lon float64
+		wantRow  int
+		wantCol  int
+	}{
+		// Індекси округлюються вниз, тож точки трохи південніше екватора
+		// і західніше нульового меридіана потрапляють у комірку -1, а не 0
+		{name: "origin", lat: 0, lon: 0, wantRow: 0, wantCol: 0},
+		{name: "just north-east of origin", lat: 1e-7, lon: 1e-7, wantRow: 0, wantCol: 0},
+		{name: "just south-west of origin", lat: -1e-7, lon: -1e-7, wantRow: -1, wantCol: -1},
+		{name: "south of equator", lat: -2e-5, lon: 1e-7, wantRow: -5, wantCol: 0},
+		{name: "west of meridian", lat: 1e-7, lon: -2e-5, wantRow: 0, wantCol: -5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grid := &SpatialGrid{Projection: geo.NewGrid(DefaultGridCellSize, tt.refLat), Cells: make(map[CellKey]*GridCell)}
+
+			cell := grid.cell(tt.lat, tt.lon)
+			if cell.Key != (CellKey{Row: tt.wantRow, Col: tt.wantCol}) {
+				t.Errorf("cell = %+v, want row %d, col %d", cell.Key, tt.wantRow, tt.wantCol)
+			}
+		})
+	}
+}
+
+func TestSpatialGridCellEdges(t *testing.T) {
+	// Ділянки в різних півкулях: від'ємні координати дають від'ємні індекси
+	sites := []struct {
+		name     string
+		lat, lon float64
+	}{
+		{name: "Kyiv", lat: 50.4501, lon: 30.5234},
+		{name: "Sydney", lat: -33.8688, lon: 151.2093},
+		{name: "Santiago", lat: -33.4489, lon: -70.6693},
+		{name: "Reykjavik", lat: 64.1466, lon: -21.9426},
+	}
+
+	for _, site := range sites {
+		t.Run(site.name, func(t *testing.T) {
+			grid := &SpatialGrid{Projection: geo.NewGrid(DefaultGridCellSize, site.lat), Cells: make(map[CellKey]*GridCell)}
+			cell := grid.cell(site.lat, site.lon)
+
+			minLat, minLon, maxLat, maxLon := grid.Projection.Bounds(cell.Key.Row, cell.Key.Col)
+			if site.lat < minLat || site.lat >= maxLat || site.lon < minLon || site.lon >= maxLon {
+				t.Fatalf("point (%v, %v) outside its cell bounds [%v, %v) x [%v, %v)", site.lat, site.lon, minLat, maxLat, minLon, maxLon)
+			}
+			if cell.Latitude <= minLat || cell.Latitude >= maxLat || cell.Longitude <= minLon || cell.Longitude >= maxLon {
+				t.Errorf("centre (%v, %v) outside cell bounds", cell.Latitude, cell.Longitude)
+			}
+
+			// Точки біля кожного краю належать цій комірці, а за краєм - сусідній
+			epsLat := (maxLat - minLat) * 1e-3
+			epsLon := (maxLon - minLon) * 1e-3
+			key := cell.Key
+			edges := []struct {
+				name     string
+				lat, lon float64
+				want     CellKey
+			}{
+				{"inside south edge", minLat + epsLat, cell.Longitude, key},
+				{"inside north edge", maxLat - epsLat, cell.Longitude, key},
+				{"inside west edge", cell.Latitude, minLon + epsLon, key},
+				{"inside east edge", cell.Latitude, maxLon - epsLon, key},
+				{"beyond south edge", minLat - epsLat, cell.Longitude, CellKey{Row: key.Row - 1, Col: key.Col}},
+				{"beyond north edge", maxLat + epsLat, cell.Longitude, CellKey{Row: key.Row + 1, Col: key.Col}},
+				{"beyond west edge", cell.Latitude, minLon - epsLon, CellKey{Row: key.Row, Col: key.Col - 1}},
+				{"beyond east edge", cell.Latitude, maxLon + epsLon, CellKey{Row: key.Row, Col: key.Col + 1}},
+			}
+			for _, edge := range edges {
+				if got := grid.cell(edge.lat, edge.lon).Key; got != edge.want {
+					t.Errorf("%s: cell %+v, want %+v", edge.name, got, edge.want)
+				}
+			}
+
+			// Комірки створюються один раз: сусіди додаються, а повторний запит повертає ту саму
+			if again := grid.cell(site.lat, site.lon); again != cell {
+				t.Error("second lookup created a new cell")
+			}
+			if len(grid.Cells) != 5 {
+				t.Errorf("grid has %d cells, want the cell and its 4 neighbours", len(grid.Cells))
+			}
+		})
+	}
+}
+
+func TestCreateSpatialGridSkipsUnusableSamples(t *testing.T) {
+	detector := NewDetector()
+	magnetic := func(lat, lon float64) Sample {
+		return Sample{
+			SensorType: SensorMagnetic,
+			Latitude:   lat,
+			Longitude:  lon,
+			Data:       &MagneticVector{SampleRate: 10, Readings: []MagneticReading{{X: 48000}}},
+		}
+	}
+
+	samples := []Sample{
+		{SensorType: "sonar", Latitude: 50, Longitude: 30, Data: 1.0},
+		{SensorType: SensorMagnetic, Latitude: 50, Longitude: 30, Data: "not a vector"},
+		magnetic(50.4501, 30.5234),
+		magnetic(50.4501001, 30.5234001),
+		magnetic(50.4502, 30.5234),
+	}
+
+	grid := detector.createSpatialGrid(samples)
+	if len(grid.Cells) != 2 {
+		t.Fatalf("grid has %d cells, want 2", len(grid.Cells))
+	}
+
+	// Опорна широта проєкції береться з першого придатного виміру
+	if want := geo.NewGrid(DefaultGridCellSize, 50.4501); grid.Projection != want {
+		t.Errorf("projection = %+v, want %+v", grid.Projection, want)
+	}
+
+	cell := grid.cell(50.4501, 30.5234)
+	if n := len(cell.Observations[SensorMagnetic]); n != 2 {
+		t.Errorf("first cell has %d magnetic observations, want 2", n)
+	}
+}
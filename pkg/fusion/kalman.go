@@ -0,0 +1,152 @@
+package fusion
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// maxSignalStrength максимальне значення індикатора signalStrength у пакетах пристроїв
+const maxSignalStrength = 255.0
+
+// minSignalStrength нижня межа сили сигналу, щоб шум вимірювання залишався скінченним
+const minSignalStrength = 8.0
+
+// KalmanParams параметри скалярного фільтра Калмана для одного типу сенсора.
+// Значення задаються в одиницях ознаки сенсора (м для ЛІДАР, нТл для магнітометра,
+// нормалізована амплітуда для акустики).
+type KalmanParams struct {
+	// ProcessNoise дисперсія зміни ознаки за секунду (модель випадкового блукання)
+	ProcessNoise float64 `json:"process_noise"`
+	// MeasurementNoise дисперсія вимірювання при максимальній силі сигналу
+	MeasurementNoise float64 `json:"measurement_noise"`
+}
+
+// Validate перевіряє коректність параметрів фільтра
+func (p KalmanParams) Validate() error {
+	if p.ProcessNoise < 0 || math.IsNaN(p.ProcessNoise) || math.IsInf(p.ProcessNoise, 0) {
+		return fmt.Errorf("process noise must be a non-negative number, got %v", p.ProcessNoise)
+	}
+	if p.MeasurementNoise <= 0 || math.IsNaN(p.MeasurementNoise) || math.IsInf(p.MeasurementNoise, 0) {
+		return fmt.Errorf("measurement noise must be a positive number, got %v", p.MeasurementNoise)
+	}
+	return nil
+}
+
+// DefaultKalmanParams повертає параметри фільтра за замовчуванням для кожного
+// зареєстрованого типу сенсора
+func DefaultKalmanParams() map[string]KalmanParams {
+	sensorRegistry.mu.RLock()
+	defer sensorRegistry.mu.RUnlock()
+
+	params := make(map[string]KalmanParams, len(sensorRegistry.specs))
+	for sensorType, spec := range sensorRegistry.specs {
+		params[sensorType] = spec.Kalman
+	}
+	return params
+}
+
+// kalmanFilter реалізує одновимірний фільтр Калмана зі сталою моделлю стану.
+// Ознака комірки вважається незмінною з точністю до процесного шуму,
+// який зростає пропорційно часу між вимірами.
+type kalmanFilter struct {
+	params      KalmanParams
+	state       float64
+	covariance  float64
+	samples     int
+	lastUpdated time.Time
+}
+
+// newKalmanFilter створює фільтр із заданими параметрами
+func newKalmanFilter(params KalmanParams) *kalmanFilter {
+	return &kalmanFilter{params: params}
+}
+
+// update враховує новий вимір із заданою дисперсією шуму вимірювання
+func (f *kalmanFilter) update(value, measurementNoise float64, timestamp time.Time) {
+	// Перший вимір ініціалізує стан з невизначеністю, рівною шуму вимірювання
+	if f.samples == 0 {
+		f.state = value
+		f.covariance = measurementNoise
+		f.samples = 1
+		f.lastUpdated = timestamp
+		return
+	}
+
+	// Прогноз: стан не змінюється, невизначеність зростає з часом
+	elapsed := timestamp.Sub(f.lastUpdated).Seconds()
+	if elapsed > 0 {
+		f.covariance += f.params.ProcessNoise * elapsed
+		f.lastUpdated = timestamp
+	}
+
+	// Корекція
+	gain := f.covariance / (f.covariance + measurementNoise)
+	f.state += gain * (value - f.state)
+	f.covariance *= 1 - gain
+	f.samples++
+}
+
+// estimate повертає поточну оцінку стану фільтра
+func (f *kalmanFilter) estimate() SensorEstimate {
+	return SensorEstimate{
+		Value:      f.state,
+		Covariance: f.covariance,
+		Samples:    f.samples,
+	}
+}
+
+// filterObservations проганяє виміри комірки через фільтр у хронологічному порядку
+func filterObservations(observations []Observation, params KalmanParams) SensorEstimate {
+	ordered := make([]Observation, len(observations))
+	copy(ordered, observations)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Sample.Timestamp.Before(ordered[j].Sample.Timestamp)
+	})
+
+	filter := newKalmanFilter(params)
+	for _, observation := range ordered {
+		noise := measurementNoise(params, observation.Sample.Quality)
+		filter.update(observation.Value, noise, observation.Sample.Timestamp)
+	}
+
+	return filter.estimate()
+}
+
+// measurementNoise масштабує шум вимірювання за силою сигналу з QualityIndicators.
+// Слабкий сигнал обернено пропорційно збільшує дисперсію; якщо індикатор
+// відсутній, використовується базове значення.
+func measurementNoise(params KalmanParams, quality interface{}) float64 {
+	strength, ok := signalStrength(quality)
+	if !ok {
+		return params.MeasurementNoise
+	}
+
+	if strength < minSignalStrength {
+		strength = minSignalStrength
+	}
+	if strength > maxSignalStrength {
+		strength = maxSignalStrength
+	}
+
+	return params.MeasurementNoise * maxSignalStrength / strength
+}
+
+// signalStrength дістає signalStrength з індикаторів якості.
+// Свіжі дані містять ціле число, а прочитані зі сховища - float64.
+func signalStrength(quality interface{}) (float64, bool) {
+	indicators, ok := quality.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+
+	switch value := indicators["signalStrength"].(type) {
+	case int:
+		return float64(value), true
+	case float64:
+		return value, true
+	default:
+		return 0, false
+	}
+}
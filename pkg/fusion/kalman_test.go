@@ -0,0 +1,130 @@
+package fusion
+
+import (
+	"testing"
+	"time"
+)
+
+var kalmanStart = time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+func TestKalmanFilterFirstMeasurement(t *testing.T) {
+	filter := newKalmanFilter(KalmanParams{ProcessNoise: 0.5, MeasurementNoise: 4})
+	filter.update(12, 4, kalmanStart)
+
+	estimate := filter.estimate()
+	if estimate.Value != 12 || estimate.Covariance != 4 || estimate.Samples != 1 {
+		t.Errorf("estimate = %+v, want the measurement with its noise as covariance", estimate)
+	}
+}
+
+func TestKalmanFilterVarianceShrinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		params KalmanParams
+		step   time.Duration
+	}{
+		{name: "simultaneous measurements", params: KalmanParams{ProcessNoise: 1, MeasurementNoise: 4}},
+		{name: "static feature", params: KalmanParams{MeasurementNoise: 4}, step: time.Second},
+		{name: "slow drift", params: KalmanParams{ProcessNoise: 0.01, MeasurementNoise: 4}, step: 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newKalmanFilter(tt.params)
+			previous := 0.0
+			for i := 0; i < 20; i++ {
+				filter.update(5, tt.params.MeasurementNoise, kalmanStart.Add(time.Duration(i)*tt.step))
+
+				estimate := filter.estimate()
+				if i > 0 && estimate.Covariance >= previous {
+					t.Fatalf("measurement %d: covariance %v did not shrink from %v", i, estimate.Covariance, previous)
+				}
+				if !approxEqual(estimate.Value, 5) {
+					t.Fatalf("measurement %d: value %v drifted from constant measurements of 5", i, estimate.Value)
+				}
+				previous = estimate.Covariance
+			}
+
+			// Без процесного шуму n вимірів дають дисперсію R/n
+			if tt.params.ProcessNoise == 0 || tt.step == 0 {
+				if want := tt.params.MeasurementNoise / 20; !approxEqual(previous, want) {
+					t.Errorf("covariance after 20 measurements = %v, want %v", previous, want)
+				}
+			}
+		})
+	}
+}
+
+func TestKalmanFilterProcessNoiseGrowsWithTime(t *testing.T) {
+	params := KalmanParams{ProcessNoise: 0.5, MeasurementNoise: 4}
+
+	covarianceAfter := func(elapsed time.Duration) float64 {
+		filter := newKalmanFilter(params)
+		filter.update(0, params.MeasurementNoise, kalmanStart)
+		filter.update(0, params.MeasurementNoise, kalmanStart.Add(elapsed))
+		return filter.estimate().Covariance
+	}
+
+	previous := 0.0
+	for _, elapsed := range []time.Duration{0, time.Second, 10 * time.Second, time.Minute} {
+		got := covarianceAfter(elapsed)
+
+		// Прогноз додає Q*dt, корекція дає P*R/(P+R)
+		prior := params.MeasurementNoise + params.ProcessNoise*elapsed.Seconds()
+		if want := prior * params.MeasurementNoise / (prior + params.MeasurementNoise); !approxEqual(got, want) {
+			t.Errorf("after %v: covariance %v, want %v", elapsed, got, want)
+		}
+		if got < previous {
+			t.Errorf("after %v: covariance %v is below %v for a shorter interval", elapsed, got, previous)
+		}
+		previous = got
+	}
+
+	// Вимір зі старішою міткою часу не зменшує невизначеність прогнозу
+	if got, want := covarianceAfter(-time.Minute), covarianceAfter(0); !approxEqual(got, want) {
+		t.Errorf("out-of-order measurement: covariance %v, want %v", got, want)
+	}
+}
+
+func TestFilterObservationsOrdersByTime(t *testing.T) {
+	params := KalmanParams{ProcessNoise: 0.2, MeasurementNoise: 1}
+	observation := func(value float64, seconds int) Observation {
+		return Observation{Sample: Sample{Timestamp: kalmanStart.Add(time.Duration(seconds) * time.Second)}, Value: value}
+	}
+
+	ordered := []Observation{observation(1, 0), observation(3, 2), observation(2, 5)}
+	shuffled := []Observation{ordered[2], ordered[0], ordered[1]}
+
+	want := filterObservations(ordered, params)
+	if got := filterObservations(shuffled, params); got != want {
+		t.Errorf("shuffled observations: got %+v, want %+v", got, want)
+	}
+	if shuffled[0].Value != 2 {
+		t.Error("filterObservations reordered the caller's slice")
+	}
+}
+
+func TestMeasurementNoise(t *testing.T) {
+	params := KalmanParams{MeasurementNoise: 2}
+
+	tests := []struct {
+		name    string
+		quality interface{}
+		want    float64
+	}{
+		{name: "no indicators", quality: nil, want: 2},
+		{name: "no signal strength", quality: map[string]interface{}{"battery": 80}, want: 2},
+		{name: "full signal", quality: map[string]interface{}{"signalStrength": 255}, want: 2},
+		{name: "half signal from storage", quality: map[string]interface{}{"signalStrength": 127.5}, want: 4},
+		{name: "signal above range", quality: map[string]interface{}{"signalStrength": 1000}, want: 2},
+		{name: "weak signal is clamped", quality: map[string]interface{}{"signalStrength": 0}, want: 2 * maxSignalStrength / minSignalStrength},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := measurementNoise(params, tt.quality); !approxEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
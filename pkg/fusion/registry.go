@@ -0,0 +1,138 @@
+package fusion
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SensorSpec описує тип сенсора для конвеєра злиття: як декодувати його
+// пакет, яку скалярну ознаку з нього обчислювати та наскільки їй довіряти
+type SensorSpec struct {
+	Type string
+	// Decode перетворює бінарне навантаження пакета на типізовані дані
+	Decode func(data []byte) (interface{}, error)
+	// Feature обчислює ознаку з декодованих або прочитаних зі сховища даних.
+	// Повертає false, якщо дані не містять вимірів.
+	Feature func(data interface{}) (float64, bool, error)
+	// Reliability базова надійність доказів сенсора в (0, 1]
+	Reliability float64
+	// Kalman параметри фільтра Калмана за замовчуванням
+	Kalman KalmanParams
+}
+
+// sensorRegistry зберігає зареєстровані типи сенсорів у порядку реєстрації
+var sensorRegistry = struct {
+	mu    sync.RWMutex
+	specs map[string]SensorSpec
+	order []string
+}{specs: make(map[string]SensorSpec)}
+
+func init() {
+	for _, spec := range []SensorSpec{
+		{
+			Type:        SensorLidar,
+			Decode:      func(data []byte) (interface{}, error) { return ProcessLidarData(data) },
+			Feature:     lidarFeature,
+			Reliability: 0.6,
+			Kalman:      KalmanParams{ProcessNoise: 1e-6, MeasurementNoise: 1e-4}, // σ ≈ 1 см
+		},
+		{
+			Type:        SensorMagnetic,
+			Decode:      func(data []byte) (interface{}, error) { return ProcessMagneticData(data) },
+			Feature:     magneticFeature,
+			Reliability: 0.8,
+			Kalman:      KalmanParams{ProcessNoise: 1.0, MeasurementNoise: 25.0}, // σ ≈ 5 нТл
+		},
+		{
+			Type:        SensorAcoustic,
+			Decode:      func(data []byte) (interface{}, error) { return ProcessAcousticData(data) },
+			Feature:     acousticFeature,
+			Reliability: 0.7,
+			Kalman:      KalmanParams{ProcessNoise: 1e-5, MeasurementNoise: 1e-3},
+		},
+		{
+			Type:        SensorGPR,
+			Decode:      func(data []byte) (interface{}, error) { return ProcessGPRData(data) },
+			Feature:     gprFeature,
+			Reliability: 0.75,
+			Kalman:      KalmanParams{ProcessNoise: 1e-5, MeasurementNoise: 1e-3},
+		},
+		{
+			Type:        SensorThermal,
+			Decode:      func(data []byte) (interface{}, error) { return ProcessThermalData(data) },
+			Feature:     thermalFeature,
+			Reliability: 0.5,
+			Kalman:      KalmanParams{ProcessNoise: 0.01, MeasurementNoise: 0.25}, // σ ≈ 0.5 K
+		},
+	} {
+		if err := RegisterSensor(spec); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterSensor додає новий тип сенсора до конвеєра злиття.
+// Реєстрацію слід виконувати під час ініціалізації, до обробки даних.
+// Щоб ознака нового сенсора впливала на класифікацію, модель
+// класифікатора має містити для нього вузол ознаки.
+func RegisterSensor(spec SensorSpec) error {
+	if spec.Type == "" {
+		return errors.New("sensor type is required")
+	}
+	if spec.Decode == nil || spec.Feature == nil {
+		return fmt.Errorf("sensor %q must provide decoder and feature extractor", spec.Type)
+	}
+	if spec.Reliability <= 0 || spec.Reliability > 1 {
+		return fmt.Errorf("sensor %q reliability must be within (0, 1], got %v", spec.Type, spec.Reliability)
+	}
+	if err := spec.Kalman.Validate(); err != nil {
+		return fmt.Errorf("sensor %q: %w", spec.Type, err)
+	}
+
+	sensorRegistry.mu.Lock()
+	defer sensorRegistry.mu.Unlock()
+
+	if _, exists := sensorRegistry.specs[spec.Type]; exists {
+		return fmt.Errorf("sensor type %q is already registered", spec.Type)
+	}
+
+	sensorRegistry.specs[spec.Type] = spec
+	sensorRegistry.order = append(sensorRegistry.order, spec.Type)
+	return nil
+}
+
+// SensorTypes повертає зареєстровані типи сенсорів у порядку реєстрації
+func SensorTypes() []string {
+	sensorRegistry.mu.RLock()
+	defer sensorRegistry.mu.RUnlock()
+
+	types := make([]string, len(sensorRegistry.order))
+	copy(types, sensorRegistry.order)
+	return types
+}
+
+// IsSensorType повідомляє, чи зареєстрований тип сенсора
+func IsSensorType(sensorType string) bool {
+	_, ok := lookupSensor(sensorType)
+	return ok
+}
+
+// DecodeSensorData декодує бінарне навантаження пакета сенсора заданого типу
+func DecodeSensorData(sensorType string, data []byte) (interface{}, error) {
+	spec, ok := lookupSensor(sensorType)
+	if !ok {
+		return nil, fmt.Errorf("unsupported sensor type %q", sensorType)
+	}
+
+	return spec.Decode(data)
+}
+
+// lookupSensor повертає опис зареєстрованого типу сенсора
+func lookupSensor(sensorType string) (SensorSpec, bool) {
+	sensorRegistry.mu.RLock()
+	defer sensorRegistry.mu.RUnlock()
+
+	spec, ok := sensorRegistry.specs[sensorType]
+	return spec, ok
+}
@@ -0,0 +1,189 @@
+package fusion
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mine-detection-system/pkg/protocol"
+)
+
+// Типи сенсорів, які підтримує конвеєр злиття
+const (
+	SensorLidar    = "lidar"
+	SensorMagnetic = "magnetic"
+	SensorAcoustic = "acoustic"
+	SensorGPR      = "gpr"
+	SensorThermal  = "thermal"
+)
+
+// Типи даних сенсорів визначені протоколом обміну з пристроями
+type (
+	LidarPoint       = protocol.LidarPoint
+	LidarPointCloud  = protocol.LidarPointCloud
+	MagneticReading  = protocol.MagneticReading
+	MagneticVector   = protocol.MagneticVector
+	AcousticWaveform = protocol.AcousticWaveform
+	GPRScan          = protocol.GPRScan
+	ThermalImage     = protocol.ThermalImage
+)
+
+// lidarFeature обчислює нерівність поверхні: стандартне відхилення висоти точок, м.
+// Свіжо встановлена міна залишає горбик або просідання ґрунту.
+func lidarFeature(data interface{}) (float64, bool, error) {
+	cloud, err := decodeStored[LidarPointCloud](data)
+	if err != nil || len(cloud.Points) == 0 {
+		return 0, false, err
+	}
+
+	heights := make([]float64, len(cloud.Points))
+	for i, point := range cloud.Points {
+		heights[i] = point.Range * math.Cos(point.Angle)
+	}
+
+	return stdDev(heights), true, nil
+}
+
+// magneticFeature обчислює магнітну аномалію: стандартне відхилення модуля поля, нТл.
+// Модуль поля Землі (~50 000 нТл) сам по собі не інформативний, тоді як
+// металевий об'єкт спотворює поле на ділянці, яку проходить сенсор.
+func magneticFeature(data interface{}) (float64, bool, error) {
+	vector, err := decodeStored[MagneticVector](data)
+	if err != nil || len(vector.Readings) == 0 {
+		return 0, false, err
+	}
+
+	magnitudes := make([]float64, len(vector.Readings))
+	for i, reading := range vector.Readings {
+		magnitudes[i] = math.Sqrt(reading.X*reading.X + reading.Y*reading.Y + reading.Z*reading.Z)
+	}
+
+	return stdDev(magnitudes), true, nil
+}
+
+// acousticFeature обчислює середньоквадратичну амплітуду сигналу
+func acousticFeature(data interface{}) (float64, bool, error) {
+	waveform, err := decodeStored[AcousticWaveform](data)
+	if err != nil || len(waveform.Samples) == 0 {
+		return 0, false, err
+	}
+
+	sum := 0.0
+	for _, sample := range waveform.Samples {
+		sum += sample * sample
+	}
+
+	return math.Sqrt(sum / float64(len(waveform.Samples))), true, nil
+}
+
+// gprFeature обчислює силу відбиття від локального об'єкта: найбільшу амплітуду
+// після видалення фону. Фоном вважається середній A-скан серії, який містить
+// пряму хвилю та відбиття від шарів ґрунту; для одного A-скану видаляється
+// лише постійна складова.
+func gprFeature(data interface{}) (float64, bool, error) {
+	scan, err := decodeStored[GPRScan](data)
+	if err != nil || len(scan.Traces) == 0 || len(scan.Traces[0]) == 0 {
+		return 0, false, err
+	}
+
+	samples := len(scan.Traces[0])
+	background := make([]float64, samples)
+	if len(scan.Traces) > 1 {
+		for _, trace := range scan.Traces {
+			if len(trace) != samples {
+				return 0, false, fmt.Errorf("gpr traces have different lengths: %d and %d", len(trace), samples)
+			}
+			for i, amplitude := range trace {
+				background[i] += amplitude / float64(len(scan.Traces))
+			}
+		}
+	} else {
+		mean := 0.0
+		for _, amplitude := range scan.Traces[0] {
+			mean += amplitude / float64(samples)
+		}
+		for i := range background {
+			background[i] = mean
+		}
+	}
+
+	peak := 0.0
+	for _, trace := range scan.Traces {
+		for i, amplitude := range trace {
+			peak = math.Max(peak, math.Abs(amplitude-background[i]))
+		}
+	}
+
+	return peak, true, nil
+}
+
+// thermalFeature обчислює тепловий контраст: найбільше відхилення температури
+// пікселя від середньої по кадру, K. Ґрунт над закладеним об'єктом нагрівається
+// й охолоджується інакше, ніж навколишній.
+func thermalFeature(data interface{}) (float64, bool, error) {
+	image, err := decodeStored[ThermalImage](data)
+	if err != nil || len(image.Temperatures) == 0 {
+		return 0, false, err
+	}
+
+	mean := 0.0
+	for _, temperature := range image.Temperatures {
+		mean += temperature
+	}
+	mean /= float64(len(image.Temperatures))
+
+	contrast := 0.0
+	for _, temperature := range image.Temperatures {
+		contrast = math.Max(contrast, math.Abs(temperature-mean))
+	}
+
+	return contrast, true, nil
+}
+
+// decodeStored приводить поле SensorData.Data до типізованого навантаження.
+// Щойно оброблені дані вже мають потрібний тип, а прочитані зі сховища
+// представлені як JSON-об'єкт і конвертуються через повторну серіалізацію.
+func decodeStored[T any](data interface{}) (*T, error) {
+	switch value := data.(type) {
+	case *T:
+		if value == nil {
+			return nil, fmt.Errorf("empty %T payload", value)
+		}
+		return value, nil
+	case T:
+		return &value, nil
+	case nil:
+		return nil, fmt.Errorf("missing %T payload", new(T))
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var result T
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("invalid %T payload: %w", result, err)
+	}
+
+	return &result, nil
+}
+
+// stdDev обчислює стандартне відхилення вибірки
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - mean) * (value - mean)
+	}
+
+	return math.Sqrt(variance / float64(len(values)-1))
+}
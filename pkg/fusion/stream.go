@@ -0,0 +1,154 @@
+package fusion
+
+import "mine-detection-system/pkg/geo"
+
+// Stream інкрементальний стан злиття даних одного сканування.
+// Кожен новий вимір оновлює фільтр Калмана лише своєї комірки, після чого
+// комірка перекласифіковується. Stream не зберігає самі виміри, тож пам'ять
+// залежить від площі сканування, а не від кількості пакетів.
+// Stream не безпечний для одночасного використання з кількох горутин.
+type Stream struct {
+	detector   *Detector
+	grid       *SpatialGrid
+	gridReady  bool
+	filters    map[CellKey]map[string]*kalmanFilter
+	classified map[CellKey]*Classification
+	// alerted комірки, що належать кластерам, про які вже повідомлено
+	alerted map[CellKey]bool
+	samples int
+}
+
+// Coverage описує площу, охоплену скануванням
+type Coverage struct {
+	// Cells кількість комірок сітки, в яких є хоча б один вимір
+	Cells int `json:"cells"`
+	// Area охоплена площа, м²
+	Area float64 `json:"area"`
+	// Samples кількість врахованих вимірів
+	Samples int `json:"samples"`
+}
+
+// NewStream створює інкрементальний стан злиття з налаштуваннями детектора
+func (d *Detector) NewStream() *Stream {
+	return &Stream{
+		detector:   d,
+		grid:       &SpatialGrid{Cells: make(map[CellKey]*GridCell)},
+		filters:    make(map[CellKey]map[string]*kalmanFilter),
+		classified: make(map[CellKey]*Classification),
+		alerted:    make(map[CellKey]bool),
+	}
+}
+
+// Add враховує новий вимір і повертає попередню детекцію, якщо комірка виміру
+// щойно перетнула поріг і утворила новий кластер. Про кожен кластер повідомляється
+// один раз, навіть якщо згодом він розростається. Виміри невідомих типів або з
+// пошкодженим навантаженням пропускаються, як і в пакетному режимі.
+func (s *Stream) Add(sample Sample) (*Detection, bool) {
+	spec, ok := lookupSensor(sample.SensorType)
+	if !ok {
+		return nil, false
+	}
+
+	value, ok, err := spec.Feature(sample.Data)
+	if err != nil || !ok {
+		return nil, false
+	}
+
+	if !s.gridReady {
+		s.grid.Projection = geo.NewGrid(s.detector.gridCellSize, sample.Latitude)
+		s.gridReady = true
+	}
+
+	cell := s.grid.cell(sample.Latitude, sample.Longitude)
+	s.samples++
+
+	// Оновлення фільтра Калмана для каналу сенсора в цій комірці
+	channels, ok := s.filters[cell.Key]
+	if !ok {
+		channels = make(map[string]*kalmanFilter)
+		s.filters[cell.Key] = channels
+	}
+	params := s.detector.kalmanParams[sample.SensorType]
+	filter, ok := channels[sample.SensorType]
+	if !ok {
+		filter = newKalmanFilter(params)
+		channels[sample.SensorType] = filter
+	}
+	filter.update(value, measurementNoise(params, sample.Quality), sample.Timestamp)
+
+	// Перекласифікація комірки
+	filtered := &FilteredCell{
+		Cell:      cell,
+		Estimates: make(map[string]SensorEstimate, len(channels)),
+	}
+	for sensorType, channel := range channels {
+		filtered.Estimates[sensorType] = channel.estimate()
+	}
+	classification := s.detector.classifyCell(filtered)
+	s.classified[cell.Key] = classification
+
+	if classification.MineProbability < s.detector.confidenceThreshold {
+		return nil, false
+	}
+
+	// Комірка приєднується до кластера, про який уже повідомлено
+	if s.alerted[cell.Key] || s.hasAlertedNeighbour(cell.Key) {
+		s.alerted[cell.Key] = true
+		return nil, false
+	}
+
+	// Комірка над порогом: шукаємо її кластер і повідомляємо, якщо він новий
+	for _, cluster := range s.detector.clusterCells(s.classified) {
+		if !clusterContains(cluster, cell.Key) {
+			continue
+		}
+
+		isNew := true
+		for _, member := range cluster {
+			if s.alerted[member.Cell.Key] {
+				isNew = false
+			}
+			s.alerted[member.Cell.Key] = true
+		}
+		if !isNew {
+			return nil, false
+		}
+
+		detection := s.detector.clusterDetection(s.grid.Projection, cluster)
+		return &detection, true
+	}
+
+	return nil, false
+}
+
+// Coverage повертає поточне покриття сканування
+func (s *Stream) Coverage() Coverage {
+	cellSize := s.detector.gridCellSize
+	return Coverage{
+		Cells:   len(s.grid.Cells),
+		Area:    float64(len(s.grid.Cells)) * cellSize * cellSize,
+		Samples: s.samples,
+	}
+}
+
+// hasAlertedNeighbour перевіряє, чи межує комірка з коміркою кластера, про який уже повідомлено
+func (s *Stream) hasAlertedNeighbour(key CellKey) bool {
+	for dRow := -1; dRow <= 1; dRow++ {
+		for dCol := -1; dCol <= 1; dCol++ {
+			if s.alerted[CellKey{Row: key.Row + dRow, Col: key.Col + dCol}] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clusterContains перевіряє, чи містить кластер комірку
+func clusterContains(cluster []*Classification, key CellKey) bool {
+	for _, classification := range cluster {
+		if classification.Cell.Key == key {
+			return true
+		}
+	}
+	return false
+}
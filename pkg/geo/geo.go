@@ -0,0 +1,33 @@
+package geo
+
+import "math"
+
+// EarthRadius середній радіус Землі в метрах
+const EarthRadius = 6371008.8
+
+// Distance обчислює відстань між двома точками за формулою гаверсинусів (у метрах)
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+
+	return 2 * EarthRadius * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// BoundingBox повертає прямокутник (minLat, minLon, maxLat, maxLon), що містить коло заданого радіуса
+func BoundingBox(lat, lon, radius float64) (float64, float64, float64, float64) {
+	dLat := radius / EarthRadius * 180 / math.Pi
+
+	// Поблизу полюсів довгота вироджується, тому беремо весь діапазон
+	cosLat := math.Cos(lat * math.Pi / 180)
+	if cosLat < 1e-9 {
+		return math.Max(lat-dLat, -90), -180, math.Min(lat+dLat, 90), 180
+	}
+	dLon := dLat / cosLat
+
+	return math.Max(lat-dLat, -90), lon - dLon, math.Min(lat+dLat, 90), lon + dLon
+}
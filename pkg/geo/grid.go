@@ -0,0 +1,53 @@
+package geo
+
+import "math"
+
+// metersPerDegree довжина одного градуса меридіана в метрах
+const metersPerDegree = EarthRadius * math.Pi / 180
+
+// Grid розбиває поверхню на квадратні комірки заданого розміру в метрах.
+// Використовується рівнопроміжна проєкція з масштабом довготи, зафіксованим
+// на опорній широті, що достатньо точно для ділянок розміром у кілька кілометрів.
+type Grid struct {
+	CellSize float64
+	lonScale float64
+}
+
+// NewGrid створює сітку з кроком cellSize метрів для околиць опорної широти refLat
+func NewGrid(cellSize, refLat float64) Grid {
+	lonScale := math.Cos(refLat * math.Pi / 180)
+	if lonScale < 1e-6 {
+		lonScale = 1e-6
+	}
+
+	return Grid{
+		CellSize: cellSize,
+		lonScale: lonScale,
+	}
+}
+
+// Cell повертає індекси (рядок, стовпець) комірки, що містить точку
+func (g Grid) Cell(lat, lon float64) (int, int) {
+	row := int(math.Floor(lat * metersPerDegree / g.CellSize))
+	col := int(math.Floor(lon * metersPerDegree * g.lonScale / g.CellSize))
+
+	return row, col
+}
+
+// Center повертає координати центру комірки
+func (g Grid) Center(row, col int) (float64, float64) {
+	lat := (float64(row) + 0.5) * g.CellSize / metersPerDegree
+	lon := (float64(col) + 0.5) * g.CellSize / (metersPerDegree * g.lonScale)
+
+	return lat, lon
+}
+
+// Bounds повертає межі комірки
+func (g Grid) Bounds(row, col int) (minLat, minLon, maxLat, maxLon float64) {
+	minLat = float64(row) * g.CellSize / metersPerDegree
+	maxLat = float64(row+1) * g.CellSize / metersPerDegree
+	minLon = float64(col) * g.CellSize / (metersPerDegree * g.lonScale)
+	maxLon = float64(col+1) * g.CellSize / (metersPerDegree * g.lonScale)
+
+	return minLat, minLon, maxLat, maxLon
+}
@@ -0,0 +1,63 @@
+package geo
+
+import "sort"
+
+// Point представляє точку на поверхні
+type Point struct {
+	Lat float64
+	Lon float64
+}
+
+// ConvexHull будує опуклу оболонку точок алгоритмом монотонного ланцюга.
+// Повертає замкнене кільце проти годинникової стрілки (перша точка повторюється
+// в кінці), придатне для GeoJSON Polygon. Для менш ніж трьох різних точок
+// оболонка вироджена і повертається nil.
+func ConvexHull(points []Point) []Point {
+	sorted := make([]Point, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Lon != sorted[j].Lon {
+			return sorted[i].Lon < sorted[j].Lon
+		}
+		return sorted[i].Lat < sorted[j].Lat
+	})
+
+	// Видалення дублікатів
+	unique := sorted[:0]
+	for i, point := range sorted {
+		if i == 0 || point != sorted[i-1] {
+			unique = append(unique, point)
+		}
+	}
+	if len(unique) < 3 {
+		return nil
+	}
+
+	// cross > 0, якщо поворот o -> a -> b проти годинникової стрілки
+	cross := func(o, a, b Point) float64 {
+		return (a.Lon-o.Lon)*(b.Lat-o.Lat) - (a.Lat-o.Lat)*(b.Lon-o.Lon)
+	}
+
+	hull := make([]Point, 0, 2*len(unique))
+	for _, point := range unique {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], point) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, point)
+	}
+	lower := len(hull) + 1
+	for i := len(unique) - 2; i >= 0; i-- {
+		point := unique[i]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], point) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, point)
+	}
+
+	if len(hull) < 4 {
+		// Усі точки на одній прямій
+		return nil
+	}
+
+	return hull
+}
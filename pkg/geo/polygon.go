@@ -0,0 +1,114 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ValidatePolygon перевіряє, що об'єкт є коректною геометрією GeoJSON типу
+// Polygon або MultiPolygon (RFC 7946): кільця замкнені, мають щонайменше
+// чотири позиції, координати в межах WGS84, а площа кожного кільця ненульова.
+func ValidatePolygon(geometry map[string]interface{}) error {
+	if len(geometry) == 0 {
+		return errors.New("geometry is empty")
+	}
+
+	// Нормалізація через JSON дозволяє однаково обробляти дані, отримані з API
+	// ([]interface{}) та створені в коді ([][][]float64)
+	raw, err := json.Marshal(geometry)
+	if err != nil {
+		return fmt.Errorf("geometry is not serializable: %w", err)
+	}
+
+	var object struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return fmt.Errorf("invalid geometry: %w", err)
+	}
+
+	switch object.Type {
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(object.Coordinates, &rings); err != nil {
+			return fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		return validateRings(rings)
+
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(object.Coordinates, &polygons); err != nil {
+			return fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+		if len(polygons) == 0 {
+			return errors.New("MultiPolygon has no polygons")
+		}
+		for i, rings := range polygons {
+			if err := validateRings(rings); err != nil {
+				return fmt.Errorf("polygon %d: %w", i, err)
+			}
+		}
+		return nil
+
+	case "":
+		return errors.New("geometry type is missing")
+
+	default:
+		return fmt.Errorf("geometry type %q is not a polygon", object.Type)
+	}
+}
+
+// validateRings перевіряє зовнішнє кільце та отвори одного полігона
+func validateRings(rings [][][]float64) error {
+	if len(rings) == 0 {
+		return errors.New("polygon has no rings")
+	}
+
+	for i, ring := range rings {
+		if err := validateRing(ring); err != nil {
+			return fmt.Errorf("ring %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateRing перевіряє одне лінійне кільце
+func validateRing(ring [][]float64) error {
+	if len(ring) < 4 {
+		return fmt.Errorf("ring must have at least 4 positions, got %d", len(ring))
+	}
+
+	for i, position := range ring {
+		if len(position) < 2 || len(position) > 3 {
+			return fmt.Errorf("position %d must have 2 or 3 values, got %d", i, len(position))
+		}
+
+		lon, lat := position[0], position[1]
+		if math.IsNaN(lon) || lon < -180 || lon > 180 {
+			return fmt.Errorf("position %d: longitude %v out of range", i, lon)
+		}
+		if math.IsNaN(lat) || lat < -90 || lat > 90 {
+			return fmt.Errorf("position %d: latitude %v out of range", i, lat)
+		}
+	}
+
+	first, last := ring[0], ring[len(ring)-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		return errors.New("ring is not closed")
+	}
+
+	// Подвоєна площа за формулою шнурування; нуль означає вироджене кільце
+	area := 0.0
+	for i := 0; i < len(ring)-1; i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	if math.Abs(area) < 1e-14 {
+		return errors.New("ring has zero area")
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/google/uuid"
+	"math"
+	"time"
+)
+
+// batchHeaderSize розмір заголовка навантаження кадру з кількістю вимірів
+const batchHeaderSize = 2
+
+// batchMinSampleSize найменший розмір запису виміру: тип, чотири зміни
+// метаданих і довжина навантаження по одному байту, навантаження порожнє
+const batchMinSampleSize = 6
+
+// BatchSample один вимір кадру PacketTypeBatch
+type BatchSample struct {
+	// Type код типу пакета сенсора, наприклад PacketTypeLidar
+	Type byte
+	// Metadata положення і час виміру. Індикатори якості спільні для кадру:
+	// під час кодування береться Quality першого виміру.
+	Metadata Metadata
+	// Payload навантаження сенсора, сформоване Encode-функцією його типу
+	Payload []byte
+}
+
+// NewBatchPacket формує кадр поточної версії протоколу з кількома вимірами.
+// Метадані кадру беруться з першого виміру; порядковий номер і прапорці
+// (запит підтвердження, стиснення) задає викликач.
+//
+// Формат навантаження кадру:
+//
+//	offset  size     field
+//	0       2        uint16 (big-endian) кількість вимірів N (> 0)
+//	2       ...      N записів:
+//	                 uint8    тип пакета сенсора
+//	                 varint   зміна широти, 1e-7 градуса
+//	                 varint   зміна довготи, 1e-7 градуса
+//	                 varint   зміна висоти, мм
+//	                 varint   зміна часу пристрою, мкс
+//	                 uvarint  довжина навантаження P
+//	                 P        навантаження сенсора
+//
+// Зміни відраховуються від попереднього виміру, для першого - від метаданих
+// кадру. varint і uvarint кодуються як у encoding/binary (LEB128, для знакових
+// значень - zigzag). Якщо метадані кадру не містять часу пристрою, зміни часу
+// мають бути нульовими, а виміри не мають часу пристрою.
+func NewBatchPacket(scanID uuid.UUID, samples []BatchSample) (*Packet, error) {
+	if len(samples) == 0 || len(samples) > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: batch sample count %d out of range 1..%d", ErrMalformedPacket, len(samples), math.MaxUint16)
+	}
+
+	base := samples[0].Metadata
+	timed := !base.DeviceTime.IsZero()
+	previous, err := batchPosition(base)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, batchHeaderSize, batchHeaderSize+len(samples)*16)
+	binary.BigEndian.PutUint16(payload, uint16(len(samples)))
+	for i, sample := range samples {
+		if sample.Type == PacketTypeBatch {
+			return nil, fmt.Errorf("%w: batch sample %d is a batch", ErrMalformedPacket, i)
+		}
+		if !sample.Metadata.DeviceTime.IsZero() != timed {
+			return nil, fmt.Errorf("%w: batch sample %d device time must be set for all samples or none", ErrMalformedPacket, i)
+		}
+
+		position, err := batchPosition(sample.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("batch sample %d: %w", i, err)
+		}
+
+		payload = append(payload, sample.Type)
+		for k := range position {
+			payload = binary.AppendVarint(payload, position[k]-previous[k])
+		}
+		payload = binary.AppendUvarint(payload, uint64(len(sample.Payload)))
+		payload = append(payload, sample.Payload...)
+		previous = position
+	}
+
+	return &Packet{
+		Version:  CurrentVersion,
+		Type:     PacketTypeBatch,
+		ScanID:   scanID,
+		Metadata: base,
+		Payload:  payload,
+	}, nil
+}
+
+// DecodeBatch розбирає виміри кадру PacketTypeBatch. Навантаження вимірів
+// посилаються на p.Payload без копіювання.
+func DecodeBatch(p *Packet) ([]BatchSample, error) {
+	if p.Type != PacketTypeBatch {
+		return nil, fmt.Errorf("%w: packet type 0x%02x is not a batch", ErrMalformedPacket, p.Type)
+	}
+
+	data := p.Payload
+	if len(data) < batchHeaderSize {
+		return nil, fmt.Errorf("%w: batch payload has %d bytes, header needs %d", ErrTruncatedPacket, len(data), batchHeaderSize)
+	}
+
+	count := int(binary.BigEndian.Uint16(data[0:2]))
+	if count == 0 {
+		return nil, fmt.Errorf("%w: batch contains no samples", ErrMalformedPacket)
+	}
+	// Кількість перевіряється до виділення пам'яті під виміри: короткий кадр
+	// не повинен змушувати сервер виділяти місце під 65535 записів
+	if maxCount := (len(data) - batchHeaderSize) / batchMinSampleSize; count > maxCount {
+		return nil, fmt.Errorf("%w: batch declares %d samples, %d bytes hold at most %d", ErrTruncatedPacket, count, len(data)-batchHeaderSize, maxCount)
+	}
+
+	timed := !p.Metadata.DeviceTime.IsZero()
+	position, err := batchPosition(p.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]BatchSample, count)
+	offset := batchHeaderSize
+	for i := range samples {
+		if offset >= len(data) {
+			return nil, fmt.Errorf("%w: batch sample %d is missing", ErrTruncatedPacket, i)
+		}
+		sampleType := data[offset]
+		offset++
+		if sampleType == PacketTypeBatch {
+			return nil, fmt.Errorf("%w: batch sample %d is a batch", ErrMalformedPacket, i)
+		}
+
+		for k := range position {
+			delta, n := binary.Varint(data[offset:])
+			if n <= 0 {
+				return nil, fmt.Errorf("%w: batch sample %d has invalid metadata delta", ErrMalformedPacket, i)
+			}
+			position[k] += delta
+			offset += n
+		}
+
+		length, n := binary.Uvarint(data[offset:])
+		if n <= 0 {
+			return nil, fmt.Errorf("%w: batch sample %d has invalid payload length", ErrMalformedPacket, i)
+		}
+		offset += n
+		if length > uint64(len(data)-offset) {
+			return nil, fmt.Errorf("%w: batch sample %d needs %d bytes, got %d", ErrTruncatedPacket, i, length, len(data)-offset)
+		}
+
+		metadata, err := batchMetadata(position, timed, p.Metadata.Quality)
+		if err != nil {
+			return nil, fmt.Errorf("batch sample %d: %w", i, err)
+		}
+
+		samples[i] = BatchSample{
+			Type:     sampleType,
+			Metadata: metadata,
+			Payload:  data[offset : offset+int(length)],
+		}
+		offset += int(length)
+	}
+
+	if offset != len(data) {
+		return nil, fmt.Errorf("%w: batch has %d trailing bytes", ErrMalformedPacket, len(data)-offset)
+	}
+
+	return samples, nil
+}
+
+// batchPosition переводить положення і час виміру в цілі одиниці кадру:
+// широта і довгота в 1e-7 градуса, висота в мм, час у мкс
+func batchPosition(m Metadata) ([4]int64, error) {
+	if err := m.validatePosition(); err != nil {
+		return [4]int64{}, err
+	}
+
+	altitude, err := scaleInt32(m.Altitude, 1000, "altitude")
+	if err != nil {
+		return [4]int64{}, err
+	}
+
+	var deviceTime int64
+	if !m.DeviceTime.IsZero() {
+		deviceTime = m.DeviceTime.UnixMicro()
+	}
+
+	return [4]int64{
+		int64(math.Round(m.Latitude * 1e7)),
+		int64(math.Round(m.Longitude * 1e7)),
+		int64(altitude),
+		deviceTime,
+	}, nil
+}
+
+// batchMetadata відновлює метадані виміру з цілих одиниць кадру
+func batchMetadata(position [4]int64, timed bool, quality Quality) (Metadata, error) {
+	metadata := Metadata{
+		Latitude:  float64(position[0]) / 1e7,
+		Longitude: float64(position[1]) / 1e7,
+		Altitude:  float64(position[2]) / 1000.0,
+		Quality:   quality,
+	}
+	if err := metadata.validatePosition(); err != nil {
+		return Metadata{}, err
+	}
+
+	switch {
+	case timed:
+		metadata.DeviceTime = time.UnixMicro(position[3]).UTC()
+	case position[3] != 0:
+		return Metadata{}, fmt.Errorf("%w: device time delta without frame device time", ErrMalformedPacket)
+	}
+
+	return metadata, nil
+}
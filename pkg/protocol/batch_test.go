@@ -0,0 +1,218 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// batchSamples виміри з положенням у межах точності кадру (1e-7 градуса, мм, мкс)
+// і спільними індикаторами якості, тож кадр передає їх без втрат
+func batchSamples(timed bool) []BatchSample {
+	positions := []Metadata{
+		{Latitude: 50.4501, Longitude: 30.5234, Altitude: 123.456},
+		{Latitude: 50.4501012, Longitude: 30.5233987, Altitude: 123.401},
+		{Latitude: 50.4500998, Longitude: 30.5234005, Altitude: 124.002},
+	}
+	payloads := [][]byte{testPayload(), {}, {0xFF}}
+	types := []byte{PacketTypeMagnetic, PacketTypeGPR, PacketTypeMagnetic}
+
+	samples := make([]BatchSample, len(positions))
+	for i, metadata := range positions {
+		metadata.Quality = fullQuality()
+		if timed {
+			metadata.DeviceTime = time.UnixMicro(1760600000123456 + int64(i)*2500).UTC()
+		}
+		samples[i] = BatchSample{Type: types[i], Metadata: metadata, Payload: payloads[i]}
+	}
+	return samples
+}
+
+func TestBatchRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []BatchSample
+	}{
+		{name: "with device time", samples: batchSamples(true)},
+		{name: "without device time", samples: batchSamples(false)},
+		{name: "single sample", samples: batchSamples(true)[:1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet, err := NewBatchPacket(testScanID(), tt.samples)
+			if err != nil {
+				t.Fatalf("NewBatchPacket: %v", err)
+			}
+			if packet.Type != PacketTypeBatch || packet.Version != CurrentVersion {
+				t.Fatalf("frame type 0x%02x version %d, want batch of version %d", packet.Type, packet.Version, CurrentVersion)
+			}
+			packet.Sequence = 12
+			packet.Flags = FlagAckRequested
+
+			data, err := EncodePacket(packet)
+			if err != nil {
+				t.Fatalf("EncodePacket: %v", err)
+			}
+			decoded, err := DecodePacket(data)
+			if err != nil {
+				t.Fatalf("DecodePacket: %v", err)
+			}
+
+			samples, err := DecodeBatch(decoded)
+			if err != nil {
+				t.Fatalf("DecodeBatch: %v", err)
+			}
+			if !reflect.DeepEqual(samples, tt.samples) {
+				t.Errorf("decoded samples differ\n got: %+v\nwant: %+v", samples, tt.samples)
+			}
+		})
+	}
+}
+
+func TestBatchSharesFirstSampleQuality(t *testing.T) {
+	samples := batchSamples(false)
+	samples[1].Metadata.Quality = Quality{Battery: intPtr(5)}
+
+	packet, err := NewBatchPacket(testScanID(), samples)
+	if err != nil {
+		t.Fatalf("NewBatchPacket: %v", err)
+	}
+	decoded, err := DecodeBatch(packet)
+	if err != nil {
+		t.Fatalf("DecodeBatch: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded[1].Metadata.Quality, fullQuality()) {
+		t.Errorf("second sample quality = %+v, want quality of the first sample", decoded[1].Metadata.Quality)
+	}
+}
+
+func TestNewBatchPacketRejectsInvalidSamples(t *testing.T) {
+	nested := batchSamples(true)
+	nested[1].Type = PacketTypeBatch
+
+	mixedTime := batchSamples(true)
+	mixedTime[2].Metadata.DeviceTime = time.Time{}
+
+	outOfRange := batchSamples(false)
+	outOfRange[1].Metadata.Latitude = 91
+
+	tests := []struct {
+		name    string
+		samples []BatchSample
+	}{
+		{name: "no samples", samples: nil},
+		{name: "nested batch", samples: nested},
+		{name: "device time on some samples", samples: mixedTime},
+		{name: "latitude out of range", samples: outOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewBatchPacket(testScanID(), tt.samples); !errors.Is(err, ErrMalformedPacket) {
+				t.Errorf("got error %v, want %v", err, ErrMalformedPacket)
+			}
+		})
+	}
+}
+
+func TestDecodeBatchRejectsMalformedFrames(t *testing.T) {
+	// batchFrame кадр без часу пристрою з одним записом, сформованим вручну
+	batchFrame := func(sampleType byte, timeDelta int64, payloadLength uint64, trailing ...byte) *Packet {
+		payload := binary.BigEndian.AppendUint16(nil, 1)
+		payload = append(payload, sampleType)
+		payload = binary.AppendVarint(payload, 0)
+		payload = binary.AppendVarint(payload, 0)
+		payload = binary.AppendVarint(payload, 0)
+		payload = binary.AppendVarint(payload, timeDelta)
+		payload = binary.AppendUvarint(payload, payloadLength)
+		payload = append(payload, trailing...)
+		return &Packet{Version: CurrentVersion, Type: PacketTypeBatch, Metadata: positionMetadata(), Payload: payload}
+	}
+
+	tests := []struct {
+		name   string
+		packet *Packet
+		want   error
+	}{
+		{
+			name:   "not a batch",
+			packet: packetFor(ProtocolVersion3, 0),
+			want:   ErrMalformedPacket,
+		},
+		{
+			name:   "no samples",
+			packet: &Packet{Version: CurrentVersion, Type: PacketTypeBatch, Metadata: positionMetadata(), Payload: []byte{0, 0}},
+			want:   ErrMalformedPacket,
+		},
+		{
+			name:   "nested batch",
+			packet: batchFrame(PacketTypeBatch, 0, 1, 0xAA),
+			want:   ErrMalformedPacket,
+		},
+		{
+			name:   "time delta without frame device time",
+			packet: batchFrame(PacketTypeMagnetic, 1000, 1, 0xAA),
+			want:   ErrMalformedPacket,
+		},
+		{
+			name:   "payload longer than frame",
+			packet: batchFrame(PacketTypeMagnetic, 0, 2, 0xAA),
+			want:   ErrTruncatedPacket,
+		},
+		{
+			name:   "trailing bytes",
+			packet: batchFrame(PacketTypeMagnetic, 0, 1, 0xAA, 0xBB),
+			want:   ErrMalformedPacket,
+		},
+		{
+			name:   "second sample missing",
+			packet: &Packet{Version: CurrentVersion, Type: PacketTypeBatch, Metadata: positionMetadata(), Payload: append([]byte{0, 2}, batchFrame(PacketTypeMagnetic, 0, 1, 0xAA).Payload[2:]...)},
+			want:   ErrTruncatedPacket,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeBatch(tt.packet); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBatchRejectsHugeCount(t *testing.T) {
+	payload := []byte{0xFF, 0xFF, PacketTypeMagnetic, 0, 0, 0, 0, 0, 0, 0}
+	packet := &Packet{Version: CurrentVersion, Type: PacketTypeBatch, Metadata: positionMetadata(), Payload: payload}
+
+	// Кадр з 10 байтів не може вмістити 65535 вимірів, тож пам'ять під них не виділяється
+	allocated := allocatedBytes(func() {
+		if _, err := DecodeBatch(packet); !errors.Is(err, ErrTruncatedPacket) {
+			t.Errorf("got error %v, want %v", err, ErrTruncatedPacket)
+		}
+	})
+	if allocated > 4096 {
+		t.Errorf("rejecting the frame allocated %d bytes", allocated)
+	}
+}
+
+func TestDecodeBatchTruncated(t *testing.T) {
+	packet, err := NewBatchPacket(testScanID(), batchSamples(true))
+	if err != nil {
+		t.Fatalf("NewBatchPacket: %v", err)
+	}
+
+	// Обрізане навантаження ніколи не розбирається успішно: обрив посеред varint
+	// дає ErrMalformedPacket, решта випадків - ErrTruncatedPacket
+	payload := packet.Payload
+	for n := 0; n < len(payload); n++ {
+		packet.Payload = payload[:n]
+		_, err := DecodeBatch(packet)
+		if !errors.Is(err, ErrTruncatedPacket) && !errors.Is(err, ErrMalformedPacket) {
+			t.Errorf("%d of %d bytes: got error %v, want truncated or malformed packet", n, len(payload), err)
+		}
+	}
+}
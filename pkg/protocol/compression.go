@@ -0,0 +1,171 @@
+package protocol
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"sync"
+)
+
+// Алгоритми стиснення навантаження, які задаються бітами 1-2 прапорців заголовка
+const (
+	CompressionNone byte = 0x00
+	// CompressionDeflate сирий DEFLATE (RFC 1951) без заголовків zlib або gzip
+	CompressionDeflate byte = 0x02
+	// CompressionZstd кадр Zstandard (RFC 8878)
+	CompressionZstd byte = 0x04
+	// FlagCompressionMask біти прапорців, що задають алгоритм стиснення
+	FlagCompressionMask byte = 0x06
+)
+
+// MaxPayloadSize найбільший розмір навантаження після розпакування, який приймає
+// DecodePacket. Обмеження захищає від пакетів, що розпаковуються в гігабайти;
+// DecodePacketLimit дозволяє задати менше.
+const MaxPayloadSize = 16 << 20
+
+// ErrUnsupportedCompression повертається для пакета, стиснутого алгоритмом без зареєстрованого кодека
+var ErrUnsupportedCompression = errors.New("unsupported payload compression")
+
+// Codec стискає та розпаковує навантаження пакетів
+type Codec struct {
+	Name     string
+	Compress func(data []byte) ([]byte, error)
+	// Decompress розпаковує дані; результат понад limit байтів є помилкою
+	Decompress func(data []byte, limit int) ([]byte, error)
+}
+
+// codecs зберігає зареєстровані кодеки за значенням бітів стиснення
+var codecs = struct {
+	mu   sync.RWMutex
+	byID map[byte]Codec
+}{
+	byID: map[byte]Codec{
+		CompressionDeflate: {Name: "deflate", Compress: deflateCompress, Decompress: deflateDecompress},
+		CompressionZstd:    {Name: "zstd", Compress: zstdCompress, Decompress: zstdDecompress},
+	},
+}
+
+// RegisterCodec додає кодек для значення бітів стиснення прапорців заголовка.
+// DEFLATE і Zstandard вбудовані.
+func RegisterCodec(compression byte, codec Codec) error {
+	if compression == CompressionNone || compression&^FlagCompressionMask != 0 {
+		return fmt.Errorf("invalid compression flags 0x%02x", compression)
+	}
+	if codec.Name == "" || codec.Compress == nil || codec.Decompress == nil {
+		return fmt.Errorf("codec for compression 0x%02x must provide name, compressor and decompressor", compression)
+	}
+
+	codecs.mu.Lock()
+	defer codecs.mu.Unlock()
+
+	if existing, ok := codecs.byID[compression]; ok {
+		return fmt.Errorf("compression 0x%02x is already registered as %s", compression, existing.Name)
+	}
+
+	codecs.byID[compression] = codec
+	return nil
+}
+
+// lookupCodec повертає кодек для бітів стиснення прапорців
+func lookupCodec(flags byte) (Codec, error) {
+	compression := flags & FlagCompressionMask
+
+	codecs.mu.RLock()
+	codec, ok := codecs.byID[compression]
+	codecs.mu.RUnlock()
+
+	if !ok {
+		return Codec{}, fmt.Errorf("%w: 0x%02x", ErrUnsupportedCompression, compression)
+	}
+	return codec, nil
+}
+
+// deflateCompress стискає дані сирим DEFLATE з найкращим ступенем стиснення
+func deflateCompress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deflateDecompress розпаковує сирий DEFLATE, читаючи не більше limit+1 байтів
+func deflateDecompress(data []byte, limit int) ([]byte, error) {
+	reader := flate.NewReader(bytes.NewReader(data))
+	defer reader.Close()
+
+	result, err := io.ReadAll(io.LimitReader(reader, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > limit {
+		return nil, fmt.Errorf("decompressed payload exceeds %d bytes", limit)
+	}
+	return result, nil
+}
+
+// zstdEncoder спільний кодер Zstandard. Він безпечний для паралельного
+// використання через EncodeAll і створюється під час першого виклику.
+var zstdEncoder struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	err     error
+}
+
+// zstdDecoders потокові декодери Zstandard для повторного використання.
+// Потоковий декодер не можна використовувати паралельно, тож кожен виклик
+// бере власний.
+var zstdDecoders sync.Pool
+
+// zstdCompress стискає дані одним кадром Zstandard
+func zstdCompress(data []byte) ([]byte, error) {
+	zstdEncoder.once.Do(func() {
+		zstdEncoder.encoder, zstdEncoder.err = zstd.NewWriter(nil)
+	})
+	if zstdEncoder.err != nil {
+		return nil, zstdEncoder.err
+	}
+	return zstdEncoder.encoder.EncodeAll(data, nil), nil
+}
+
+// zstdDecompress розпаковує кадри Zstandard, читаючи не більше limit+1 байтів,
+// тож бомба стиснення зупиняється на межі, а не розпаковується повністю.
+// Декодер працює синхронно і не приймає вікон, більших за MaxPayloadSize.
+func zstdDecompress(data []byte, limit int) ([]byte, error) {
+	decoder, ok := zstdDecoders.Get().(*zstd.Decoder)
+	if !ok {
+		var err error
+		decoder, err = zstd.NewReader(nil,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(MaxPayloadSize),
+			zstd.WithDecoderMaxWindow(MaxPayloadSize),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer zstdDecoders.Put(decoder)
+
+	if err := decoder.Reset(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	result, err := io.ReadAll(io.LimitReader(decoder, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > limit {
+		return nil, fmt.Errorf("decompressed payload exceeds %d bytes", limit)
+	}
+	return result, nil
+}
@@ -0,0 +1,233 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// compressiblePayload навантаження, яке стискається в рази
+func compressiblePayload() []byte {
+	return bytes.Repeat(testPayload(), 512)
+}
+
+// compressedPacket формує пакет версії 2 (без контрольної суми), навантаження
+// якого вже стиснуте, і записує в прапорці заданий алгоритм
+func compressedPacket(t *testing.T, compression byte, compressed []byte) []byte {
+	t.Helper()
+
+	packet := packetFor(ProtocolVersion2, CompressionNone)
+	packet.Payload = compressed
+	data, err := EncodePacket(packet)
+	if err != nil {
+		t.Fatalf("EncodePacket: %v", err)
+	}
+	data[4] = compression
+	return data
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     byte
+		compression byte
+	}{
+		{name: "deflate v2", version: ProtocolVersion2, compression: CompressionDeflate},
+		{name: "deflate v3", version: ProtocolVersion3, compression: CompressionDeflate},
+		{name: "zstd v2", version: ProtocolVersion2, compression: CompressionZstd},
+		{name: "zstd v3", version: ProtocolVersion3, compression: CompressionZstd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet := packetFor(tt.version, tt.compression)
+			packet.Payload = compressiblePayload()
+
+			data, err := EncodePacket(packet)
+			if err != nil {
+				t.Fatalf("EncodePacket: %v", err)
+			}
+			if len(data) >= len(packet.Payload) {
+				t.Errorf("encoded packet has %d bytes, payload alone has %d", len(data), len(packet.Payload))
+			}
+
+			decoded, err := DecodePacket(data)
+			if err != nil {
+				t.Fatalf("DecodePacket: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, packet) {
+				t.Errorf("decoded packet differs\n got: %+v\nwant: %+v", decoded, packet)
+			}
+		})
+	}
+}
+
+func TestDecodePacketLimit(t *testing.T) {
+	for _, compression := range []byte{CompressionDeflate, CompressionZstd} {
+		packet := packetFor(ProtocolVersion3, compression)
+		packet.Payload = compressiblePayload()
+		size := len(packet.Payload)
+
+		data, err := EncodePacket(packet)
+		if err != nil {
+			t.Fatalf("0x%02x: EncodePacket: %v", compression, err)
+		}
+
+		if _, err := DecodePacketLimit(data, size); err != nil {
+			t.Errorf("0x%02x: limit equal to payload size: %v", compression, err)
+		}
+		if _, err := DecodePacketLimit(data, size-1); !errors.Is(err, ErrMalformedPacket) {
+			t.Errorf("0x%02x: limit below payload size: got error %v, want %v", compression, err, ErrMalformedPacket)
+		}
+	}
+}
+
+func TestDecodePacketRejectsCompressionBomb(t *testing.T) {
+	// Нулі стискаються в кілька кілобайтів, а розпаковуються понад MaxPayloadSize
+	bomb := make([]byte, MaxPayloadSize+1)
+
+	tests := []struct {
+		name        string
+		compression byte
+		compress    func([]byte) ([]byte, error)
+	}{
+		{name: "deflate", compression: CompressionDeflate, compress: deflateCompress},
+		{name: "zstd", compression: CompressionZstd, compress: zstdCompress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := tt.compress(bomb)
+			if err != nil {
+				t.Fatalf("compress: %v", err)
+			}
+
+			data := compressedPacket(t, tt.compression, compressed)
+			if _, err := DecodePacket(data); !errors.Is(err, ErrMalformedPacket) {
+				t.Errorf("got error %v, want %v", err, ErrMalformedPacket)
+			}
+		})
+	}
+}
+
+func TestDecompressStopsAtLimit(t *testing.T) {
+	bomb := make([]byte, MaxPayloadSize)
+	const limit = 64 << 10
+
+	tests := []struct {
+		name        string
+		compression byte
+		compress    func([]byte) ([]byte, error)
+	}{
+		{name: "deflate", compression: CompressionDeflate, compress: deflateCompress},
+		{name: "zstd", compression: CompressionZstd, compress: zstdCompress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := tt.compress(bomb)
+			if err != nil {
+				t.Fatalf("compress: %v", err)
+			}
+			data := compressedPacket(t, tt.compression, compressed)
+
+			// Розпакування зупиняється на межі, а не розгортає всі 16 МБ
+			allocated := allocatedBytes(func() {
+				if _, err := DecodePacketLimit(data, limit); !errors.Is(err, ErrMalformedPacket) {
+					t.Errorf("got error %v, want %v", err, ErrMalformedPacket)
+				}
+			})
+			if allocated > MaxPayloadSize/4 {
+				t.Errorf("decoding with a %d byte limit allocated %d bytes", limit, allocated)
+			}
+		})
+	}
+}
+
+func TestDecodePacketRejectsCorruptCompressedPayload(t *testing.T) {
+	for _, compression := range []byte{CompressionDeflate, CompressionZstd} {
+		data := compressedPacket(t, compression, []byte{0xDE, 0xAD, 0xBE, 0xEF, 0x00, 0x01})
+		if _, err := DecodePacket(data); !errors.Is(err, ErrMalformedPacket) {
+			t.Errorf("0x%02x: got error %v, want %v", compression, err, ErrMalformedPacket)
+		}
+	}
+}
+
+func TestUnsupportedCompression(t *testing.T) {
+	unknown := CompressionDeflate | CompressionZstd
+
+	if _, err := EncodePacket(packetFor(ProtocolVersion3, unknown)); !errors.Is(err, ErrUnsupportedCompression) {
+		t.Errorf("EncodePacket: got error %v, want %v", err, ErrUnsupportedCompression)
+	}
+
+	data := compressedPacket(t, unknown, testPayload())
+	if _, err := DecodePacket(data); !errors.Is(err, ErrUnsupportedCompression) {
+		t.Errorf("DecodePacket: got error %v, want %v", err, ErrUnsupportedCompression)
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	identity := func(data []byte) ([]byte, error) { return data, nil }
+	codec := Codec{
+		Name:       "identity",
+		Compress:   identity,
+		Decompress: func(data []byte, limit int) ([]byte, error) { return data, nil },
+	}
+
+	invalid := []struct {
+		name        string
+		compression byte
+		codec       Codec
+	}{
+		{name: "no compression", compression: CompressionNone, codec: codec},
+		{name: "acknowledgement bit", compression: FlagAckRequested | CompressionDeflate, codec: codec},
+		{name: "reserved bit", compression: 0x08, codec: codec},
+		{name: "without name", compression: CompressionDeflate | CompressionZstd, codec: Codec{Compress: identity, Decompress: codec.Decompress}},
+		{name: "without decompressor", compression: CompressionDeflate | CompressionZstd, codec: Codec{Name: "identity", Compress: identity}},
+		{name: "built-in deflate", compression: CompressionDeflate, codec: codec},
+		{name: "built-in zstd", compression: CompressionZstd, codec: codec},
+	}
+	for _, tt := range invalid {
+		if err := RegisterCodec(tt.compression, tt.codec); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	compression := CompressionDeflate | CompressionZstd
+	if err := RegisterCodec(compression, codec); err != nil {
+		t.Fatalf("RegisterCodec: %v", err)
+	}
+	t.Cleanup(func() {
+		codecs.mu.Lock()
+		delete(codecs.byID, compression)
+		codecs.mu.Unlock()
+	})
+
+	if err := RegisterCodec(compression, codec); err == nil {
+		t.Error("second registration: expected an error")
+	}
+
+	packet := packetFor(ProtocolVersion3, compression)
+	data, err := EncodePacket(packet)
+	if err != nil {
+		t.Fatalf("EncodePacket: %v", err)
+	}
+	decoded, err := DecodePacket(data)
+	if err != nil {
+		t.Fatalf("DecodePacket: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, packet) {
+		t.Errorf("decoded packet differs\n got: %+v\nwant: %+v", decoded, packet)
+	}
+}
+
+// allocatedBytes повертає, скільки байтів виділила функція
+func allocatedBytes(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
@@ -0,0 +1,280 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// Типи керуючих JSON-повідомлень. Пристрій надсилає heartbeat, scan_start,
+// scan_end, resume та command_ack; сервер відповідає відповідними *_ack,
+// підтвердженнями пакетів, командами та повідомленнями error.
+const (
+	MessageHeartbeat    = "heartbeat"
+	MessageHeartbeatAck = "heartbeat_ack"
+	MessageScanStart    = "scan_start"
+	MessageScanStartAck = "scan_start_ack"
+	MessageScanEnd      = "scan_end"
+	MessageScanEndAck   = "scan_end_ack"
+	MessageCommand      = "command"
+	MessageCommandAck   = "command_ack"
+	MessagePacketAck    = "packet_ack"
+	MessagePacketNack   = "packet_nack"
+	MessageResume       = "resume"
+	MessageResumeAck    = "resume_ack"
+	MessageError        = "error"
+)
+
+// Статуси відповіді пристрою на команду
+const (
+	AckAccepted  = "accepted"
+	AckCompleted = "completed"
+	AckRejected  = "rejected"
+)
+
+// ErrUnknownMessage повертається для керуючого повідомлення невідомого типу
+var ErrUnknownMessage = errors.New("unknown message type")
+
+// ControlMessage керуюче повідомлення, що передається текстовим кадром WebSocket
+type ControlMessage interface {
+	MessageType() string
+}
+
+// ControlError помилка розбору керуючого повідомлення відомого типу.
+// Type дозволяє відповісти пристрою, на який саме запит сталася помилка.
+type ControlError struct {
+	Type string
+	Err  error
+}
+
+func (e *ControlError) Error() string {
+	return e.Type + ": " + e.Err.Error()
+}
+
+func (e *ControlError) Unwrap() error {
+	return e.Err
+}
+
+// Heartbeat періодичне повідомлення пристрою про те, що він на зв'язку
+type Heartbeat struct {
+	// Time час пристрою, Unix-секунди; необов'язковий
+	Time int64 `json:"time,omitempty"`
+}
+
+// HeartbeatAck відповідь сервера на heartbeat
+type HeartbeatAck struct {
+	Time int64 `json:"time"`
+}
+
+// ScanStart запит пристрою на початок сканування в межах місії
+type ScanStart struct {
+	MissionID uuid.UUID   `json:"mission_id"`
+	ScanType  string      `json:"scan_type,omitempty"`
+	Metadata  interface{} `json:"metadata,omitempty"`
+}
+
+// ScanStartAck відповідь на scan_start. Пристрій використовує ScanID
+// у заголовках бінарних пакетів сканування.
+type ScanStartAck struct {
+	ScanID    uuid.UUID `json:"scan_id"`
+	MissionID uuid.UUID `json:"mission_id"`
+	Time      int64     `json:"time"`
+}
+
+// ScanEnd повідомлення пристрою про завершення сканування.
+// Порожній Status означає успішне завершення.
+type ScanEnd struct {
+	ScanID uuid.UUID `json:"scan_id"`
+	Status string    `json:"status,omitempty"`
+}
+
+// ScanEndAck відповідь на scan_end з кількістю виявлених об'єктів
+type ScanEndAck struct {
+	ScanID     uuid.UUID `json:"scan_id"`
+	Status     string    `json:"status"`
+	Detections int       `json:"detections"`
+}
+
+// Command команда сервера пристрою. Пристрій має відповісти command_ack
+// до ExpiresAt (Unix-секунди).
+type Command struct {
+	CommandID uuid.UUID              `json:"command_id"`
+	Command   string                 `json:"command"`
+	Params    map[string]interface{} `json:"params"`
+	ExpiresAt int64                  `json:"expires_at"`
+}
+
+// CommandAck відповідь пристрою на команду: AckAccepted, AckCompleted або AckRejected.
+// Error пояснює причину відхилення.
+type CommandAck struct {
+	CommandID uuid.UUID `json:"command_id"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// PacketAck підтвердження збереження пакета з прапорцем FlagAckRequested.
+// Duplicate означає, що пакет з тим самим номером і вмістом уже був збережений раніше.
+type PacketAck struct {
+	ScanID    uuid.UUID `json:"scan_id"`
+	Sequence  uint32    `json:"sequence"`
+	Duplicate bool      `json:"duplicate,omitempty"`
+}
+
+// PacketNack повідомляє, що пакет не збережено. Retry підказує, чи має сенс
+// повторна передача: пакет з некоректним вмістом повторювати марно.
+type PacketNack struct {
+	ScanID   uuid.UUID `json:"scan_id"`
+	Sequence uint32    `json:"sequence"`
+	Reason   string    `json:"reason"`
+	Retry    bool      `json:"retry"`
+}
+
+// Resume запит пристрою після перепідключення: які пакети сканування сервер уже отримав.
+// Reset повідомляє, що пристрій почав нумерацію пакетів заново (наприклад, після
+// перезавантаження): раніше використані номери більше не вважаються дублікатами.
+type Resume struct {
+	ScanID uuid.UUID `json:"scan_id"`
+	Reset  bool      `json:"reset,omitempty"`
+}
+
+// ResumeAck відповідь на resume. Пристрій має повторно надіслати пакети
+// з номерами з Missing і всі пакети після LastSequence.
+type ResumeAck struct {
+	ScanID uuid.UUID `json:"scan_id"`
+	// LastSequence найбільший отриманий номер; nil, якщо пакети не надходили
+	LastSequence *uint32 `json:"last_sequence"`
+	// Missing пропущені номери до LastSequence, які сервер ще прийме
+	Missing []uint32 `json:"missing,omitempty"`
+}
+
+// ErrorMessage повідомлення сервера про помилку обробки запиту пристрою
+type ErrorMessage struct {
+	Request string `json:"request,omitempty"`
+	Error   string `json:"error"`
+}
+
+func (Heartbeat) MessageType() string    { return MessageHeartbeat }
+func (HeartbeatAck) MessageType() string { return MessageHeartbeatAck }
+func (ScanStart) MessageType() string    { return MessageScanStart }
+func (ScanStartAck) MessageType() string { return MessageScanStartAck }
+func (ScanEnd) MessageType() string      { return MessageScanEnd }
+func (ScanEndAck) MessageType() string   { return MessageScanEndAck }
+func (Command) MessageType() string      { return MessageCommand }
+func (CommandAck) MessageType() string   { return MessageCommandAck }
+func (PacketAck) MessageType() string    { return MessagePacketAck }
+func (PacketNack) MessageType() string   { return MessagePacketNack }
+func (Resume) MessageType() string       { return MessageResume }
+func (ResumeAck) MessageType() string    { return MessageResumeAck }
+func (ErrorMessage) MessageType() string { return MessageError }
+
+// controlMessages створює порожнє повідомлення за його типом для розбору
+var controlMessages = map[string]func() ControlMessage{
+	MessageHeartbeat:    func() ControlMessage { return &Heartbeat{} },
+	MessageHeartbeatAck: func() ControlMessage { return &HeartbeatAck{} },
+	MessageScanStart:    func() ControlMessage { return &ScanStart{} },
+	MessageScanStartAck: func() ControlMessage { return &ScanStartAck{} },
+	MessageScanEnd:      func() ControlMessage { return &ScanEnd{} },
+	MessageScanEndAck:   func() ControlMessage { return &ScanEndAck{} },
+	MessageCommand:      func() ControlMessage { return &Command{} },
+	MessageCommandAck:   func() ControlMessage { return &CommandAck{} },
+	MessagePacketAck:    func() ControlMessage { return &PacketAck{} },
+	MessagePacketNack:   func() ControlMessage { return &PacketNack{} },
+	MessageResume:       func() ControlMessage { return &Resume{} },
+	MessageResumeAck:    func() ControlMessage { return &ResumeAck{} },
+	MessageError:        func() ControlMessage { return &ErrorMessage{} },
+}
+
+// EncodeControl серіалізує керуюче повідомлення з полем type.
+// Поля записуються в алфавітному порядку, тож результат детермінований.
+func EncodeControl(message ControlMessage) ([]byte, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"], err = json.Marshal(message.MessageType())
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(fields)
+}
+
+// DecodeControl розбирає керуюче повідомлення і перевіряє обов'язкові поля.
+// Повертає вказівник на структуру відповідного типу, наприклад *ScanStart.
+// Помилки повідомлень відомого типу мають тип *ControlError.
+func DecodeControl(data []byte) (ControlMessage, error) {
+	var envelope struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("invalid control message: %w", err)
+	}
+	if envelope.Type == "" {
+		return nil, errors.New("missing message type")
+	}
+
+	newMessage, ok := controlMessages[envelope.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMessage, envelope.Type)
+	}
+
+	message := newMessage()
+	if err := json.Unmarshal(data, message); err != nil {
+		return nil, &ControlError{Type: envelope.Type, Err: err}
+	}
+	if err := validateControl(message); err != nil {
+		return nil, &ControlError{Type: envelope.Type, Err: err}
+	}
+
+	return message, nil
+}
+
+// validateControl перевіряє обов'язкові поля повідомлення
+func validateControl(message ControlMessage) error {
+	switch m := message.(type) {
+	case *ScanStart:
+		return requireID(m.MissionID, "mission_id")
+	case *ScanStartAck:
+		return requireID(m.ScanID, "scan_id")
+	case *ScanEnd:
+		return requireID(m.ScanID, "scan_id")
+	case *ScanEndAck:
+		return requireID(m.ScanID, "scan_id")
+	case *PacketAck:
+		return requireID(m.ScanID, "scan_id")
+	case *Resume:
+		return requireID(m.ScanID, "scan_id")
+	case *ResumeAck:
+		return requireID(m.ScanID, "scan_id")
+	case *Command:
+		if m.Command == "" {
+			return errors.New("missing command")
+		}
+		return requireID(m.CommandID, "command_id")
+	case *CommandAck:
+		if err := requireID(m.CommandID, "command_id"); err != nil {
+			return err
+		}
+		switch m.Status {
+		case AckAccepted, AckCompleted, AckRejected:
+			return nil
+		default:
+			return fmt.Errorf("unknown acknowledgement status %q", m.Status)
+		}
+	}
+	return nil
+}
+
+// requireID перевіряє, що ідентифікатор заданий
+func requireID(id uuid.UUID, key string) error {
+	if id == uuid.Nil {
+		return errors.New("missing " + key)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package protocol
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"reflect"
+	"testing"
+)
+
+func TestControlRoundTrip(t *testing.T) {
+	scanID := testScanID()
+	missionID := uuid.MustParse("0b8e5d2a-31c4-4f6b-8e2d-7a9c1f3b5d60")
+	commandID := uuid.MustParse("c3a1e7f2-9d4b-4a85-b6e0-2f7d8c9a1b34")
+	last := uint32(41)
+
+	messages := []ControlMessage{
+		&Heartbeat{Time: 1760600000},
+		&HeartbeatAck{Time: 1760600001},
+		&ScanStart{MissionID: missionID, ScanType: "lane", Metadata: map[string]interface{}{"lane": "A"}},
+		&ScanStartAck{ScanID: scanID, MissionID: missionID, Time: 1760600002},
+		&ScanEnd{ScanID: scanID, Status: "failed"},
+		&ScanEndAck{ScanID: scanID, Status: "completed", Detections: 3},
+		&Command{CommandID: commandID, Command: "set_sample_rate", Params: map[string]interface{}{"sensor": "gpr", "rate_hz": 25.0}, ExpiresAt: 1760600060},
+		&CommandAck{CommandID: commandID, Status: AckRejected, Error: "sensor offline"},
+		&PacketAck{ScanID: scanID, Sequence: 41, Duplicate: true},
+		&PacketNack{ScanID: scanID, Sequence: 42, Reason: "checksum mismatch", Retry: true},
+		&Resume{ScanID: scanID, Reset: true},
+		&ResumeAck{ScanID: scanID, LastSequence: &last, Missing: []uint32{38, 40}},
+		&ResumeAck{ScanID: scanID},
+		&ErrorMessage{Request: MessageScanStart, Error: "mission is completed"},
+	}
+
+	for _, message := range messages {
+		t.Run(message.MessageType(), func(t *testing.T) {
+			data, err := EncodeControl(message)
+			if err != nil {
+				t.Fatalf("EncodeControl: %v", err)
+			}
+
+			var envelope struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(data, &envelope); err != nil || envelope.Type != message.MessageType() {
+				t.Fatalf("encoded message %s has type %q, want %q", data, envelope.Type, message.MessageType())
+			}
+
+			decoded, err := DecodeControl(data)
+			if err != nil {
+				t.Fatalf("DecodeControl(%s): %v", data, err)
+			}
+			if !reflect.DeepEqual(decoded, message) {
+				t.Errorf("got %+v, want %+v", decoded, message)
+			}
+		})
+	}
+}
+
+func TestEncodeControlIsDeterministic(t *testing.T) {
+	message := &PacketNack{ScanID: testScanID(), Sequence: 7, Reason: "malformed packet"}
+
+	want := `{"reason":"malformed packet","retry":false,"scan_id":"` + testScanID().String() + `","sequence":7,"type":"packet_nack"}`
+	for i := 0; i < 10; i++ {
+		data, err := EncodeControl(message)
+		if err != nil {
+			t.Fatalf("EncodeControl: %v", err)
+		}
+		if string(data) != want {
+			t.Fatalf("got %s, want %s", data, want)
+		}
+	}
+}
+
+func TestDecodeControlErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// controlType тип повідомлення в *ControlError; порожній, якщо помилка не стосується відомого типу
+		controlType string
+		want        error
+	}{
+		{name: "invalid JSON", data: `{"type":`},
+		{name: "missing type", data: `{"scan_id":"` + testScanID().String() + `"}`},
+		{name: "unknown type", data: `{"type":"reboot"}`, want: ErrUnknownMessage},
+		{name: "scan start without mission", data: `{"type":"scan_start","scan_type":"lane"}`, controlType: MessageScanStart},
+		{name: "scan end without scan", data: `{"type":"scan_end"}`, controlType: MessageScanEnd},
+		{name: "resume with invalid scan ID", data: `{"type":"resume","scan_id":"not-a-uuid"}`, controlType: MessageResume},
+		{name: "command without name", data: `{"type":"command","command_id":"` + testScanID().String() + `"}`, controlType: MessageCommand},
+		{name: "unknown acknowledgement status", data: `{"type":"command_ack","command_id":"` + testScanID().String() + `","status":"done"}`, controlType: MessageCommandAck},
+		{name: "wrong field type", data: `{"type":"heartbeat","time":"now"}`, controlType: MessageHeartbeat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeControl([]byte(tt.data))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+
+			var controlErr *ControlError
+			isControlErr := errors.As(err, &controlErr)
+			switch {
+			case tt.controlType == "" && isControlErr:
+				t.Errorf("got *ControlError for %s, want a plain error", controlErr.Type)
+			case tt.controlType != "" && !isControlErr:
+				t.Errorf("got error %v, want *ControlError", err)
+			case tt.controlType != "" && controlErr.Type != tt.controlType:
+				t.Errorf("got *ControlError for %s, want %s", controlErr.Type, tt.controlType)
+			}
+		})
+	}
+}
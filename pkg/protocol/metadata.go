@@ -0,0 +1,267 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Теги полів секції метаданих TLV
+const (
+	TagLatitude       byte = 0x01 // int32, 1e-7 градуса
+	TagLongitude      byte = 0x02 // int32, 1e-7 градуса
+	TagAltitude       byte = 0x03 // int32, мм
+	TagFixQuality     byte = 0x04 // uint8, якість розв'язку GNSS за NMEA GGA (0 - немає, 4 - RTK)
+	TagHDOP           byte = 0x05 // uint16, 0.01
+	TagHeading        byte = 0x06 // uint16, 0.01 градуса від півночі
+	TagDeviceTime     byte = 0x07 // int64, мікросекунди Unix-часу
+	TagBattery        byte = 0x08 // uint8, заряд батареї у відсотках
+	TagTemperature    byte = 0x09 // int16, 0.01 °C
+	TagSignalStrength byte = 0x0A // uint8, рівень сигналу сенсора
+)
+
+// maxFixQuality найбільший код якості розв'язку GNSS за NMEA GGA
+const maxFixQuality = 8
+
+// Metadata положення пристрою та умови виміру, передані в заголовку пакета
+type Metadata struct {
+	Latitude  float64 // градуси
+	Longitude float64 // градуси
+	Altitude  float64 // м
+	// DeviceTime час виміру за годинником пристрою; нульовий, якщо не передавався
+	DeviceTime time.Time
+	Quality    Quality
+}
+
+// Quality індикатори якості виміру. Nil означає, що пристрій поле не передавав.
+type Quality struct {
+	FixQuality     *int
+	HDOP           *float64
+	Heading        *float64 // градуси від півночі, [0, 360)
+	Battery        *int     // відсотки
+	Temperature    *float64 // °C
+	SignalStrength *int
+}
+
+// tagNames назви полів метаданих для повідомлень про помилки
+var tagNames = map[byte]string{
+	TagLatitude:       "latitude",
+	TagLongitude:      "longitude",
+	TagAltitude:       "altitude",
+	TagFixQuality:     "fixQuality",
+	TagHDOP:           "hdop",
+	TagHeading:        "heading",
+	TagDeviceTime:     "timestamp",
+	TagBattery:        "battery",
+	TagTemperature:    "temperature",
+	TagSignalStrength: "signalStrength",
+}
+
+// tagSizes розміри значень відомих тегів
+var tagSizes = map[byte]int{
+	TagLatitude:       4,
+	TagLongitude:      4,
+	TagAltitude:       4,
+	TagFixQuality:     1,
+	TagHDOP:           2,
+	TagHeading:        2,
+	TagDeviceTime:     8,
+	TagBattery:        1,
+	TagTemperature:    2,
+	TagSignalStrength: 1,
+}
+
+// decodeMetadata розбирає секцію TLV. Невідомі теги пропускаються,
+// тож пристрої з новішою прошивкою можуть додавати поля без зміни версії.
+func decodeMetadata(section []byte) (*Metadata, error) {
+	metadata := &Metadata{}
+	seen := make(map[byte]bool)
+
+	for offset := 0; offset < len(section); {
+		if len(section)-offset < 2 {
+			return nil, fmt.Errorf("%w: metadata entry at offset %d has no length", ErrTruncatedPacket, offset)
+		}
+
+		tag, length := section[offset], int(section[offset+1])
+		start := offset + 2
+		if len(section)-start < length {
+			return nil, fmt.Errorf("%w: metadata tag 0x%02x needs %d bytes, got %d", ErrTruncatedPacket, tag, length, len(section)-start)
+		}
+		value := section[start : start+length]
+		offset = start + length
+
+		name, known := tagNames[tag]
+		if !known {
+			continue
+		}
+		if seen[tag] {
+			return nil, fmt.Errorf("%w: duplicate metadata field %s", ErrMalformedPacket, name)
+		}
+		seen[tag] = true
+
+		if length != tagSizes[tag] {
+			return nil, fmt.Errorf("%w: metadata field %s has %d bytes, expected %d", ErrMalformedPacket, name, length, tagSizes[tag])
+		}
+
+		if err := metadata.decodeField(tag, value); err != nil {
+			return nil, fmt.Errorf("%w: metadata field %s: %v", ErrMalformedPacket, name, err)
+		}
+	}
+
+	for _, tag := range []byte{TagLatitude, TagLongitude, TagAltitude} {
+		if !seen[tag] {
+			return nil, fmt.Errorf("%w: missing metadata field %s", ErrMalformedPacket, tagNames[tag])
+		}
+	}
+
+	return metadata, nil
+}
+
+// decodeField записує значення відомого тегу у відповідне поле метаданих
+func (m *Metadata) decodeField(tag byte, value []byte) error {
+	switch tag {
+	case TagLatitude:
+		m.Latitude = float64(int32(binary.BigEndian.Uint32(value))) / 1e7
+		if math.Abs(m.Latitude) > 90 {
+			return fmt.Errorf("coordinate %v out of range", m.Latitude)
+		}
+	case TagLongitude:
+		m.Longitude = float64(int32(binary.BigEndian.Uint32(value))) / 1e7
+		if math.Abs(m.Longitude) > 180 {
+			return fmt.Errorf("coordinate %v out of range", m.Longitude)
+		}
+	case TagAltitude:
+		m.Altitude = float64(int32(binary.BigEndian.Uint32(value))) / 1000.0
+	case TagFixQuality:
+		if value[0] > maxFixQuality {
+			return fmt.Errorf("unknown GNSS fix quality %d", value[0])
+		}
+		fixQuality := int(value[0])
+		m.Quality.FixQuality = &fixQuality
+	case TagHDOP:
+		hdop := float64(binary.BigEndian.Uint16(value)) / 100.0
+		m.Quality.HDOP = &hdop
+	case TagHeading:
+		heading := float64(binary.BigEndian.Uint16(value)) / 100.0
+		if heading >= 360 {
+			return fmt.Errorf("heading %v out of range [0, 360)", heading)
+		}
+		m.Quality.Heading = &heading
+	case TagDeviceTime:
+		m.DeviceTime = time.UnixMicro(int64(binary.BigEndian.Uint64(value))).UTC()
+	case TagBattery:
+		if value[0] > 100 {
+			return fmt.Errorf("battery level %d%% out of range", value[0])
+		}
+		battery := int(value[0])
+		m.Quality.Battery = &battery
+	case TagTemperature:
+		temperature := float64(int16(binary.BigEndian.Uint16(value))) / 100.0
+		m.Quality.Temperature = &temperature
+	case TagSignalStrength:
+		signalStrength := int(value[0])
+		m.Quality.SignalStrength = &signalStrength
+	}
+	return nil
+}
+
+// encodeMetadata формує секцію TLV. Поля записуються в порядку тегів,
+// тож однакові метадані завжди дають однакові байти.
+func encodeMetadata(m Metadata) ([]byte, error) {
+	if err := m.validatePosition(); err != nil {
+		return nil, err
+	}
+
+	section := make([]byte, 0, 64)
+	put := func(tag byte, value []byte) {
+		section = append(section, tag, byte(len(value)))
+		section = append(section, value...)
+	}
+
+	altitude, err := scaleInt32(m.Altitude, 1000, "altitude")
+	if err != nil {
+		return nil, err
+	}
+	put(TagLatitude, binary.BigEndian.AppendUint32(nil, uint32(int32(math.Round(m.Latitude*1e7)))))
+	put(TagLongitude, binary.BigEndian.AppendUint32(nil, uint32(int32(math.Round(m.Longitude*1e7)))))
+	put(TagAltitude, binary.BigEndian.AppendUint32(nil, uint32(altitude)))
+
+	q := m.Quality
+	if q.FixQuality != nil {
+		if *q.FixQuality < 0 || *q.FixQuality > maxFixQuality {
+			return nil, fmt.Errorf("%w: unknown GNSS fix quality %d", ErrMalformedPacket, *q.FixQuality)
+		}
+		put(TagFixQuality, []byte{byte(*q.FixQuality)})
+	}
+	if q.HDOP != nil {
+		hdop, err := scaleUint16(*q.HDOP, 100, "hdop")
+		if err != nil {
+			return nil, err
+		}
+		put(TagHDOP, binary.BigEndian.AppendUint16(nil, hdop))
+	}
+	if q.Heading != nil {
+		heading, err := scaleUint16(*q.Heading, 100, "heading")
+		if err != nil {
+			return nil, err
+		}
+		if heading >= 36000 {
+			return nil, fmt.Errorf("%w: heading %v out of range [0, 360)", ErrMalformedPacket, *q.Heading)
+		}
+		put(TagHeading, binary.BigEndian.AppendUint16(nil, heading))
+	}
+	if !m.DeviceTime.IsZero() {
+		put(TagDeviceTime, binary.BigEndian.AppendUint64(nil, uint64(m.DeviceTime.UnixMicro())))
+	}
+	if q.Battery != nil {
+		if *q.Battery < 0 || *q.Battery > 100 {
+			return nil, fmt.Errorf("%w: battery level %d%% out of range", ErrMalformedPacket, *q.Battery)
+		}
+		put(TagBattery, []byte{byte(*q.Battery)})
+	}
+	if q.Temperature != nil {
+		scaled := math.Round(*q.Temperature * 100)
+		if math.IsNaN(scaled) || scaled < math.MinInt16 || scaled > math.MaxInt16 {
+			return nil, fmt.Errorf("%w: temperature %v out of range", ErrMalformedPacket, *q.Temperature)
+		}
+		put(TagTemperature, binary.BigEndian.AppendUint16(nil, uint16(int16(scaled))))
+	}
+	if q.SignalStrength != nil {
+		if *q.SignalStrength < 0 || *q.SignalStrength > math.MaxUint8 {
+			return nil, fmt.Errorf("%w: signal strength %d out of range", ErrMalformedPacket, *q.SignalStrength)
+		}
+		put(TagSignalStrength, []byte{byte(*q.SignalStrength)})
+	}
+
+	return section, nil
+}
+
+// validatePosition перевіряє, що координати можна записати в пакет
+func (m Metadata) validatePosition() error {
+	if math.IsNaN(m.Latitude) || math.Abs(m.Latitude) > 90 {
+		return fmt.Errorf("%w: latitude %v out of range", ErrMalformedPacket, m.Latitude)
+	}
+	if math.IsNaN(m.Longitude) || math.Abs(m.Longitude) > 180 {
+		return fmt.Errorf("%w: longitude %v out of range", ErrMalformedPacket, m.Longitude)
+	}
+	return nil
+}
+
+// scaleInt32 переводить значення у ціле число одиниць 1/scale з перевіркою діапазону
+func scaleInt32(value, scale float64, name string) (int32, error) {
+	scaled := math.Round(value * scale)
+	if math.IsNaN(scaled) || scaled < math.MinInt32 || scaled > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: %s %v out of range", ErrMalformedPacket, name, value)
+	}
+	return int32(scaled), nil
+}
+
+// scaleUint16 переводить невід'ємне значення у ціле число одиниць 1/scale з перевіркою діапазону
+func scaleUint16(value, scale float64, name string) (uint16, error) {
+	scaled := math.Round(value * scale)
+	if math.IsNaN(scaled) || scaled < 0 || scaled > math.MaxUint16 {
+		return 0, fmt.Errorf("%w: %s %v out of range", ErrMalformedPacket, name, value)
+	}
+	return uint16(scaled), nil
+}
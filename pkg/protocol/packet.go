@@ -0,0 +1,386 @@
+// Package protocol описує дротовий протокол обміну пристроїв із сервером:
+// бінарні пакети з даними сенсорів та керуючі JSON-повідомлення.
+// Пакет використовується сервером для розбору повідомлень, а прошивкою
+// пристроїв і симуляторами - для формування байт-у-байт сумісних пакетів.
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"hash/crc32"
+	"math"
+)
+
+// Версії формату бінарних пакетів. Пакети першої версії (і ранні пакети
+// з нулем у байті версії) мають фіксоване розташування метаданих.
+const (
+	ProtocolVersionLegacy byte = 1
+	ProtocolVersion2      byte = 2
+	ProtocolVersion3      byte = 3
+	// CurrentVersion версія, яку слід використовувати новим пристроям
+	CurrentVersion = ProtocolVersion3
+)
+
+// Коди типів бінарних пакетів із даними сенсорів
+const (
+	PacketTypeLidar    byte = 0x01
+	PacketTypeMagnetic byte = 0x02
+	PacketTypeAcoustic byte = 0x03
+	PacketTypeGPR      byte = 0x04
+	PacketTypeThermal  byte = 0x05
+	// PacketTypeBatch кадр із кількома вимірами, див. NewBatchPacket
+	PacketTypeBatch byte = 0x10
+)
+
+const (
+	packetMagic0 byte = 0xAA
+	packetMagic1 byte = 0x55
+	// packetHeaderSizeLegacy розмір заголовка пакета першої версії разом із метаданими
+	packetHeaderSizeLegacy = 40
+	// packetHeaderSizeV2 розмір заголовка пакета версії 2 до секції TLV
+	packetHeaderSizeV2 = 28
+	// packetHeaderSizeV3 розмір заголовка пакета версії 3 з порядковим номером
+	packetHeaderSizeV3 = 32
+	// packetChecksumSize розмір трейлера CRC-32 пакета версії 3
+	packetChecksumSize = 4
+	// supportedPacketFlags біти прапорців, визначені протоколом; решта зарезервовані
+	supportedPacketFlags = FlagAckRequested | FlagCompressionMask
+)
+
+// Прапорці заголовка пакета
+const (
+	// FlagAckRequested пристрій очікує packet_ack або packet_nack на цей пакет.
+	// Допускається лише у версії 3, де пакет має порядковий номер.
+	FlagAckRequested byte = 0x01
+)
+
+var (
+	// ErrTruncatedPacket повертається, якщо пакет коротший, ніж вимагає його заголовок
+	ErrTruncatedPacket = errors.New("truncated packet")
+	// ErrMalformedPacket повертається, якщо пакет містить некоректні значення
+	ErrMalformedPacket = errors.New("malformed packet")
+	// ErrChecksumMismatch повертається, якщо CRC-32 пакета не збігається з вмістом
+	ErrChecksumMismatch = errors.New("packet checksum mismatch")
+	// ErrUnverifiedPacket повертається, якщо сервер приймає лише пакети з CRC-32,
+	// а пакет версії 1 або 2 контрольної суми не містить
+	ErrUnverifiedPacket = errors.New("packet has no checksum")
+)
+
+// Packet представляє бінарний пакет із даними сенсора
+type Packet struct {
+	Version byte
+	Type    byte
+	Flags   byte
+	ScanID  uuid.UUID
+	// Sequence порядковий номер пакета пристрою; передається лише у версії 3
+	Sequence uint32
+	Metadata Metadata
+	// Payload навантаження сенсора, сформоване Encode-функцією його типу.
+	// Зберігається розпакованим: стиснення задається прапорцями і
+	// виконується під час кодування пакета.
+	Payload []byte
+}
+
+// Sequenced повідомляє, чи містить пакет порядковий номер
+func (p *Packet) Sequenced() bool {
+	return p.Version >= ProtocolVersion3
+}
+
+// Verified повідомляє, чи перевірено цілісність пакета контрольною сумою.
+// Пакети версій 1 і 2 CRC-32 не містять, тож пошкодження під час передачі
+// в них не виявляється.
+func (p *Packet) Verified() bool {
+	return p.Version >= ProtocolVersion3
+}
+
+// AckRequested повідомляє, чи очікує пристрій підтвердження пакета
+func (p *Packet) AckRequested() bool {
+	return p.Flags&FlagAckRequested != 0
+}
+
+// DecodePacket розбирає заголовок, метадані та навантаження бінарного пакета.
+// Навантаження пакета посилається на data без копіювання.
+//
+// Формат версій 2 і 3 (big-endian):
+//
+//	offset  size  field
+//	0       2     магічне число 0xAA 0x55
+//	2       1     версія протоколу (2 або 3)
+//	3       1     тип пакета
+//	4       1     прапорці: біт 0 - запит підтвердження (лише версія 3),
+//	              біти 1-2 - стиснення навантаження (CompressionDeflate, CompressionZstd)
+//	5       1     зарезервовано (0)
+//	6       2     uint16  довжина секції TLV L
+//	8       16    ID сканування
+//	24      4     uint32  довжина навантаження P
+//	28      4     uint32  порядковий номер пакета пристрою S (лише версія 3)
+//	H       L     метадані: записи tag (1 байт), length (1 байт), value
+//	H+L     P     навантаження сенсора (P - довжина після стиснення)
+//	H+L+P   4     CRC-32 (IEEE) усіх попередніх байтів пакета (лише версія 3)
+//
+// H дорівнює 28 для версії 2 і 32 для версії 3.
+// Обов'язкові теги: широта, довгота та висота.
+// Стиснуте навантаження розпаковується не більше ніж до MaxPayloadSize байтів.
+func DecodePacket(data []byte) (*Packet, error) {
+	return DecodePacketLimit(data, MaxPayloadSize)
+}
+
+// DecodePacketLimit розбирає пакет як DecodePacket, але відхиляє стиснуте
+// навантаження, яке розпаковується більш ніж у maxPayload байтів
+func DecodePacketLimit(data []byte, maxPayload int) (*Packet, error) {
+	if maxPayload <= 0 || maxPayload > MaxPayloadSize {
+		maxPayload = MaxPayloadSize
+	}
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTruncatedPacket, len(data))
+	}
+	if data[0] != packetMagic0 || data[1] != packetMagic1 {
+		return nil, fmt.Errorf("%w: invalid magic number", ErrMalformedPacket)
+	}
+
+	switch version := data[2]; version {
+	case 0, ProtocolVersionLegacy:
+		return decodeLegacyPacket(data)
+	case ProtocolVersion2, ProtocolVersion3:
+		return decodeTLVPacket(data, maxPayload)
+	default:
+		return nil, fmt.Errorf("%w: unsupported protocol version %d", ErrMalformedPacket, version)
+	}
+}
+
+// EncodePacket формує бінарний пакет у форматі, заданому p.Version.
+// Для версії 1 з метаданих зберігаються лише координати та рівень сигналу,
+// а точність координат обмежена 1e-6 градуса і висоти - 1 см.
+func EncodePacket(p *Packet) ([]byte, error) {
+	if err := checkFlags(p.Version, p.Flags); err != nil {
+		return nil, err
+	}
+
+	switch p.Version {
+	case ProtocolVersionLegacy:
+		return encodeLegacyPacket(p)
+	case ProtocolVersion2, ProtocolVersion3:
+		return encodeTLVPacket(p)
+	default:
+		return nil, fmt.Errorf("%w: unsupported protocol version %d", ErrMalformedPacket, p.Version)
+	}
+}
+
+// decodeLegacyPacket розбирає пакет першої версії з фіксованими метаданими:
+// ID сканування в байтах 8-23, широта і довгота (int32, 1e-6 градуса) в 24-31,
+// висота (int32, см) в 32-35, рівень сигналу в 36; навантаження з байта 40
+func decodeLegacyPacket(data []byte) (*Packet, error) {
+	if len(data) < packetHeaderSizeLegacy {
+		return nil, fmt.Errorf("%w: legacy header needs %d bytes, got %d", ErrTruncatedPacket, packetHeaderSizeLegacy, len(data))
+	}
+
+	scanID, err := uuid.FromBytes(data[8:24])
+	if err != nil {
+		return nil, err
+	}
+
+	signalStrength := int(data[36])
+	return &Packet{
+		Version: data[2],
+		Type:    data[3],
+		ScanID:  scanID,
+		Metadata: Metadata{
+			Latitude:  float64(int32(binary.BigEndian.Uint32(data[24:28]))) / 1e6,
+			Longitude: float64(int32(binary.BigEndian.Uint32(data[28:32]))) / 1e6,
+			Altitude:  float64(int32(binary.BigEndian.Uint32(data[32:36]))) / 100.0,
+			Quality:   Quality{SignalStrength: &signalStrength},
+		},
+		Payload: data[packetHeaderSizeLegacy:],
+	}, nil
+}
+
+// encodeLegacyPacket формує пакет першої версії
+func encodeLegacyPacket(p *Packet) ([]byte, error) {
+	meta := p.Metadata
+	if err := meta.validatePosition(); err != nil {
+		return nil, err
+	}
+
+	altitude, err := scaleInt32(meta.Altitude, 100, "altitude")
+	if err != nil {
+		return nil, err
+	}
+
+	signalStrength := 0
+	if meta.Quality.SignalStrength != nil {
+		signalStrength = *meta.Quality.SignalStrength
+	}
+	if signalStrength < 0 || signalStrength > math.MaxUint8 {
+		return nil, fmt.Errorf("%w: signal strength %d out of range", ErrMalformedPacket, signalStrength)
+	}
+
+	data := make([]byte, packetHeaderSizeLegacy+len(p.Payload))
+	data[0], data[1], data[2], data[3] = packetMagic0, packetMagic1, ProtocolVersionLegacy, p.Type
+	copy(data[8:24], p.ScanID[:])
+	binary.BigEndian.PutUint32(data[24:28], uint32(int32(math.Round(meta.Latitude*1e6))))
+	binary.BigEndian.PutUint32(data[28:32], uint32(int32(math.Round(meta.Longitude*1e6))))
+	binary.BigEndian.PutUint32(data[32:36], uint32(altitude))
+	data[36] = byte(signalStrength)
+	copy(data[packetHeaderSizeLegacy:], p.Payload)
+
+	return data, nil
+}
+
+// decodeTLVPacket розбирає пакет версії 2 або 3 із секцією метаданих TLV
+func decodeTLVPacket(data []byte, maxPayload int) (*Packet, error) {
+	version := data[2]
+	headerSize, trailerSize := tlvHeaderSize(version)
+
+	if len(data) < headerSize+trailerSize {
+		return nil, fmt.Errorf("%w: header needs %d bytes, got %d", ErrTruncatedPacket, headerSize+trailerSize, len(data))
+	}
+
+	// Контрольна сума перевіряється першою: у пошкодженому пакеті довіряти не можна жодному полю
+	if trailerSize > 0 {
+		body := data[:len(data)-trailerSize]
+		expected := binary.BigEndian.Uint32(data[len(body):])
+		if actual := crc32.ChecksumIEEE(body); actual != expected {
+			return nil, fmt.Errorf("%w: crc32 0x%08x, packet declares 0x%08x", ErrChecksumMismatch, actual, expected)
+		}
+	}
+
+	flags := data[4]
+	if err := checkFlags(version, flags); err != nil {
+		return nil, err
+	}
+
+	tlvLength := int(binary.BigEndian.Uint16(data[6:8]))
+	payloadLength := int(binary.BigEndian.Uint32(data[24:28]))
+	expected := headerSize + tlvLength + payloadLength + trailerSize
+	switch {
+	case len(data) < expected:
+		return nil, fmt.Errorf("%w: packet has %d bytes, header declares %d", ErrTruncatedPacket, len(data), expected)
+	case len(data) > expected:
+		return nil, fmt.Errorf("%w: packet has %d trailing bytes", ErrMalformedPacket, len(data)-expected)
+	}
+
+	scanID, err := uuid.FromBytes(data[8:24])
+	if err != nil {
+		return nil, err
+	}
+
+	metadataEnd := headerSize + tlvLength
+	metadata, err := decodeMetadata(data[headerSize:metadataEnd])
+	if err != nil {
+		return nil, err
+	}
+
+	payload := data[metadataEnd : metadataEnd+payloadLength]
+	if flags&FlagCompressionMask != CompressionNone {
+		codec, err := lookupCodec(flags)
+		if err != nil {
+			return nil, err
+		}
+		if payload, err = codec.Decompress(payload, maxPayload); err != nil {
+			return nil, fmt.Errorf("%w: %s payload: %v", ErrMalformedPacket, codec.Name, err)
+		}
+	}
+
+	result := &Packet{
+		Version:  version,
+		Type:     data[3],
+		Flags:    flags,
+		ScanID:   scanID,
+		Metadata: *metadata,
+		Payload:  payload,
+	}
+	if version >= ProtocolVersion3 {
+		result.Sequence = binary.BigEndian.Uint32(data[28:32])
+	}
+
+	return result, nil
+}
+
+// encodeTLVPacket формує пакет версії 2 або 3
+func encodeTLVPacket(p *Packet) ([]byte, error) {
+	section, err := encodeMetadata(p.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if len(section) > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: metadata section has %d bytes", ErrMalformedPacket, len(section))
+	}
+
+	payload := p.Payload
+	if p.Flags&FlagCompressionMask != CompressionNone {
+		codec, err := lookupCodec(p.Flags)
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) > MaxPayloadSize {
+			return nil, fmt.Errorf("%w: payload has %d bytes, compressed payload limit is %d", ErrMalformedPacket, len(payload), MaxPayloadSize)
+		}
+		if payload, err = codec.Compress(payload); err != nil {
+			return nil, fmt.Errorf("%s compression: %w", codec.Name, err)
+		}
+	}
+	if uint64(len(payload)) > math.MaxUint32 {
+		return nil, fmt.Errorf("%w: payload has %d bytes", ErrMalformedPacket, len(payload))
+	}
+
+	headerSize, trailerSize := tlvHeaderSize(p.Version)
+	data := make([]byte, headerSize+len(section)+len(payload)+trailerSize)
+	data[0], data[1], data[2], data[3], data[4] = packetMagic0, packetMagic1, p.Version, p.Type, p.Flags
+	binary.BigEndian.PutUint16(data[6:8], uint16(len(section)))
+	copy(data[8:24], p.ScanID[:])
+	binary.BigEndian.PutUint32(data[24:28], uint32(len(payload)))
+	if p.Version >= ProtocolVersion3 {
+		binary.BigEndian.PutUint32(data[28:32], p.Sequence)
+	}
+	copy(data[headerSize:], section)
+	copy(data[headerSize+len(section):], payload)
+
+	if trailerSize > 0 {
+		body := data[:len(data)-trailerSize]
+		binary.BigEndian.PutUint32(data[len(body):], crc32.ChecksumIEEE(body))
+	}
+
+	return data, nil
+}
+
+// PeekAckRequest повертає ID сканування та порядковий номер пакета версії 3
+// із запитом підтвердження, не перевіряючи решту пакета. Дозволяє відповісти
+// packet_nack на пакет, який не вдалося розібрати; значення можуть бути
+// пошкоджені, тож пристрій має ігнорувати nack для невідомих йому номерів.
+func PeekAckRequest(data []byte) (uuid.UUID, uint32, bool) {
+	if len(data) < packetHeaderSizeV3 || data[0] != packetMagic0 || data[1] != packetMagic1 ||
+		data[2] != ProtocolVersion3 || data[4]&FlagAckRequested == 0 {
+		return uuid.Nil, 0, false
+	}
+
+	scanID, err := uuid.FromBytes(data[8:24])
+	if err != nil {
+		return uuid.Nil, 0, false
+	}
+
+	return scanID, binary.BigEndian.Uint32(data[28:32]), true
+}
+
+// checkFlags перевіряє, що прапорці пакета визначені протоколом і допустимі для його версії
+func checkFlags(version, flags byte) error {
+	if flags&^supportedPacketFlags != 0 {
+		return fmt.Errorf("%w: unsupported flags 0x%02x", ErrMalformedPacket, flags)
+	}
+	if flags != 0 && version < ProtocolVersion2 {
+		return fmt.Errorf("%w: legacy packets have no flags", ErrMalformedPacket)
+	}
+	if flags&FlagAckRequested != 0 && version < ProtocolVersion3 {
+		return fmt.Errorf("%w: acknowledgement requires protocol version %d", ErrMalformedPacket, ProtocolVersion3)
+	}
+	return nil
+}
+
+// tlvHeaderSize повертає розміри заголовка та трейлера пакета з метаданими TLV
+func tlvHeaderSize(version byte) (int, int) {
+	if version >= ProtocolVersion3 {
+		return packetHeaderSizeV3, packetChecksumSize
+	}
+	return packetHeaderSizeV2, 0
+}
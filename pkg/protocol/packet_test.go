@@ -0,0 +1,340 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/google/uuid"
+	"hash/crc32"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int           { return &v }
+func floatPtr(v float64) *float64 { return &v }
+
+func testScanID() uuid.UUID {
+	return uuid.MustParse("6f1c2a9e-4b7d-4c1e-9a53-2d8f0e7b6c41")
+}
+
+func testPayload() []byte {
+	return []byte{0x00, 0x02, 0x10, 0x20, 0x30, 0x40, 0x50, 0x60}
+}
+
+// fullMetadata метадані з усіма полями, які передає версія 2
+func fullMetadata() Metadata {
+	return Metadata{
+		Latitude:   50.4501,
+		Longitude:  30.5234,
+		Altitude:   123.456,
+		DeviceTime: time.UnixMicro(1760600000123456).UTC(),
+		Quality:    fullQuality(),
+	}
+}
+
+// positionMetadata метадані лише з обов'язковими полями
+func positionMetadata() Metadata {
+	return Metadata{Latitude: -33.8688, Longitude: 151.2093, Altitude: -2.5}
+}
+
+// legacyMetadata метадані, які без втрат передає перша версія
+func legacyMetadata() Metadata {
+	return Metadata{
+		Latitude:  50.4501,
+		Longitude: 30.5234,
+		Altitude:  123.45,
+		Quality:   Quality{SignalStrength: intPtr(200)},
+	}
+}
+
+func packetFor(version, flags byte) *Packet {
+	return &Packet{
+		Version:  version,
+		Type:     PacketTypeMagnetic,
+		Flags:    flags,
+		ScanID:   testScanID(),
+		Metadata: fullMetadata(),
+		Payload:  testPayload(),
+	}
+}
+
+func fullQuality() Quality {
+	return Quality{
+		FixQuality:     intPtr(4),
+		HDOP:           floatPtr(0.85),
+		Heading:        floatPtr(271.5),
+		Battery:        intPtr(76),
+		Temperature:    floatPtr(-12.5),
+		SignalStrength: intPtr(180),
+	}
+}
+
+func TestPacketRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		packet *Packet
+	}{
+		{
+			name:   "legacy",
+			packet: &Packet{Version: ProtocolVersionLegacy, Type: PacketTypeLidar, ScanID: testScanID(), Metadata: legacyMetadata(), Payload: testPayload()},
+		},
+		{
+			name:   "v2 full metadata",
+			packet: &Packet{Version: ProtocolVersion2, Type: PacketTypeGPR, ScanID: testScanID(), Metadata: fullMetadata(), Payload: testPayload()},
+		},
+		{
+			name:   "v2 position only",
+			packet: &Packet{Version: ProtocolVersion2, Type: PacketTypeThermal, ScanID: testScanID(), Metadata: positionMetadata(), Payload: testPayload()},
+		},
+		{
+			name:   "v3 with acknowledgement",
+			packet: &Packet{Version: ProtocolVersion3, Type: PacketTypeAcoustic, Flags: FlagAckRequested, ScanID: testScanID(), Sequence: 0xFFFFFFFE, Metadata: fullMetadata(), Payload: testPayload()},
+		},
+		{
+			name:   "v3 empty payload",
+			packet: &Packet{Version: ProtocolVersion3, Type: PacketTypeMagnetic, ScanID: testScanID(), Sequence: 7, Metadata: positionMetadata(), Payload: []byte{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodePacket(tt.packet)
+			if err != nil {
+				t.Fatalf("EncodePacket: %v", err)
+			}
+
+			decoded, err := DecodePacket(data)
+			if err != nil {
+				t.Fatalf("DecodePacket: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.packet) {
+				t.Errorf("decoded packet differs\n got: %+v\nwant: %+v", decoded, tt.packet)
+			}
+
+			// Кодування детерміноване: повторне кодування дає ті самі байти
+			again, err := EncodePacket(decoded)
+			if err != nil {
+				t.Fatalf("EncodePacket of decoded packet: %v", err)
+			}
+			if !bytes.Equal(again, data) {
+				t.Errorf("re-encoded packet differs from original")
+			}
+		})
+	}
+}
+
+func TestDecodePacketDetectsCorruption(t *testing.T) {
+	data, err := EncodePacket(packetFor(ProtocolVersion3, FlagAckRequested))
+	if err != nil {
+		t.Fatalf("EncodePacket: %v", err)
+	}
+
+	// Магічне число і версія перевіряються до контрольної суми, решта байтів - лише нею
+	for i := 3; i < len(data); i++ {
+		for _, bit := range []byte{0x01, 0x80} {
+			corrupted := append([]byte(nil), data...)
+			corrupted[i] ^= bit
+
+			if _, err := DecodePacket(corrupted); !errors.Is(err, ErrChecksumMismatch) {
+				t.Fatalf("byte %d bit 0x%02x: got error %v, want %v", i, bit, err, ErrChecksumMismatch)
+			}
+		}
+	}
+}
+
+func TestDecodePacketRejectsHeaderErrors(t *testing.T) {
+	data, err := EncodePacket(packetFor(ProtocolVersion2, 0))
+	if err != nil {
+		t.Fatalf("EncodePacket: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(data []byte) []byte
+		want   error
+	}{
+		{"bad magic", func(d []byte) []byte { d[0] = 0x00; return d }, ErrMalformedPacket},
+		{"unknown version", func(d []byte) []byte { d[2] = 9; return d }, ErrMalformedPacket},
+		{"acknowledgement on v2", func(d []byte) []byte { d[4] = FlagAckRequested; return d }, ErrMalformedPacket},
+		{"reserved flag", func(d []byte) []byte { d[4] = 0x80; return d }, ErrMalformedPacket},
+		{"trailing bytes", func(d []byte) []byte { return append(d, 0x00) }, ErrMalformedPacket},
+		{"payload length too large", func(d []byte) []byte {
+			binary.BigEndian.PutUint32(d[24:28], uint32(len(d)))
+			return d
+		}, ErrTruncatedPacket},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet := tt.mutate(append([]byte(nil), data...))
+			if _, err := DecodePacket(packet); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePacketTruncated(t *testing.T) {
+	for _, version := range []byte{ProtocolVersionLegacy, ProtocolVersion2, ProtocolVersion3} {
+		packet := packetFor(version, 0)
+		if version == ProtocolVersionLegacy {
+			packet.Metadata = legacyMetadata()
+		}
+		data, err := EncodePacket(packet)
+		if err != nil {
+			t.Fatalf("v%d: EncodePacket: %v", version, err)
+		}
+
+		for n := 0; n < len(data); n++ {
+			_, err := DecodePacket(data[:n])
+			switch {
+			case version == ProtocolVersionLegacy && n >= packetHeaderSizeLegacy:
+				// Довжина навантаження першої версії не передається, тож коротший пакет коректний
+				if err != nil {
+					t.Errorf("v%d: %d of %d bytes: unexpected error %v", version, n, len(data), err)
+				}
+			case version == ProtocolVersion3 && n >= packetHeaderSizeV3+packetChecksumSize:
+				// Обрізаний пакет третьої версії не проходить перевірку CRC
+				if !errors.Is(err, ErrChecksumMismatch) {
+					t.Errorf("v%d: %d of %d bytes: got error %v, want %v", version, n, len(data), err, ErrChecksumMismatch)
+				}
+			default:
+				if !errors.Is(err, ErrTruncatedPacket) {
+					t.Errorf("v%d: %d of %d bytes: got error %v, want %v", version, n, len(data), err, ErrTruncatedPacket)
+				}
+			}
+		}
+	}
+}
+
+func TestEncodePacketRejectsInvalidFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		version byte
+		flags   byte
+	}{
+		{"flags on legacy packet", ProtocolVersionLegacy, CompressionDeflate},
+		{"acknowledgement on v2", ProtocolVersion2, FlagAckRequested},
+		{"reserved flag", ProtocolVersion3, 0x80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EncodePacket(packetFor(tt.version, tt.flags)); !errors.Is(err, ErrMalformedPacket) {
+				t.Errorf("got error %v, want %v", err, ErrMalformedPacket)
+			}
+		})
+	}
+}
+
+func TestEncodePacketRejectsInvalidMetadata(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(m *Metadata)
+	}{
+		{"latitude out of range", func(m *Metadata) { m.Latitude = 90.5 }},
+		{"longitude out of range", func(m *Metadata) { m.Longitude = -181 }},
+		{"heading of full turn", func(m *Metadata) { m.Quality.Heading = floatPtr(360) }},
+		{"battery over 100%", func(m *Metadata) { m.Quality.Battery = intPtr(101) }},
+		{"unknown fix quality", func(m *Metadata) { m.Quality.FixQuality = intPtr(9) }},
+		{"temperature out of range", func(m *Metadata) { m.Quality.Temperature = floatPtr(400) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet := packetFor(ProtocolVersion3, 0)
+			tt.mutate(&packet.Metadata)
+			if _, err := EncodePacket(packet); !errors.Is(err, ErrMalformedPacket) {
+				t.Errorf("got error %v, want %v", err, ErrMalformedPacket)
+			}
+		})
+	}
+}
+
+// tlvPacket формує пакет версії 3 з довільною секцією метаданих
+func tlvPacket(section, payload []byte) []byte {
+	data := make([]byte, packetHeaderSizeV3, packetHeaderSizeV3+len(section)+len(payload)+packetChecksumSize)
+	data[0], data[1], data[2], data[3] = packetMagic0, packetMagic1, ProtocolVersion3, PacketTypeLidar
+	binary.BigEndian.PutUint16(data[6:8], uint16(len(section)))
+	scanID := testScanID()
+	copy(data[8:24], scanID[:])
+	binary.BigEndian.PutUint32(data[24:28], uint32(len(payload)))
+	data = append(data, section...)
+	data = append(data, payload...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+}
+
+func TestDecodeMetadataSection(t *testing.T) {
+	position, err := encodeMetadata(positionMetadata())
+	if err != nil {
+		t.Fatalf("encodeMetadata: %v", err)
+	}
+	entry := func(tag byte, value ...byte) []byte {
+		return append([]byte{tag, byte(len(value))}, value...)
+	}
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	tests := []struct {
+		name    string
+		section []byte
+		want    error
+	}{
+		{"unknown tag is skipped", join(position, entry(0x7F, 0xAB, 0xCD, 0xEF)), nil},
+		{"empty unknown tag is skipped", join(entry(0x7E), position), nil},
+		{"missing altitude", position[:12], ErrMalformedPacket},
+		{"duplicate field", join(position, entry(TagBattery, 50), entry(TagBattery, 60)), ErrMalformedPacket},
+		{"wrong field size", join(position, entry(TagHDOP, 0x01)), ErrMalformedPacket},
+		{"battery over 100%", join(position, entry(TagBattery, 101)), ErrMalformedPacket},
+		{"entry without length", join(position, []byte{TagBattery}), ErrTruncatedPacket},
+		{"entry shorter than length", join(position, []byte{0x7F, 4, 0x01}), ErrTruncatedPacket},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet, err := DecodePacket(tlvPacket(tt.section, testPayload()))
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Errorf("got error %v, want %v", err, tt.want)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("DecodePacket: %v", err)
+			}
+			if !reflect.DeepEqual(packet.Metadata, positionMetadata()) {
+				t.Errorf("got metadata %+v, want %+v", packet.Metadata, positionMetadata())
+			}
+		})
+	}
+}
+
+func TestPeekAckRequest(t *testing.T) {
+	packet := packetFor(ProtocolVersion3, FlagAckRequested)
+	packet.Sequence = 1234
+	data, err := EncodePacket(packet)
+	if err != nil {
+		t.Fatalf("EncodePacket: %v", err)
+	}
+
+	// Пошкоджений пакет не розбирається, але на нього ще можна відповісти nack
+	data[len(data)-1] ^= 0xFF
+	scanID, sequence, ok := PeekAckRequest(data)
+	if !ok || scanID != packet.ScanID || sequence != packet.Sequence {
+		t.Errorf("got (%s, %d, %v), want (%s, %d, true)", scanID, sequence, ok, packet.ScanID, packet.Sequence)
+	}
+
+	withoutAck, err := EncodePacket(packetFor(ProtocolVersion3, 0))
+	if err != nil {
+		t.Fatalf("EncodePacket: %v", err)
+	}
+	if _, _, ok := PeekAckRequest(withoutAck); ok {
+		t.Errorf("packet without acknowledgement request reported as requesting one")
+	}
+	if _, _, ok := PeekAckRequest(data[:packetHeaderSizeV3-1]); ok {
+		t.Errorf("truncated header reported as requesting acknowledgement")
+	}
+}
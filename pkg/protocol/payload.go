@@ -0,0 +1,511 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrTruncatedPayload повертається, якщо навантаження коротше, ніж вимагає його заголовок
+var ErrTruncatedPayload = errors.New("truncated sensor payload")
+
+// ErrMalformedPayload повертається, якщо навантаження містить некоректні значення
+var ErrMalformedPayload = errors.New("malformed sensor payload")
+
+const (
+	lidarHeaderSize    = 2
+	lidarPointSize     = 10
+	magneticHeaderSize = 4
+	magneticSampleSize = 12
+	acousticHeaderSize = 8
+	acousticBitDepth   = 16
+	acousticMaxChannel = 8
+	gprHeaderSize      = 8
+	thermalHeaderSize  = 12
+	kelvinOffset       = 273.15
+	// ThermalScale крок температури, з яким EncodeThermal записує пікселі, K
+	ThermalScale = 0.01
+)
+
+// LidarPoint представляє одне відбиття ЛІДАР
+type LidarPoint struct {
+	Range     float64 `json:"range"`     // Відстань до поверхні, м
+	Intensity float64 `json:"intensity"` // Інтенсивність відбитого сигналу
+	Angle     float64 `json:"angle"`     // Кут відхилення від надиру, рад
+}
+
+// LidarPointCloud представляє хмару точок одного ЛІДАР-пакета
+type LidarPointCloud struct {
+	Points []LidarPoint `json:"points"`
+}
+
+// MagneticReading представляє один вимір трикомпонентного магнітометра, нТл
+type MagneticReading struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// MagneticVector представляє серію вимірів магнітометра
+type MagneticVector struct {
+	SampleRate float64           `json:"sample_rate"` // Частота дискретизації, Гц
+	Readings   []MagneticReading `json:"readings"`
+}
+
+// AcousticWaveform представляє акустичний сигнал
+type AcousticWaveform struct {
+	SampleRate int       `json:"sample_rate"` // Частота дискретизації, Гц
+	Samples    []float64 `json:"samples"`     // Нормалізовані відліки в діапазоні [-1, 1]
+}
+
+// GPRScan представляє серію A-сканів георадара
+type GPRScan struct {
+	// TimeWindow тривалість запису одного A-скану, нс
+	TimeWindow float64 `json:"time_window"`
+	// Traces A-скани з нормалізованими амплітудами в діапазоні [-1, 1]
+	Traces [][]float64 `json:"traces"`
+}
+
+// ThermalImage представляє кадр тепловізора
+type ThermalImage struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Temperatures температури пікселів построково, °C
+	Temperatures []float64 `json:"temperatures"`
+}
+
+// DecodeLidar декодує навантаження пакета ЛІДАР.
+//
+// Формат (little-endian):
+//
+//	offset  size  field
+//	0       2     uint16  кількість точок N (> 0)
+//	2       10*N  точки:
+//	              float32 range      відстань, м (>= 0)
+//	              uint16  intensity  інтенсивність відбиття
+//	              float32 angle      кут від надиру, рад ([-π, π])
+func DecodeLidar(data []byte) (*LidarPointCloud, error) {
+	if len(data) < lidarHeaderSize {
+		return nil, fmt.Errorf("%w: lidar payload has %d bytes, header needs %d", ErrTruncatedPayload, len(data), lidarHeaderSize)
+	}
+
+	count := int(binary.LittleEndian.Uint16(data[0:2]))
+	if count == 0 {
+		return nil, fmt.Errorf("%w: lidar payload contains no points", ErrMalformedPayload)
+	}
+
+	if err := checkPayloadSize("lidar", data, lidarHeaderSize+count*lidarPointSize); err != nil {
+		return nil, err
+	}
+
+	cloud := &LidarPointCloud{Points: make([]LidarPoint, count)}
+	for i := 0; i < count; i++ {
+		offset := lidarHeaderSize + i*lidarPointSize
+		rangeM := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[offset : offset+4])))
+		intensity := binary.LittleEndian.Uint16(data[offset+4 : offset+6])
+		angle := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[offset+6 : offset+10])))
+
+		if err := validateLidarPoint(i, rangeM, angle); err != nil {
+			return nil, err
+		}
+
+		cloud.Points[i] = LidarPoint{
+			Range:     rangeM,
+			Intensity: float64(intensity),
+			Angle:     angle,
+		}
+	}
+
+	return cloud, nil
+}
+
+// EncodeLidar формує навантаження пакета ЛІДАР у форматі DecodeLidar.
+// Відстань і кут записуються як float32, інтенсивність округлюється до цілого.
+func EncodeLidar(cloud *LidarPointCloud) ([]byte, error) {
+	count := len(cloud.Points)
+	if count == 0 || count > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: lidar point count %d out of range 1..%d", ErrMalformedPayload, count, math.MaxUint16)
+	}
+
+	data := make([]byte, lidarHeaderSize+count*lidarPointSize)
+	binary.LittleEndian.PutUint16(data[0:2], uint16(count))
+	for i, point := range cloud.Points {
+		if err := validateLidarPoint(i, point.Range, point.Angle); err != nil {
+			return nil, err
+		}
+		intensity := math.Round(point.Intensity)
+		if math.IsNaN(intensity) || intensity < 0 || intensity > math.MaxUint16 {
+			return nil, fmt.Errorf("%w: lidar point %d has invalid intensity %v", ErrMalformedPayload, i, point.Intensity)
+		}
+
+		offset := lidarHeaderSize + i*lidarPointSize
+		binary.LittleEndian.PutUint32(data[offset:offset+4], math.Float32bits(float32(point.Range)))
+		binary.LittleEndian.PutUint16(data[offset+4:offset+6], uint16(intensity))
+		binary.LittleEndian.PutUint32(data[offset+6:offset+10], math.Float32bits(float32(point.Angle)))
+	}
+
+	return data, nil
+}
+
+// validateLidarPoint перевіряє відстань і кут точки ЛІДАР
+func validateLidarPoint(i int, rangeM, angle float64) error {
+	if !isFinite(rangeM) || rangeM < 0 {
+		return fmt.Errorf("%w: lidar point %d has invalid range %v", ErrMalformedPayload, i, rangeM)
+	}
+	if !isFinite(angle) || math.Abs(angle) > math.Pi {
+		return fmt.Errorf("%w: lidar point %d has invalid angle %v", ErrMalformedPayload, i, angle)
+	}
+	return nil
+}
+
+// DecodeMagnetic декодує навантаження пакета трикомпонентного магнітометра.
+//
+// Формат (big-endian):
+//
+//	offset  size  field
+//	0       2     uint16  частота дискретизації, Гц (> 0)
+//	2       2     uint16  кількість вимірів N (> 0)
+//	4       12*N  виміри: float32 X, float32 Y, float32 Z, нТл
+func DecodeMagnetic(data []byte) (*MagneticVector, error) {
+	if len(data) < magneticHeaderSize {
+		return nil, fmt.Errorf("%w: magnetometer payload has %d bytes, header needs %d", ErrTruncatedPayload, len(data), magneticHeaderSize)
+	}
+
+	sampleRate := binary.BigEndian.Uint16(data[0:2])
+	if sampleRate == 0 {
+		return nil, fmt.Errorf("%w: magnetometer sample rate is zero", ErrMalformedPayload)
+	}
+
+	count := int(binary.BigEndian.Uint16(data[2:4]))
+	if count == 0 {
+		return nil, fmt.Errorf("%w: magnetometer payload contains no readings", ErrMalformedPayload)
+	}
+
+	if err := checkPayloadSize("magnetometer", data, magneticHeaderSize+count*magneticSampleSize); err != nil {
+		return nil, err
+	}
+
+	vector := &MagneticVector{
+		SampleRate: float64(sampleRate),
+		Readings:   make([]MagneticReading, count),
+	}
+	for i := 0; i < count; i++ {
+		offset := magneticHeaderSize + i*magneticSampleSize
+		reading := MagneticReading{
+			X: float64(math.Float32frombits(binary.BigEndian.Uint32(data[offset : offset+4]))),
+			Y: float64(math.Float32frombits(binary.BigEndian.Uint32(data[offset+4 : offset+8]))),
+			Z: float64(math.Float32frombits(binary.BigEndian.Uint32(data[offset+8 : offset+12]))),
+		}
+
+		if !isFinite(reading.X) || !isFinite(reading.Y) || !isFinite(reading.Z) {
+			return nil, fmt.Errorf("%w: magnetometer reading %d is not finite", ErrMalformedPayload, i)
+		}
+
+		vector.Readings[i] = reading
+	}
+
+	return vector, nil
+}
+
+// EncodeMagnetic формує навантаження пакета магнітометра у форматі DecodeMagnetic.
+// Частота дискретизації має бути цілою кількістю герц.
+func EncodeMagnetic(vector *MagneticVector) ([]byte, error) {
+	if vector.SampleRate != math.Trunc(vector.SampleRate) || vector.SampleRate < 1 || vector.SampleRate > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: magnetometer sample rate %v is not an integer in 1..%d", ErrMalformedPayload, vector.SampleRate, math.MaxUint16)
+	}
+
+	count := len(vector.Readings)
+	if count == 0 || count > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: magnetometer reading count %d out of range 1..%d", ErrMalformedPayload, count, math.MaxUint16)
+	}
+
+	data := make([]byte, magneticHeaderSize+count*magneticSampleSize)
+	binary.BigEndian.PutUint16(data[0:2], uint16(vector.SampleRate))
+	binary.BigEndian.PutUint16(data[2:4], uint16(count))
+	for i, reading := range vector.Readings {
+		if !isFinite(reading.X) || !isFinite(reading.Y) || !isFinite(reading.Z) {
+			return nil, fmt.Errorf("%w: magnetometer reading %d is not finite", ErrMalformedPayload, i)
+		}
+
+		offset := magneticHeaderSize + i*magneticSampleSize
+		binary.BigEndian.PutUint32(data[offset:offset+4], math.Float32bits(float32(reading.X)))
+		binary.BigEndian.PutUint32(data[offset+4:offset+8], math.Float32bits(float32(reading.Y)))
+		binary.BigEndian.PutUint32(data[offset+8:offset+12], math.Float32bits(float32(reading.Z)))
+	}
+
+	return data, nil
+}
+
+// DecodeAcoustic декодує PCM-кадри акустичного сенсора.
+// Багатоканальний сигнал зводиться до моно усередненням каналів.
+//
+// Формат (little-endian):
+//
+//	offset  size  field
+//	0       4     uint32  частота дискретизації, Гц (> 0)
+//	4       1     uint8   кількість каналів C (1..8)
+//	5       1     uint8   розрядність, біт (лише 16)
+//	6       2     uint16  кількість кадрів N (> 0)
+//	8       2*C*N кадри: C чергованих відліків int16 на кадр
+func DecodeAcoustic(data []byte) (*AcousticWaveform, error) {
+	if len(data) < acousticHeaderSize {
+		return nil, fmt.Errorf("%w: acoustic payload has %d bytes, header needs %d", ErrTruncatedPayload, len(data), acousticHeaderSize)
+	}
+
+	sampleRate := binary.LittleEndian.Uint32(data[0:4])
+	channels := int(data[4])
+	bitDepth := int(data[5])
+	frames := int(binary.LittleEndian.Uint16(data[6:8]))
+
+	if sampleRate == 0 {
+		return nil, fmt.Errorf("%w: acoustic sample rate is zero", ErrMalformedPayload)
+	}
+	if channels == 0 || channels > acousticMaxChannel {
+		return nil, fmt.Errorf("%w: acoustic channel count %d out of range 1..%d", ErrMalformedPayload, channels, acousticMaxChannel)
+	}
+	if bitDepth != acousticBitDepth {
+		return nil, fmt.Errorf("%w: unsupported acoustic bit depth %d", ErrMalformedPayload, bitDepth)
+	}
+	if frames == 0 {
+		return nil, fmt.Errorf("%w: acoustic payload contains no frames", ErrMalformedPayload)
+	}
+
+	if err := checkPayloadSize("acoustic", data, acousticHeaderSize+frames*channels*2); err != nil {
+		return nil, err
+	}
+
+	waveform := &AcousticWaveform{
+		SampleRate: int(sampleRate),
+		Samples:    make([]float64, frames),
+	}
+	for i := 0; i < frames; i++ {
+		sum := 0.0
+		for c := 0; c < channels; c++ {
+			offset := acousticHeaderSize + (i*channels+c)*2
+			sum += float64(int16(binary.LittleEndian.Uint16(data[offset:offset+2]))) / 32768.0
+		}
+		waveform.Samples[i] = sum / float64(channels)
+	}
+
+	return waveform, nil
+}
+
+// EncodeAcoustic формує одноканальне 16-бітне навантаження акустичного
+// сенсора у форматі DecodeAcoustic
+func EncodeAcoustic(waveform *AcousticWaveform) ([]byte, error) {
+	if waveform.SampleRate <= 0 || int64(waveform.SampleRate) > math.MaxUint32 {
+		return nil, fmt.Errorf("%w: acoustic sample rate %d out of range", ErrMalformedPayload, waveform.SampleRate)
+	}
+
+	frames := len(waveform.Samples)
+	if frames == 0 || frames > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: acoustic frame count %d out of range 1..%d", ErrMalformedPayload, frames, math.MaxUint16)
+	}
+
+	data := make([]byte, acousticHeaderSize+frames*2)
+	binary.LittleEndian.PutUint32(data[0:4], uint32(waveform.SampleRate))
+	data[4] = 1
+	data[5] = acousticBitDepth
+	binary.LittleEndian.PutUint16(data[6:8], uint16(frames))
+	for i, sample := range waveform.Samples {
+		pcm, err := encodePCM16(sample)
+		if err != nil {
+			return nil, fmt.Errorf("%w: acoustic sample %d: %v", ErrMalformedPayload, i, err)
+		}
+		offset := acousticHeaderSize + i*2
+		binary.LittleEndian.PutUint16(data[offset:offset+2], uint16(pcm))
+	}
+
+	return data, nil
+}
+
+// DecodeGPR декодує серію A-сканів георадара.
+//
+// Формат (little-endian):
+//
+//	offset  size  field
+//	0       2     uint16   кількість відліків в A-скані S (> 0)
+//	2       2     uint16   кількість A-сканів N (> 0)
+//	4       4     float32  часове вікно A-скану, нс (> 0)
+//	8       2*S*N A-скани послідовно: S відліків int16 кожен
+func DecodeGPR(data []byte) (*GPRScan, error) {
+	if len(data) < gprHeaderSize {
+		return nil, fmt.Errorf("%w: gpr payload has %d bytes, header needs %d", ErrTruncatedPayload, len(data), gprHeaderSize)
+	}
+
+	samples := int(binary.LittleEndian.Uint16(data[0:2]))
+	traces := int(binary.LittleEndian.Uint16(data[2:4]))
+	timeWindow := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[4:8])))
+
+	if samples == 0 || traces == 0 {
+		return nil, fmt.Errorf("%w: gpr payload contains no samples", ErrMalformedPayload)
+	}
+	if !isFinite(timeWindow) || timeWindow <= 0 {
+		return nil, fmt.Errorf("%w: gpr time window %v is not positive", ErrMalformedPayload, timeWindow)
+	}
+
+	if err := checkPayloadSize("gpr", data, gprHeaderSize+samples*traces*2); err != nil {
+		return nil, err
+	}
+
+	scan := &GPRScan{
+		TimeWindow: timeWindow,
+		Traces:     make([][]float64, traces),
+	}
+	for t := 0; t < traces; t++ {
+		trace := make([]float64, samples)
+		for i := range trace {
+			offset := gprHeaderSize + (t*samples+i)*2
+			trace[i] = float64(int16(binary.LittleEndian.Uint16(data[offset:offset+2]))) / 32768.0
+		}
+		scan.Traces[t] = trace
+	}
+
+	return scan, nil
+}
+
+// EncodeGPR формує навантаження георадара у форматі DecodeGPR.
+// Усі A-скани серії мають містити однакову кількість відліків.
+func EncodeGPR(scan *GPRScan) ([]byte, error) {
+	traces := len(scan.Traces)
+	if traces == 0 || traces > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: gpr trace count %d out of range 1..%d", ErrMalformedPayload, traces, math.MaxUint16)
+	}
+
+	samples := len(scan.Traces[0])
+	if samples == 0 || samples > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: gpr sample count %d out of range 1..%d", ErrMalformedPayload, samples, math.MaxUint16)
+	}
+	if !isFinite(scan.TimeWindow) || float32(scan.TimeWindow) <= 0 {
+		return nil, fmt.Errorf("%w: gpr time window %v is not positive", ErrMalformedPayload, scan.TimeWindow)
+	}
+
+	data := make([]byte, gprHeaderSize+samples*traces*2)
+	binary.LittleEndian.PutUint16(data[0:2], uint16(samples))
+	binary.LittleEndian.PutUint16(data[2:4], uint16(traces))
+	binary.LittleEndian.PutUint32(data[4:8], math.Float32bits(float32(scan.TimeWindow)))
+	for t, trace := range scan.Traces {
+		if len(trace) != samples {
+			return nil, fmt.Errorf("%w: gpr trace %d has %d samples, expected %d", ErrMalformedPayload, t, len(trace), samples)
+		}
+		for i, sample := range trace {
+			pcm, err := encodePCM16(sample)
+			if err != nil {
+				return nil, fmt.Errorf("%w: gpr trace %d sample %d: %v", ErrMalformedPayload, t, i, err)
+			}
+			offset := gprHeaderSize + (t*samples+i)*2
+			binary.LittleEndian.PutUint16(data[offset:offset+2], uint16(pcm))
+		}
+	}
+
+	return data, nil
+}
+
+// DecodeThermal декодує кадр тепловізора.
+// Температура пікселя в кельвінах дорівнює raw*scale + offset.
+//
+// Формат (little-endian):
+//
+//	offset  size  field
+//	0       2     uint16   ширина кадру W (> 0)
+//	2       2     uint16   висота кадру H (> 0)
+//	4       4     float32  масштаб, K на одиницю (> 0)
+//	8       4     float32  зміщення, K
+//	12      2*W*H пікселі построково: uint16 raw
+func DecodeThermal(data []byte) (*ThermalImage, error) {
+	if len(data) < thermalHeaderSize {
+		return nil, fmt.Errorf("%w: thermal payload has %d bytes, header needs %d", ErrTruncatedPayload, len(data), thermalHeaderSize)
+	}
+
+	width := int(binary.LittleEndian.Uint16(data[0:2]))
+	height := int(binary.LittleEndian.Uint16(data[2:4]))
+	scale := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[4:8])))
+	offsetK := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[8:12])))
+
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("%w: thermal frame is empty", ErrMalformedPayload)
+	}
+	if !isFinite(scale) || scale <= 0 || !isFinite(offsetK) {
+		return nil, fmt.Errorf("%w: invalid thermal calibration scale %v, offset %v", ErrMalformedPayload, scale, offsetK)
+	}
+
+	if err := checkPayloadSize("thermal", data, thermalHeaderSize+width*height*2); err != nil {
+		return nil, err
+	}
+
+	image := &ThermalImage{
+		Width:        width,
+		Height:       height,
+		Temperatures: make([]float64, width*height),
+	}
+	for i := range image.Temperatures {
+		offset := thermalHeaderSize + i*2
+		kelvin := float64(binary.LittleEndian.Uint16(data[offset:offset+2]))*scale + offsetK
+		if kelvin < 0 {
+			return nil, fmt.Errorf("%w: thermal pixel %d is below absolute zero", ErrMalformedPayload, i)
+		}
+		image.Temperatures[i] = kelvin - kelvinOffset
+	}
+
+	return image, nil
+}
+
+// EncodeThermal формує кадр тепловізора у форматі DecodeThermal з масштабом
+// ThermalScale і нульовим зміщенням, що покриває діапазон від 0 K до ~382 °C
+func EncodeThermal(image *ThermalImage) ([]byte, error) {
+	if image.Width <= 0 || image.Width > math.MaxUint16 || image.Height <= 0 || image.Height > math.MaxUint16 {
+		return nil, fmt.Errorf("%w: thermal frame size %dx%d out of range", ErrMalformedPayload, image.Width, image.Height)
+	}
+	if len(image.Temperatures) != image.Width*image.Height {
+		return nil, fmt.Errorf("%w: thermal frame %dx%d has %d pixels", ErrMalformedPayload, image.Width, image.Height, len(image.Temperatures))
+	}
+
+	// Масштаб передається як float32, тож і перерахунок виконується з тим самим значенням
+	scale := float64(float32(ThermalScale))
+
+	data := make([]byte, thermalHeaderSize+len(image.Temperatures)*2)
+	binary.LittleEndian.PutUint16(data[0:2], uint16(image.Width))
+	binary.LittleEndian.PutUint16(data[2:4], uint16(image.Height))
+	binary.LittleEndian.PutUint32(data[4:8], math.Float32bits(float32(ThermalScale)))
+	binary.LittleEndian.PutUint32(data[8:12], math.Float32bits(0))
+	for i, celsius := range image.Temperatures {
+		raw := math.Round((celsius + kelvinOffset) / scale)
+		if math.IsNaN(raw) || raw < 0 || raw > math.MaxUint16 {
+			return nil, fmt.Errorf("%w: thermal pixel %d temperature %v °C out of range", ErrMalformedPayload, i, celsius)
+		}
+		offset := thermalHeaderSize + i*2
+		binary.LittleEndian.PutUint16(data[offset:offset+2], uint16(raw))
+	}
+
+	return data, nil
+}
+
+// encodePCM16 переводить нормалізований відлік з [-1, 1] у int16
+func encodePCM16(sample float64) (int16, error) {
+	if !isFinite(sample) || math.Abs(sample) > 1 {
+		return 0, fmt.Errorf("value %v out of range [-1, 1]", sample)
+	}
+	scaled := math.Round(sample * 32768)
+	if scaled > math.MaxInt16 {
+		scaled = math.MaxInt16
+	}
+	return int16(scaled), nil
+}
+
+// checkPayloadSize перевіряє, що довжина навантаження точно відповідає очікуваній
+func checkPayloadSize(sensor string, data []byte, expected int) error {
+	switch {
+	case len(data) < expected:
+		return fmt.Errorf("%w: %s payload has %d bytes, need %d", ErrTruncatedPayload, sensor, len(data), expected)
+	case len(data) > expected:
+		return fmt.Errorf("%w: %s payload has %d trailing bytes", ErrMalformedPayload, sensor, len(data)-expected)
+	default:
+		return nil
+	}
+}
+
+// isFinite перевіряє, що значення не є NaN або нескінченністю
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
@@ -0,0 +1,222 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// Значення вимірів у тестах точно представляються у float32 і PCM16,
+// тож кодування з подальшим декодуванням має повертати їх без змін
+func TestPayloadRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		want interface{}
+	}{
+		{
+			name: "lidar",
+			want: &LidarPointCloud{Points: []LidarPoint{
+				{Range: 1.5, Intensity: 200, Angle: -0.25},
+				{Range: 0, Intensity: 65535, Angle: 3},
+			}},
+		},
+		{
+			name: "magnetic",
+			want: &MagneticVector{SampleRate: 100, Readings: []MagneticReading{
+				{X: 48000.5, Y: -1200.25, Z: 3.125},
+				{X: 0, Y: 0, Z: -50000},
+			}},
+		},
+		{
+			name: "acoustic",
+			want: &AcousticWaveform{SampleRate: 48000, Samples: []float64{0, 0.5, -0.25, -1}},
+		},
+		{
+			name: "gpr",
+			want: &GPRScan{TimeWindow: 20, Traces: [][]float64{{0.5, -0.5, 0}, {0.125, -1, 0.75}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := encodeSensorPayload(tt.want)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+
+			decoded, err := decodeSensorPayload(tt.want, data)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.want) {
+				t.Errorf("got %+v, want %+v", decoded, tt.want)
+			}
+		})
+	}
+}
+
+func TestThermalRoundTrip(t *testing.T) {
+	image := &ThermalImage{Width: 3, Height: 2, Temperatures: []float64{-40, 0, 21.37, 36.6, 100, 380}}
+
+	data, err := EncodeThermal(image)
+	if err != nil {
+		t.Fatalf("EncodeThermal: %v", err)
+	}
+	decoded, err := DecodeThermal(data)
+	if err != nil {
+		t.Fatalf("DecodeThermal: %v", err)
+	}
+
+	if decoded.Width != image.Width || decoded.Height != image.Height {
+		t.Fatalf("frame size %dx%d, want %dx%d", decoded.Width, decoded.Height, image.Width, image.Height)
+	}
+	for i, want := range image.Temperatures {
+		// Температура передається з кроком ThermalScale
+		if got := decoded.Temperatures[i]; math.Abs(got-want) > ThermalScale/2+1e-9 {
+			t.Errorf("pixel %d: got %v °C, want %v °C", i, got, want)
+		}
+	}
+}
+
+func TestDecodeAcousticAveragesChannels(t *testing.T) {
+	data := make([]byte, acousticHeaderSize, acousticHeaderSize+8)
+	binary.LittleEndian.PutUint32(data[0:4], 8000)
+	data[4] = 2
+	data[5] = acousticBitDepth
+	binary.LittleEndian.PutUint16(data[6:8], 2)
+	for _, sample := range []int16{16384, -16384, 16384, 8192} {
+		data = binary.LittleEndian.AppendUint16(data, uint16(sample))
+	}
+
+	waveform, err := DecodeAcoustic(data)
+	if err != nil {
+		t.Fatalf("DecodeAcoustic: %v", err)
+	}
+	if want := []float64{0, 0.375}; !reflect.DeepEqual(waveform.Samples, want) {
+		t.Errorf("samples = %v, want %v", waveform.Samples, want)
+	}
+}
+
+func TestDecodePayloadTruncated(t *testing.T) {
+	payloads := []interface{}{
+		&LidarPointCloud{Points: []LidarPoint{{Range: 1, Intensity: 1, Angle: 0}}},
+		&MagneticVector{SampleRate: 10, Readings: []MagneticReading{{X: 1, Y: 2, Z: 3}}},
+		&AcousticWaveform{SampleRate: 8000, Samples: []float64{0.5, 0.25}},
+		&GPRScan{TimeWindow: 10, Traces: [][]float64{{0.5, 0.25}}},
+		&ThermalImage{Width: 1, Height: 2, Temperatures: []float64{20, 21}},
+	}
+
+	for _, payload := range payloads {
+		data, err := encodeSensorPayload(payload)
+		if err != nil {
+			t.Fatalf("%T: encode: %v", payload, err)
+		}
+
+		for n := 0; n < len(data); n++ {
+			if _, err := decodeSensorPayload(payload, data[:n]); !errors.Is(err, ErrTruncatedPayload) {
+				t.Errorf("%T: %d of %d bytes: got error %v, want %v", payload, n, len(data), err, ErrTruncatedPayload)
+			}
+		}
+
+		if _, err := decodeSensorPayload(payload, append(data, 0)); !errors.Is(err, ErrMalformedPayload) {
+			t.Errorf("%T: trailing byte: got error %v, want %v", payload, err, ErrMalformedPayload)
+		}
+	}
+}
+
+func TestDecodePayloadRejectsInvalidValues(t *testing.T) {
+	lidar := func(rangeM, angle float32) []byte {
+		data := binary.LittleEndian.AppendUint16(nil, 1)
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(rangeM))
+		data = binary.LittleEndian.AppendUint16(data, 10)
+		return binary.LittleEndian.AppendUint32(data, math.Float32bits(angle))
+	}
+	acoustic := func(channels, bitDepth byte) []byte {
+		data := binary.LittleEndian.AppendUint32(nil, 8000)
+		data = append(data, channels, bitDepth)
+		data = binary.LittleEndian.AppendUint16(data, 1)
+		return append(data, make([]byte, 2*int(channels))...)
+	}
+
+	tests := []struct {
+		name   string
+		decode func() error
+	}{
+		{"lidar without points", func() error { _, err := DecodeLidar([]byte{0, 0}); return err }},
+		{"lidar negative range", func() error { _, err := DecodeLidar(lidar(-1, 0)); return err }},
+		{"lidar angle beyond pi", func() error { _, err := DecodeLidar(lidar(1, 4)); return err }},
+		{"lidar NaN range", func() error { _, err := DecodeLidar(lidar(float32(math.NaN()), 0)); return err }},
+		{"magnetic zero sample rate", func() error { _, err := DecodeMagnetic([]byte{0, 0, 0, 1}); return err }},
+		{"acoustic without channels", func() error { _, err := DecodeAcoustic(acoustic(0, 16)); return err }},
+		{"acoustic 8-bit samples", func() error { _, err := DecodeAcoustic(acoustic(1, 8)); return err }},
+		{"acoustic too many channels", func() error { _, err := DecodeAcoustic(acoustic(9, 16)); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.decode(); !errors.Is(err, ErrMalformedPayload) {
+				t.Errorf("got error %v, want %v", err, ErrMalformedPayload)
+			}
+		})
+	}
+}
+
+func TestEncodePayloadRejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload interface{}
+	}{
+		{"lidar without points", &LidarPointCloud{}},
+		{"lidar negative intensity", &LidarPointCloud{Points: []LidarPoint{{Range: 1, Intensity: -1}}}},
+		{"magnetic fractional sample rate", &MagneticVector{SampleRate: 10.5, Readings: []MagneticReading{{}}}},
+		{"acoustic sample out of range", &AcousticWaveform{SampleRate: 8000, Samples: []float64{1.5}}},
+		{"gpr ragged traces", &GPRScan{TimeWindow: 10, Traces: [][]float64{{0, 0}, {0}}}},
+		{"gpr zero time window", &GPRScan{Traces: [][]float64{{0}}}},
+		{"thermal pixel count", &ThermalImage{Width: 2, Height: 2, Temperatures: []float64{20}}},
+		{"thermal below absolute zero", &ThermalImage{Width: 1, Height: 1, Temperatures: []float64{-300}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := encodeSensorPayload(tt.payload); !errors.Is(err, ErrMalformedPayload) {
+				t.Errorf("got error %v, want %v", err, ErrMalformedPayload)
+			}
+		})
+	}
+}
+
+// encodeSensorPayload кодує навантаження функцією, що відповідає його типу
+func encodeSensorPayload(payload interface{}) ([]byte, error) {
+	switch p := payload.(type) {
+	case *LidarPointCloud:
+		return EncodeLidar(p)
+	case *MagneticVector:
+		return EncodeMagnetic(p)
+	case *AcousticWaveform:
+		return EncodeAcoustic(p)
+	case *GPRScan:
+		return EncodeGPR(p)
+	case *ThermalImage:
+		return EncodeThermal(p)
+	}
+	panic("unknown payload type")
+}
+
+// decodeSensorPayload декодує дані функцією, що відповідає типу зразка
+func decodeSensorPayload(sample interface{}, data []byte) (interface{}, error) {
+	switch sample.(type) {
+	case *LidarPointCloud:
+		return DecodeLidar(data)
+	case *MagneticVector:
+		return DecodeMagnetic(data)
+	case *AcousticWaveform:
+		return DecodeAcoustic(data)
+	case *GPRScan:
+		return DecodeGPR(data)
+	case *ThermalImage:
+		return DecodeThermal(data)
+	}
+	panic("unknown payload type")
+}